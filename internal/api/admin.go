@@ -0,0 +1,219 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"web-search-api-for-llms/internal/browser"
+	"web-search-api-for-llms/internal/cache"
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/worker"
+)
+
+// AdminHandler exposes runtime pool-management endpoints (pause/resume/resize the
+// worker pools and the browser pool, cache namespace invalidation, and a combined status
+// readout) so an operator can throttle the service or evict stale content during an
+// incident without a redeploy. Every endpoint requires Config.AdminAPIKey; if that's
+// unset, the admin API refuses all requests.
+type AdminHandler struct {
+	Config            *config.AppConfig
+	HTTPWorkerPool    *worker.WorkerPool
+	BrowserWorkerPool *worker.WorkerPool
+	BrowserPool       *browser.Pool
+	Caches            *cache.CacheManager
+}
+
+// NewAdminHandler creates a new AdminHandler with its dependencies.
+func NewAdminHandler(appConfig *config.AppConfig, httpWorkerPool, browserWorkerPool *worker.WorkerPool, browserPool *browser.Pool, caches *cache.CacheManager) *AdminHandler {
+	return &AdminHandler{
+		Config:            appConfig,
+		HTTPWorkerPool:    httpWorkerPool,
+		BrowserWorkerPool: browserWorkerPool,
+		BrowserPool:       browserPool,
+		Caches:            caches,
+	}
+}
+
+// authorize reports whether r carries the configured admin key in its X-Admin-Key
+// header, writing the appropriate error response and returning false if not.
+func (ah *AdminHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if ah.Config.AdminAPIKey == "" {
+		ah.respondWithError(w, http.StatusNotFound, "admin API is disabled")
+		return false
+	}
+	if r.Header.Get("X-Admin-Key") != ah.Config.AdminAPIKey {
+		ah.respondWithError(w, http.StatusUnauthorized, "invalid or missing X-Admin-Key header")
+		return false
+	}
+	return true
+}
+
+func (ah *AdminHandler) respondWithError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		slog.Warn("Failed to write admin error response", "error", err)
+	}
+}
+
+// poolByName resolves "http" or "browser" to the matching worker pool, the only two
+// pools HandlePools can pause/resume/resize.
+func (ah *AdminHandler) poolByName(name string) (*worker.WorkerPool, bool) {
+	switch name {
+	case "http":
+		return ah.HTTPWorkerPool, true
+	case "browser":
+		return ah.BrowserWorkerPool, true
+	default:
+		return nil, false
+	}
+}
+
+// HandlePools routes every /admin/pools/... request: GET /admin/pools/status, and
+// POST /admin/pools/{http|browser}/{pause|resume|resize}.
+func (ah *AdminHandler) HandlePools(w http.ResponseWriter, r *http.Request) {
+	if !ah.authorize(w, r) {
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/admin/pools/")
+	if path == "status" {
+		ah.handleStatus(w, r)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 {
+		ah.respondWithError(w, http.StatusNotFound, "unknown admin pools route")
+		return
+	}
+	poolName, action := parts[0], parts[1]
+
+	if poolName == "browser" && action != "resize" {
+		ah.respondWithError(w, http.StatusNotFound, "the browser pool only supports resize; pause/resume apply to the http/browser worker pools")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		ah.respondWithError(w, http.StatusMethodNotAllowed, "this endpoint requires POST")
+		return
+	}
+
+	if poolName == "browser" {
+		ah.resizeBrowserPool(w, r)
+		return
+	}
+
+	pool, ok := ah.poolByName(poolName)
+	if !ok {
+		ah.respondWithError(w, http.StatusNotFound, fmt.Sprintf("unknown pool %q (must be \"http\" or \"browser\")", poolName))
+		return
+	}
+
+	switch action {
+	case "pause":
+		pool.Pause()
+		ah.respondWithStatus(w, http.StatusOK, map[string]string{"status": "paused"})
+	case "resume":
+		pool.Resume()
+		ah.respondWithStatus(w, http.StatusOK, map[string]string{"status": "resumed"})
+	case "resize":
+		n, ok := ah.parseResizeParam(w, r)
+		if !ok {
+			return
+		}
+		pool.Resize(n)
+		ah.respondWithStatus(w, http.StatusOK, pool.Status())
+	default:
+		ah.respondWithError(w, http.StatusNotFound, fmt.Sprintf("unknown action %q (must be \"pause\", \"resume\", or \"resize\")", action))
+	}
+}
+
+// HandleCache routes DELETE /cache/{group} requests, invalidating every entry in the
+// named cache namespace (e.g. "youtube", "pdf"; see config.CacheGroups). This lets an
+// operator evict a group operators know is stale (e.g. after a source changes its
+// format) without a restart or touching the others' TTLs. A DELETE /cache/{group}?key=
+// request instead purges just that one entry (e.g. a single URL that's since changed),
+// leaving the rest of the namespace untouched.
+func (ah *AdminHandler) HandleCache(w http.ResponseWriter, r *http.Request) {
+	if !ah.authorize(w, r) {
+		return
+	}
+	if r.Method != http.MethodDelete {
+		ah.respondWithError(w, http.StatusMethodNotAllowed, "this endpoint requires DELETE")
+		return
+	}
+
+	group := strings.TrimPrefix(r.URL.Path, "/cache/")
+	if group == "" || strings.Contains(group, "/") {
+		ah.respondWithError(w, http.StatusNotFound, "DELETE /cache/{group} requires a single path segment")
+		return
+	}
+
+	if key := r.URL.Query().Get("key"); key != "" {
+		if err := ah.Caches.InvalidateKey(r.Context(), group, key); err != nil {
+			ah.respondWithError(w, http.StatusNotFound, fmt.Sprintf("failed to invalidate key %q in cache group %q: %v", key, group, err))
+			return
+		}
+		ah.respondWithStatus(w, http.StatusOK, map[string]string{"status": "invalidated", "group": group, "key": key})
+		return
+	}
+
+	if err := ah.Caches.InvalidateNamespace(r.Context(), group); err != nil {
+		ah.respondWithError(w, http.StatusNotFound, fmt.Sprintf("failed to invalidate cache group %q: %v", group, err))
+		return
+	}
+	ah.respondWithStatus(w, http.StatusOK, map[string]string{"status": "invalidated", "group": group})
+}
+
+func (ah *AdminHandler) resizeBrowserPool(w http.ResponseWriter, r *http.Request) {
+	n, ok := ah.parseResizeParam(w, r)
+	if !ok {
+		return
+	}
+	if err := ah.BrowserPool.Resize(n); err != nil {
+		ah.respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to resize browser pool: %v", err))
+		return
+	}
+	ah.respondWithStatus(w, http.StatusOK, ah.BrowserPool.Status())
+}
+
+func (ah *AdminHandler) parseResizeParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := r.URL.Query().Get("n")
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		ah.respondWithError(w, http.StatusBadRequest, "resize requires a positive integer ?n= query parameter")
+		return 0, false
+	}
+	return n, true
+}
+
+// poolsStatus is the GET /admin/pools/status response body.
+type poolsStatus struct {
+	HTTPWorkerPool    worker.Status  `json:"http_worker_pool"`
+	BrowserWorkerPool worker.Status  `json:"browser_worker_pool"`
+	BrowserPool       browser.Status `json:"browser_pool"`
+}
+
+func (ah *AdminHandler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		ah.respondWithError(w, http.StatusMethodNotAllowed, "this endpoint requires GET")
+		return
+	}
+	ah.respondWithStatus(w, http.StatusOK, poolsStatus{
+		HTTPWorkerPool:    ah.HTTPWorkerPool.Status(),
+		BrowserWorkerPool: ah.BrowserWorkerPool.Status(),
+		BrowserPool:       ah.BrowserPool.Status(),
+	})
+}
+
+func (ah *AdminHandler) respondWithStatus(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Warn("Failed to write admin response", "error", err)
+	}
+}
@@ -8,14 +8,18 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"web-search-api-for-llms/internal/browser"
 	"web-search-api-for-llms/internal/cache"
 	"web-search-api-for-llms/internal/config"
 	"web-search-api-for-llms/internal/extractor"
+	"web-search-api-for-llms/internal/readability"
 	"web-search-api-for-llms/internal/searxng"
+	"web-search-api-for-llms/internal/warc"
 	"web-search-api-for-llms/internal/worker"
 
 	jsoniter "github.com/json-iterator/go"
@@ -30,6 +34,12 @@ func GetJsoniter() jsoniter.API {
 
 // ... (Payload structs remain the same) ...
 
+// maxURLs bounds how many URLs a single /extract (or /extract/stream, /extract/sse)
+// request may submit. The worker pools' disk overflow queue now absorbs a batch this
+// large without blocking or OOMing the request goroutine, so this is a sanity ceiling
+// rather than a memory budget.
+const maxURLs = 2000
+
 var (
 	requestPayloadPool        = sync.Pool{New: func() interface{} { return new(RequestPayload) }}
 	extractRequestPayloadPool = sync.Pool{New: func() interface{} { return new(ExtractRequestPayload) }}
@@ -39,6 +49,10 @@ type RequestPayload struct {
 	Query         string `json:"query"`
 	MaxResults    int    `json:"max_results"`
 	MaxCharPerURL *int   `json:"max_char_per_url,omitempty"`
+	// Mode selects how JSWebpageExtractor's result is built for each URL: "raw" (the
+	// default, plain innerText), "readability" (a denoised article via
+	// internal/readability), or "both". See readability.ParseMode.
+	Mode string `json:"mode,omitempty"`
 }
 type FinalResponsePayload struct {
 	QueryDetails struct {
@@ -52,21 +66,50 @@ type FinalResponsePayload struct {
 type ExtractRequestPayload struct {
 	URLs          []string `json:"urls"`
 	MaxCharPerURL *int     `json:"max_char_per_url,omitempty"`
+	// Archive, when true, has processRequest capture every fetched URL's raw HTTP
+	// request/response (or, for JS-rendered pages, the extracted resource) as WARC 1.1
+	// records alongside the JSON response (see internal/warc).
+	Archive bool `json:"archive,omitempty"`
+	// ArchivePath names the WARC output directory for this batch. A relative path is
+	// resolved under AppConfig.WARCOutputDir; empty uses WARCOutputDir directly. Ignored
+	// unless Archive is true.
+	ArchivePath string `json:"archive_path,omitempty"`
+	// Mode selects how JSWebpageExtractor's result is built for each URL: "raw" (the
+	// default, plain innerText), "readability" (a denoised article via
+	// internal/readability), or "both". See readability.ParseMode.
+	Mode string `json:"mode,omitempty"`
+	// TranscriptStart and TranscriptEnd restrict YouTubeExtractor's transcript to a time
+	// window in seconds; TranscriptEnd of 0 (or omitted) means no upper bound. Ignored
+	// for non-YouTube URLs.
+	TranscriptStart float64 `json:"transcript_start,omitempty"`
+	TranscriptEnd   float64 `json:"transcript_end,omitempty"`
+	// Language selects a caption track's BCP-47 code for YouTubeExtractor's transcript,
+	// instead of the "en" default.
+	Language string `json:"language,omitempty"`
+	// Prefer selects which path YouTubeExtractor takes for a URL carrying both a video ID
+	// and a playlist ID: "playlist" (the default) extracts the whole playlist, "video"
+	// extracts just that video instead. Ignored for URLs with only one or the other.
+	Prefer string `json:"prefer,omitempty"`
+	// MaxVideos caps how many items YouTubeExtractor.extractPlaylist accumulates for this
+	// request, overriding AppConfig.MaxPlaylistItems when smaller and non-zero. Ignored
+	// for non-playlist URLs.
+	MaxVideos int `json:"max_videos,omitempty"`
 }
 type ExtractResponsePayload struct {
 	RequestDetails struct {
 		URLsRequested int `json:"urls_requested"`
 		URLsProcessed int `json:"urls_processed"`
 	} `json:"request_details"`
-	Results []*extractor.ExtractedResult `json:"results"`
-	Error   string                       `json:"error,omitempty"`
+	Results     []*extractor.ExtractedResult `json:"results"`
+	Error       string                       `json:"error,omitempty"`
+	ArchivePath string                       `json:"archive_path,omitempty"`
 }
 
 // SearchHandler holds dependencies for the search handler.
 type SearchHandler struct {
 	Config            *config.AppConfig
 	SearxNGClient     *searxng.Client
-	Cache             cache.Cache
+	Caches            *cache.CacheManager
 	HTTPWorkerPool    *worker.WorkerPool // For lightweight jobs
 	BrowserWorkerPool *worker.WorkerPool // For heavyweight, CPU-bound jobs
 }
@@ -76,14 +119,14 @@ func NewSearchHandler(
 	appConfig *config.AppConfig,
 	browserPool *browser.Pool,
 	client *http.Client,
-	appCache cache.Cache,
+	caches *cache.CacheManager,
 	httpWorkerPool *worker.WorkerPool,
 	browserWorkerPool *worker.WorkerPool,
 ) *SearchHandler {
 	return &SearchHandler{
 		Config:            appConfig,
 		SearxNGClient:     searxng.NewClient(appConfig, client),
-		Cache:             appCache,
+		Caches:            caches,
 		HTTPWorkerPool:    httpWorkerPool,
 		BrowserWorkerPool: browserWorkerPool,
 	}
@@ -97,8 +140,199 @@ func (sh *SearchHandler) HandleExtract(w http.ResponseWriter, r *http.Request) {
 	sh.processRequest(w, r, "/extract")
 }
 
-// isBrowserJob determines if a URL requires the heavyweight browser worker pool.
-func isBrowserJob(urlString, endpoint string) bool {
+// HandleExtractStream behaves like HandleExtract but delivers results incrementally as
+// they complete, one JSON-encoded ExtractedResult per line (NDJSON), flushed immediately.
+func (sh *SearchHandler) HandleExtractStream(w http.ResponseWriter, r *http.Request) {
+	urls, maxChars, mode, transcriptOpts, playlistOpts, ok := sh.decodeExtractRequest(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	out := make(chan *extractor.ExtractedResult, len(urls))
+	go sh.HTTPWorkerPool.Dispatcher.DispatchAndStream(extractor.NewPlaylistContext(extractor.NewTranscriptContext(readability.NewContext(r.Context(), mode), transcriptOpts), playlistOpts), urls, "/extract", maxChars, out)
+
+	for result := range out {
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			slog.Error("Error encoding streamed extract result", "error", err, "url", result.URL)
+		}
+		flusher.Flush()
+		result.Reset()
+		extractor.ExtractedResultPool.Put(result)
+	}
+}
+
+// HandleExtractSSE behaves like HandleExtract but delivers results incrementally as they
+// complete over Server-Sent Events: one "event: result" frame per ExtractedResult,
+// flushed immediately, followed by a final "event: done" frame.
+func (sh *SearchHandler) HandleExtractSSE(w http.ResponseWriter, r *http.Request) {
+	urls, maxChars, mode, transcriptOpts, playlistOpts, ok := sh.decodeExtractRequest(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	out := make(chan *extractor.ExtractedResult, len(urls))
+	go sh.HTTPWorkerPool.Dispatcher.DispatchAndStream(extractor.NewPlaylistContext(extractor.NewTranscriptContext(readability.NewContext(r.Context(), mode), transcriptOpts), playlistOpts), urls, "/extract", maxChars, out)
+
+	for result := range out {
+		fmt.Fprint(w, "event: result\ndata: ")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			slog.Error("Error encoding SSE extract result", "error", err, "url", result.URL)
+		}
+		fmt.Fprint(w, "\n")
+		flusher.Flush()
+		result.Reset()
+		extractor.ExtractedResultPool.Put(result)
+	}
+
+	fmt.Fprint(w, "event: done\ndata: {}\n\n")
+	flusher.Flush()
+}
+
+// twitterStreamPollInterval is how often HandleTwitterStream re-polls SearchTimeline for
+// new matches while a client keeps the connection open.
+const twitterStreamPollInterval = 15 * time.Second
+
+// twitterStreamMaxDuration bounds how long a single /twitter/stream connection is kept
+// open, so a client that never disconnects doesn't pin a goroutine (and an account's guest
+// token) forever.
+const twitterStreamMaxDuration = 10 * time.Minute
+
+// HandleTwitterStream long-polls Twitter/X search results for a query (see
+// TwitterExtractor.Search), pushing newly-seen tweets as NDJSON as they appear. It's a
+// cheap substitute for Twitter's deprecated Streaming API: each poll only returns tweets
+// posted after the previous poll's newest match, tracked via SearchTimeline's since_id
+// semantics.
+func (sh *SearchHandler) HandleTwitterStream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "missing required query parameter: q", http.StatusBadRequest)
+		return
+	}
+	product := r.URL.Query().Get("product")
+	sinceID := r.URL.Query().Get("since_id")
+
+	twitterExtractor, ok := sh.HTTPWorkerPool.Dispatcher.TwitterExtractor()
+	if !ok {
+		http.Error(w, "twitter search is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	ctx, cancel := context.WithTimeout(r.Context(), twitterStreamMaxDuration)
+	defer cancel()
+
+	ticker := time.NewTicker(twitterStreamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		searchResult, err := twitterExtractor.Search(ctx, query, product, sinceID, sh.Config.TwitterSearchMaxResults)
+		if err != nil {
+			slog.Error("Error polling twitter search stream", "query", query, "error", err)
+		} else {
+			for i := len(searchResult.Tweets) - 1; i >= 0; i-- {
+				if err := json.NewEncoder(w).Encode(searchResult.Tweets[i]); err != nil {
+					slog.Error("Error encoding streamed twitter search result", "error", err, "query", query)
+				}
+			}
+			flusher.Flush()
+			if len(searchResult.Tweets) > 0 {
+				sinceID = extractor.ExtractTweetID(searchResult.Tweets[0].URL)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// decodeExtractRequest parses and validates the shared ExtractRequestPayload body used by
+// HandleExtract, HandleExtractStream and HandleExtractSSE. It writes an error response
+// and returns ok=false if the payload is invalid.
+func (sh *SearchHandler) decodeExtractRequest(w http.ResponseWriter, r *http.Request) (urls []string, maxChars *int, mode readability.Mode, transcriptOpts extractor.TranscriptOptions, playlistOpts extractor.PlaylistOptions, ok bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return nil, nil, "", extractor.TranscriptOptions{}, extractor.PlaylistOptions{}, false
+	}
+
+	reqPayload := extractRequestPayloadPool.Get().(*ExtractRequestPayload)
+	defer func() {
+		*reqPayload = ExtractRequestPayload{}
+		extractRequestPayloadPool.Put(reqPayload)
+	}()
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Warn("Failed to close request body", "error", err)
+		}
+	}()
+
+	if err := json.NewDecoder(r.Body).Decode(reqPayload); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request payload: %v", err), http.StatusBadRequest)
+		return nil, nil, "", extractor.TranscriptOptions{}, extractor.PlaylistOptions{}, false
+	}
+	if len(reqPayload.URLs) == 0 {
+		http.Error(w, "URLs parameter is required", http.StatusBadRequest)
+		return nil, nil, "", extractor.TranscriptOptions{}, extractor.PlaylistOptions{}, false
+	}
+	// The worker pools' disk overflow queue (see worker.WorkerPool.Enqueue) bounds
+	// memory for an oversized batch now, so this only needs to guard against truly
+	// pathological payloads rather than cap at what fits in memory.
+	if len(reqPayload.URLs) > maxURLs {
+		http.Error(w, fmt.Sprintf("Too many URLs provided. Maximum allowed: %d", maxURLs), http.StatusBadRequest)
+		return nil, nil, "", extractor.TranscriptOptions{}, extractor.PlaylistOptions{}, false
+	}
+	mode, err := readability.ParseMode(reqPayload.Mode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, "", extractor.TranscriptOptions{}, extractor.PlaylistOptions{}, false
+	}
+
+	urls = make([]string, len(reqPayload.URLs))
+	copy(urls, reqPayload.URLs)
+	transcriptOpts = extractor.TranscriptOptions{
+		Start:    reqPayload.TranscriptStart,
+		End:      reqPayload.TranscriptEnd,
+		Language: reqPayload.Language,
+	}
+	playlistOpts = extractor.PlaylistOptions{
+		Prefer:    reqPayload.Prefer,
+		MaxVideos: reqPayload.MaxVideos,
+	}
+	return urls, reqPayload.MaxCharPerURL, mode, transcriptOpts, playlistOpts, true
+}
+
+// IsBrowserJob determines if a URL requires the heavyweight browser worker pool.
+func IsBrowserJob(urlString, endpoint string) bool {
 	// All /extract jobs use the browser for maximum compatibility.
 	if endpoint == "/extract" {
 		return true
@@ -107,12 +341,25 @@ func isBrowserJob(urlString, endpoint string) bool {
 	// You can add more domains here if they prove to be JS-heavy.
 	parsedURL, err := url.Parse(urlString)
 	if err != nil {
-		slog.Warn("Could not parse URL in isBrowserJob", "url", urlString, "error", err)
+		slog.Warn("Could not parse URL in IsBrowserJob", "url", urlString, "error", err)
 		return false // Default to non-browser job on parse failure
 	}
 	return strings.Contains(parsedURL.Host, "twitter.com") || strings.Contains(parsedURL.Host, "x.com")
 }
 
+// failedResult builds a pooled ExtractedResult recording err against targetURL, for
+// when a job never made it onto a worker pool at all (e.g. its disk overflow queue
+// rejected it) and so never got the usual Dispatcher-produced result.
+func failedResult(targetURL string, err error) *extractor.ExtractedResult {
+	result := extractor.ExtractedResultPool.Get().(*extractor.ExtractedResult)
+	result.Reset()
+	result.URL = targetURL
+	result.ProcessedSuccessfully = false
+	result.Error = err.Error()
+	result.ErrorClass = extractor.ClassifyError(err)
+	return result
+}
+
 func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request, endpoint string) {
 	// Extract request ID from context
 	requestID, _ := r.Context().Value("requestID").(string)
@@ -128,6 +375,11 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 	var maxChars *int
 	var query string
 	var maxResults int
+	var archiveWriter *warc.Writer
+	var archivePath string
+	var mode readability.Mode
+	var transcriptOpts extractor.TranscriptOptions
+	var playlistOpts extractor.PlaylistOptions
 
 	if endpoint == "/search" {
 		reqPayload := requestPayloadPool.Get().(*RequestPayload)
@@ -143,6 +395,12 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 			http.Error(w, "Query parameter is required", http.StatusBadRequest)
 			return
 		}
+		var err error
+		mode, err = readability.ParseMode(reqPayload.Mode)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		maxChars = reqPayload.MaxCharPerURL
 		query = reqPayload.Query
 		maxResults = reqPayload.MaxResults
@@ -152,9 +410,10 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 
 		logger.Info("Handling search request", "query", query, "max_results", maxResults)
 
-		var err error
-		searchKey := getSearchCacheKey(query)
-		if cachedURLs, found := sh.Cache.GetSearchURLs(r.Context(), searchKey); found {
+		searchStart := time.Now()
+		searchCache := sh.Caches.Namespace("search")
+		searchKey := searchCache.Key(query)
+		if cachedURLs, found := searchCache.GetSearchURLs(r.Context(), searchKey); found {
 			logger.Info("Search cache HIT", "query", query)
 			urls = cachedURLs
 		} else {
@@ -165,7 +424,10 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 				sh.respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch results from search engine(s): %v", err))
 				return
 			}
-			sh.Cache.Set(r.Context(), searchKey, urls, sh.Config.SearchCacheTTL)
+			searchCache.Set(r.Context(), searchKey, urls, sh.Config.SearchCacheTTL)
+		}
+		if metrics := RequestMetricsFromContext(r.Context()); metrics != nil {
+			metrics.SearchDur = time.Since(searchStart)
 		}
 	} else { // "/extract"
 		reqPayload := extractRequestPayloadPool.Get().(*ExtractRequestPayload)
@@ -181,14 +443,50 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 			http.Error(w, "URLs parameter is required", http.StatusBadRequest)
 			return
 		}
-		const maxURLs = 20
 		if len(reqPayload.URLs) > maxURLs {
 			http.Error(w, fmt.Sprintf("Too many URLs provided. Maximum allowed: %d", maxURLs), http.StatusBadRequest)
 			return
 		}
+		var err error
+		mode, err = readability.ParseMode(reqPayload.Mode)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		urls = reqPayload.URLs
 		maxChars = reqPayload.MaxCharPerURL
+		transcriptOpts = extractor.TranscriptOptions{
+			Start:    reqPayload.TranscriptStart,
+			End:      reqPayload.TranscriptEnd,
+			Language: reqPayload.Language,
+		}
+		playlistOpts = extractor.PlaylistOptions{
+			Prefer:    reqPayload.Prefer,
+			MaxVideos: reqPayload.MaxVideos,
+		}
 		logger.Info("Handling extract request", "url_count", len(urls))
+
+		if reqPayload.Archive {
+			archivePath = reqPayload.ArchivePath
+			if archivePath == "" || !filepath.IsAbs(archivePath) {
+				archivePath = filepath.Join(sh.Config.WARCOutputDir, archivePath)
+			}
+			writer, err := warc.NewWriter(archivePath, sh.Config.WARCRollSizeBytes)
+			if err != nil {
+				logger.Error("Failed to create WARC writer", "path", archivePath, "error", err)
+				sh.respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to start archive: %v", err))
+				return
+			}
+			if err := writer.WriteWarcinfo(map[string]string{
+				"software":      "web-search-api-for-llms",
+				"format":        "WARC File Format 1.1",
+				"isPartOf":      requestID,
+				"json-metadata": fmt.Sprintf(`{"url_count":%d}`, len(urls)),
+			}); err != nil {
+				logger.Warn("Failed to write WARC warcinfo record", "error", err)
+			}
+			archiveWriter = writer
+		}
 	}
 	defer func() {
 		if err := r.Body.Close(); err != nil {
@@ -200,9 +498,26 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 	resultsChan := make(chan *extractor.ExtractedResult, len(urls))
 	var wg sync.WaitGroup
 
+	jobCtx := readability.NewContext(r.Context(), mode)
+	jobCtx = extractor.NewTranscriptContext(jobCtx, transcriptOpts)
+	jobCtx = extractor.NewPlaylistContext(jobCtx, playlistOpts)
+	if archiveWriter != nil {
+		jobCtx = warc.NewContext(jobCtx, archiveWriter)
+	}
+
+	metrics := RequestMetricsFromContext(r.Context())
+
+	cacheStart := time.Now()
 	cachedResults, uncachedURLs := sh.checkContentCache(r.Context(), urls, maxChars)
+	if metrics != nil {
+		metrics.CacheDur = time.Since(cacheStart)
+		metrics.AddCacheHits(int64(len(cachedResults)))
+		metrics.AddCacheMisses(int64(len(uncachedURLs)))
+	}
 	logger.Info("Content cache summary", "total", len(urls), "hits", len(cachedResults), "misses", len(uncachedURLs))
 
+	extractStart := time.Now()
+
 	for _, cachedResult := range cachedResults {
 		resultsChan <- cachedResult
 	}
@@ -215,16 +530,25 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 			Endpoint:   endpoint,
 			MaxChars:   maxChars,
 			ResultChan: make(chan *extractor.ExtractedResult, 1),
-			Context:    r.Context(),
+			Context:    jobCtx,
 		}
 
 		// *** CORE LOGIC CHANGE: Choose the correct worker pool ***
-		if isBrowserJob(targetURL, endpoint) {
+		// Enqueue (rather than a direct JobQueue send) lets a pool with overflow
+		// configured spill to disk instead of blocking this goroutine when a large
+		// batch outruns the in-memory buffer.
+		if IsBrowserJob(targetURL, endpoint) {
 			logger.Debug("Dispatching to BROWSER worker pool", "url", targetURL)
-			sh.BrowserWorkerPool.JobQueue <- job
+			if err := sh.BrowserWorkerPool.Enqueue(job); err != nil {
+				logger.Error("Failed to enqueue browser job", "url", targetURL, "error", err)
+				job.ResultChan <- failedResult(targetURL, err)
+			}
 		} else {
 			logger.Debug("Dispatching to HTTP worker pool", "url", targetURL)
-			sh.HTTPWorkerPool.JobQueue <- job
+			if err := sh.HTTPWorkerPool.Enqueue(job); err != nil {
+				logger.Error("Failed to enqueue HTTP job", "url", targetURL, "error", err)
+				job.ResultChan <- failedResult(targetURL, err)
+			}
 		}
 
 		// Fan-in the results (this part remains the same)
@@ -242,28 +566,55 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 	}()
 
 	// --- Aggregate and respond ---
-	var finalResults []*extractor.ExtractedResult // <-- Change to slice of pointers
-	itemsToCache := make(map[string]interface{})  // Map to hold items for MSet
+	var finalResults []*extractor.ExtractedResult                  // <-- Change to slice of pointers
+	itemsToCacheByGroup := make(map[string]map[string]interface{}) // cache group -> key -> value, for MSet
 
 	// The rest of the aggregation logic can remain mostly the same
 	for res := range resultsChan {
 		finalResults = append(finalResults, res) // Append the pointer directly
 		// No need to add to a separate resultsToPool slice anymore
 
-		// Check if the result was a cache miss and should be cached now.
-		cacheKey := getContentCacheKey(res.URL, maxChars)
-		if res.Error != "" {
-			// Cache permanent errors for a longer duration
-			if checkIfErrorIsPermanent(fmt.Errorf(res.Error)) {
-				itemsToCache[cacheKey] = res
+		if metrics != nil {
+			if res.Error == "" && res.ProcessedSuccessfully {
+				metrics.AddURLsOK(1)
+			} else {
+				metrics.AddURLsFailed(1)
 			}
+		}
+
+		// Check if the result was a cache miss and should be cached now. Each URL's
+		// content is cached under the namespace its extractor belongs to (see
+		// Dispatcher.CacheGroup), so a long YouTube TTL doesn't starve short-lived
+		// search results of capacity and vice versa.
+		group := sh.HTTPWorkerPool.Dispatcher.CacheGroup(res.URL)
+		cacheKey := contentCacheKey(sh.Caches.Namespace(group), res.URL, maxChars)
+		shouldCache := false
+		if res.Error != "" {
+			// Cache permanent errors for a longer duration; transient/rate-limited/
+			// geo-restricted failures are worth retrying, so don't cache those.
+			shouldCache = IsPermanentErrorClass(res.ErrorClass) || CheckIfErrorIsPermanent(fmt.Errorf(res.Error))
 		} else if res.ProcessedSuccessfully {
-			itemsToCache[cacheKey] = res
+			shouldCache = true
+		}
+		if shouldCache {
+			if itemsToCacheByGroup[group] == nil {
+				itemsToCacheByGroup[group] = make(map[string]interface{})
+			}
+			itemsToCacheByGroup[group][cacheKey] = res
+		}
+	}
+	if metrics != nil {
+		metrics.ExtractDur = time.Since(extractStart)
+	}
+
+	if archiveWriter != nil {
+		if err := archiveWriter.Close(); err != nil {
+			logger.Warn("Failed to close WARC writer", "path", archivePath, "error", err)
 		}
 	}
 
-	// Perform a single, pipelined cache write for all successful results
-	if len(itemsToCache) > 0 {
+	// Perform a single, pipelined cache write per group for all successful results
+	for group, itemsToCache := range itemsToCacheByGroup {
 		// Create a deep copy of the items to be cached to prevent a race condition.
 		// The race occurs because the original `finalResults` slice, which `itemsToCache`
 		// points to, gets its objects reset and returned to a sync.Pool immediately
@@ -280,14 +631,14 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 			}
 		}
 
-		logger.Debug("Performing batched cache write", "item_count", len(itemsToCacheCopy))
+		logger.Debug("Performing batched cache write", "group", group, "item_count", len(itemsToCacheCopy))
 		// Use a background context for the cache write so it doesn't block the response.
 		// Pass the copied map to the goroutine.
-		go func(items map[string]interface{}) {
-			if err := sh.Cache.MSet(context.Background(), items, sh.Config.ContentCacheTTL); err != nil {
-				slog.Error("Failed to cache items", "error", err)
+		go func(group string, items map[string]interface{}) {
+			if err := sh.Caches.Namespace(group).MSet(context.Background(), items, sh.Config.ContentCacheTTL); err != nil {
+				slog.Error("Failed to cache items", "group", group, "error", err)
 			}
-		}(itemsToCacheCopy)
+		}(group, itemsToCacheCopy)
 	}
 
 	logger.Info("Finished all extractions", "count", len(finalResults))
@@ -317,6 +668,9 @@ func (sh *SearchHandler) processRequest(w http.ResponseWriter, r *http.Request,
 		resp := ExtractResponsePayload{Results: finalResults}
 		resp.RequestDetails.URLsRequested = len(urls)
 		resp.RequestDetails.URLsProcessed = len(finalResults)
+		if archiveWriter != nil {
+			resp.ArchivePath = archivePath
+		}
 		if err := json.NewEncoder(w).Encode(resp); err != nil {
 			logger.Error("Error encoding extract response", "error", err)
 		}
@@ -338,45 +692,62 @@ func (sh *SearchHandler) checkContentCache(ctx context.Context, urls []string, m
 		return nil, nil
 	}
 
-	keysToCheck := make([]string, len(urls))
-	urlToCacheKey := make(map[string]string, len(urls))
-	for i, u := range urls {
-		key := getContentCacheKey(u, maxChars)
-		keysToCheck[i] = key
-		urlToCacheKey[u] = key
+	// Group URLs by the per-source-type cache namespace Dispatcher.CacheGroup would
+	// route their eventual extraction into, so each group's MGET stays within its own
+	// namespace instead of mixing e.g. "youtube" and "pdf" keys in the same call.
+	urlsByGroup := make(map[string][]string)
+	for _, u := range urls {
+		group := sh.HTTPWorkerPool.Dispatcher.CacheGroup(u)
+		urlsByGroup[group] = append(urlsByGroup[group], u)
 	}
 
-	foundMap, err := sh.Cache.MGetExtractedResults(ctx, keysToCheck)
-	if err != nil {
-		slog.Warn("Cache MGET failed, falling back to individual gets", "error", err)
-		return sh.checkContentCacheIndividually(ctx, urls, maxChars) // Keep the old logic as a fallback
-	}
+	for group, groupURLs := range urlsByGroup {
+		groupCache := sh.Caches.Namespace(group)
+		keysToCheck := make([]string, len(groupURLs))
+		urlToCacheKey := make(map[string]string, len(groupURLs))
+		for i, u := range groupURLs {
+			key := contentCacheKey(groupCache, u, maxChars)
+			keysToCheck[i] = key
+			urlToCacheKey[u] = key
+		}
 
-	// Process batched results
-	foundKeys := make(map[string]bool)
-	for key, result := range foundMap {
-		cachedResults = append(cachedResults, result)
-		foundKeys[key] = true
-	}
+		foundMap, err := groupCache.MGetExtractedResults(ctx, keysToCheck)
+		if err != nil {
+			slog.Warn("Cache MGET failed, falling back to individual gets", "group", group, "error", err)
+			groupCached, groupUncached := sh.checkContentCacheIndividually(ctx, group, groupURLs, maxChars)
+			cachedResults = append(cachedResults, groupCached...)
+			uncachedURLs = append(uncachedURLs, groupUncached...)
+			continue
+		}
 
-	// Determine which URLs were not in the cache
-	for _, u := range urls {
-		key := urlToCacheKey[u]
-		if !foundKeys[key] {
-			uncachedURLs = append(uncachedURLs, u)
+		// Process batched results
+		foundKeys := make(map[string]bool)
+		for key, result := range foundMap {
+			cachedResults = append(cachedResults, result)
+			foundKeys[key] = true
+		}
+
+		// Determine which URLs were not in the cache
+		for _, u := range groupURLs {
+			key := urlToCacheKey[u]
+			if !foundKeys[key] {
+				uncachedURLs = append(uncachedURLs, u)
+			}
 		}
 	}
 	return cachedResults, uncachedURLs
 }
 
-// checkContentCacheIndividually is the fallback for non-redis or failed MGET
-func (sh *SearchHandler) checkContentCacheIndividually(ctx context.Context, urls []string, maxChars *int) (
+// checkContentCacheIndividually is the fallback for non-redis or failed MGET, scoped to
+// a single cache group (see checkContentCache).
+func (sh *SearchHandler) checkContentCacheIndividually(ctx context.Context, group string, urls []string, maxChars *int) (
 	cachedResults []*extractor.ExtractedResult,
 	uncachedURLs []string,
 ) {
+	groupCache := sh.Caches.Namespace(group)
 	for _, u := range urls {
-		key := getContentCacheKey(u, maxChars)
-		if cachedResult, found := sh.Cache.GetExtractedResult(ctx, key); found {
+		key := contentCacheKey(groupCache, u, maxChars)
+		if cachedResult, found := groupCache.GetExtractedResult(ctx, key); found {
 			cachedResults = append(cachedResults, cachedResult)
 		} else {
 			uncachedURLs = append(uncachedURLs, u)
@@ -393,20 +764,23 @@ func (sh *SearchHandler) respondWithError(w http.ResponseWriter, code int, messa
 	}
 }
 
-// ... (Helper functions like getSearchCacheKey, getContentCacheKey, checkIfErrorIsPermanent)
-func getSearchCacheKey(query string) string { return "search_cache:" + query }
-func getContentCacheKey(url string, maxChars *int) string {
-	var sb strings.Builder
-	sb.WriteString("content_cache:")
-	sb.WriteString(url)
-	if maxChars != nil {
-		sb.WriteString(":")
-		// A small optimization: convert int to string without fmt.
-		sb.WriteString(strconv.Itoa(*maxChars))
-	}
-	return sb.String()
+// contentCacheKey builds ns's cache key for a URL, folding in maxChars since the same
+// URL extracted with two different character caps isn't the same cached value.
+func contentCacheKey(ns *cache.Namespace, url string, maxChars *int) string {
+	if maxChars == nil {
+		return ns.Key(url)
+	}
+	return ns.Key(url, strconv.Itoa(*maxChars))
 }
-func checkIfErrorIsPermanent(err error) bool {
+
+// isPermanentErrorClass reports whether ec classifies a failure that won't succeed on
+// retry, so it's safe to cache for the normal TTL rather than the backoff the result
+// pipeline gives transient/rate-limited/geo-restricted failures.
+func IsPermanentErrorClass(ec extractor.ErrorClass) bool {
+	return ec == extractor.ErrorClassPermanent || ec == extractor.ErrorClassUnavailable
+}
+
+func CheckIfErrorIsPermanent(err error) bool {
 	if err == nil {
 		return false
 	}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// requestMetricsKeyType is an unexported type so no other package can collide with
+// requestMetricsKey by constructing an equal context key.
+type requestMetricsKeyType struct{}
+
+var requestMetricsKey = requestMetricsKeyType{}
+
+// RequestMetrics accumulates the per-request counters and phase timings
+// accessLogMiddleware (main.go) logs and, when AppConfig.AccessLogServerTiming is set,
+// reports back to the client via a Server-Timing header. CacheHits/CacheMisses/
+// URLsOK/URLsFailed are written from both the handling goroutine and its worker fan-in
+// goroutines, so they're updated with atomic.AddInt64; the *Dur fields are only ever
+// written once, from processRequest's own goroutine, after the phase they time
+// completes.
+type RequestMetrics struct {
+	CacheHits   int64
+	CacheMisses int64
+	URLsOK      int64
+	URLsFailed  int64
+
+	SearchDur  time.Duration
+	ExtractDur time.Duration
+	CacheDur   time.Duration
+
+	// BytesOutRaw is the response body size before gzipMiddleware compresses it, tracked
+	// separately from the post-compression byte count the access-log ResponseWriter
+	// wrapper counts on the wire side.
+	BytesOutRaw int64
+}
+
+// AddCacheHits/AddCacheMisses/AddURLsOK/AddURLsFailed/AddBytesOutRaw increment their
+// counter by n, atomically so concurrent worker fan-in goroutines (or, for
+// AddBytesOutRaw, chunked Write calls) can call them safely.
+func (m *RequestMetrics) AddCacheHits(n int64)   { atomic.AddInt64(&m.CacheHits, n) }
+func (m *RequestMetrics) AddCacheMisses(n int64) { atomic.AddInt64(&m.CacheMisses, n) }
+func (m *RequestMetrics) AddURLsOK(n int64)      { atomic.AddInt64(&m.URLsOK, n) }
+func (m *RequestMetrics) AddURLsFailed(n int64)  { atomic.AddInt64(&m.URLsFailed, n) }
+func (m *RequestMetrics) AddBytesOutRaw(n int64) { atomic.AddInt64(&m.BytesOutRaw, n) }
+
+// NewRequestMetricsContext attaches a fresh *RequestMetrics to ctx for a handler to
+// populate over the life of the request; RequestMetricsFromContext retrieves it
+// afterward. Returns the same *RequestMetrics for convenience.
+func NewRequestMetricsContext(ctx context.Context) (context.Context, *RequestMetrics) {
+	m := &RequestMetrics{}
+	return context.WithValue(ctx, requestMetricsKey, m), m
+}
+
+// RequestMetricsFromContext retrieves the *RequestMetrics NewRequestMetricsContext
+// attached to ctx, or nil if none was attached (e.g. a request that never reached
+// SearchHandler).
+func RequestMetricsFromContext(ctx context.Context) *RequestMetrics {
+	m, _ := ctx.Value(requestMetricsKey).(*RequestMetrics)
+	return m
+}
+
+// ServerTimingHeader formats m's phase durations as a Server-Timing header value, e.g.
+// "search;dur=12.3, extract;dur=340.1, cache;dur=1.2", so client-side tooling can
+// visualize where a request's time went. A zero-valued phase is omitted.
+func ServerTimingHeader(m *RequestMetrics) string {
+	if m == nil {
+		return ""
+	}
+	var entries []string
+	for _, phase := range []struct {
+		name string
+		dur  time.Duration
+	}{
+		{"search", m.SearchDur},
+		{"cache", m.CacheDur},
+		{"extract", m.ExtractDur},
+	} {
+		if phase.dur > 0 {
+			entries = append(entries, fmt.Sprintf("%s;dur=%.1f", phase.name, float64(phase.dur.Microseconds())/1000))
+		}
+	}
+	return strings.Join(entries, ", ")
+}
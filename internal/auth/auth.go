@@ -0,0 +1,261 @@
+// Package auth implements JWT bearer-token authentication and per-subject rate limiting
+// for the public API. Authenticator.Require wraps an individual http.HandlerFunc (rather
+// than the whole mux, like api.AdminHandler's X-Admin-Key check does for /admin/*) so
+// each endpoint can declare its own required scope.
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/time/rate"
+
+	"web-search-api-for-llms/internal/config"
+)
+
+// Claims is the JWT payload Authenticator expects: the standard sub/exp (via
+// jwt.RegisteredClaims) plus RAG-Forge-specific authorization and rate-limit hints.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Scope lists the endpoints this token may call: "/search", "/extract", "/debug/*",
+	// or "*" for all of them. Require rejects a request whose endpoint isn't covered.
+	Scope []string `json:"scope,omitempty"`
+	// RateLimit is this subject's requests-per-minute token-bucket rate. Zero falls
+	// back to config.AppConfig.AuthDefaultRateLimit.
+	RateLimit int `json:"rate_limit,omitempty"`
+	// MaxConcurrency caps this subject's in-flight requests across all endpoints. Zero
+	// falls back to config.AppConfig.AuthDefaultMaxConcurrency (itself zero meaning
+	// unlimited).
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+}
+
+// Authenticator verifies bearer tokens against the signing key(s) configured via
+// config.AppConfig.Auth* (HS256 shared secret, or RS256 via a static public key or a
+// JWKS URL), and enforces each verified token's scope and rate_limit/max_concurrency.
+type Authenticator struct {
+	cfg     *config.AppConfig
+	keyFunc jwt.Keyfunc
+	jwks    *jwksCache // non-nil only when cfg.AuthJWKSURL is set
+
+	mu       sync.Mutex
+	limiters map[string]*subjectLimiter
+}
+
+// subjectLimiter is one subject's token bucket and (optional) concurrency semaphore,
+// created lazily the first time that subject authenticates and kept for the process
+// lifetime (cardinality is bounded by the number of distinct tokens ever minted, which
+// for an API-key-style deployment is small).
+type subjectLimiter struct {
+	bucket *rate.Limiter
+	sem    chan struct{} // nil means no concurrency cap
+}
+
+// New builds an Authenticator from cfg's AuthAlgorithm/AuthHMACSecret/AuthRSAPublicKey/
+// AuthJWKSURL. Returns an error if AuthEnabled but the configured key material doesn't
+// parse; config.AppConfig.Validate already rejects the case where it's simply missing.
+func New(cfg *config.AppConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		cfg:      cfg,
+		limiters: make(map[string]*subjectLimiter),
+	}
+
+	switch cfg.AuthAlgorithm {
+	case "HS256":
+		secret := []byte(cfg.AuthHMACSecret)
+		a.keyFunc = func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method %v, want HS256", t.Method.Alg())
+			}
+			return secret, nil
+		}
+	case "RS256":
+		if cfg.AuthJWKSURL != "" {
+			a.jwks = newJWKSCache(cfg.AuthJWKSURL, cfg.AuthJWKSRefreshInterval)
+			a.keyFunc = func(t *jwt.Token) (interface{}, error) {
+				if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+					return nil, fmt.Errorf("unexpected signing method %v, want RS256", t.Method.Alg())
+				}
+				kid, _ := t.Header["kid"].(string)
+				return a.jwks.key(kid)
+			}
+		} else {
+			pub, err := parseRSAPublicKeyPEM(cfg.AuthRSAPublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid AUTH_RSA_PUBLIC_KEY: %w", err)
+			}
+			a.keyFunc = func(t *jwt.Token) (interface{}, error) {
+				if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+					return nil, fmt.Errorf("unexpected signing method %v, want RS256", t.Method.Alg())
+				}
+				return pub, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported AUTH_ALGORITHM: %s", cfg.AuthAlgorithm)
+	}
+
+	return a, nil
+}
+
+// Require wraps next so a request must carry a valid bearer token authorized for scope
+// (exact match, or a "*" in the token's Scope) before next runs. When cfg.AuthEnabled is
+// false, Require is a no-op passthrough, for local/dev deployments. Every attempt, denied
+// or not, is logged via slog with the subject (once known), scope, response status, and
+// latency.
+func (a *Authenticator) Require(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.cfg.AuthEnabled {
+			next(w, r)
+			return
+		}
+
+		start := time.Now()
+		claims, err := a.authenticate(r)
+		if err != nil {
+			a.audit(scope, "", http.StatusUnauthorized, time.Since(start))
+			respondError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if !authorizedForScope(claims.Scope, scope) {
+			a.audit(scope, claims.Subject, http.StatusForbidden, time.Since(start))
+			respondError(w, http.StatusForbidden, fmt.Sprintf("token is not authorized for %s", scope))
+			return
+		}
+
+		limiter := a.limiterFor(claims)
+		if !limiter.bucket.Allow() {
+			a.audit(scope, claims.Subject, http.StatusTooManyRequests, time.Since(start))
+			respondError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		if limiter.sem != nil {
+			select {
+			case limiter.sem <- struct{}{}:
+				defer func() { <-limiter.sem }()
+			default:
+				a.audit(scope, claims.Subject, http.StatusTooManyRequests, time.Since(start))
+				respondError(w, http.StatusTooManyRequests, "too many concurrent requests")
+				return
+			}
+		}
+
+		sw := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next(sw, r)
+		a.audit(scope, claims.Subject, sw.status, time.Since(start))
+	}
+}
+
+// authenticate extracts and verifies the request's bearer token, returning its Claims.
+func (a *Authenticator) authenticate(r *http.Request) (*Claims, error) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+	if token == "" {
+		return nil, errors.New("missing bearer token")
+	}
+
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, a.keyFunc, jwt.WithValidMethods([]string{"HS256", "RS256"}))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if !parsed.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token is missing sub claim")
+	}
+	return claims, nil
+}
+
+// authorizedForScope reports whether scopes authorizes endpoint, either by an exact
+// match or a "*" wildcard entry.
+func authorizedForScope(scopes []string, endpoint string) bool {
+	for _, s := range scopes {
+		if s == "*" || s == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// limiterFor returns claims.Subject's subjectLimiter, creating one from the claims'
+// rate_limit/max_concurrency (falling back to the configured defaults) on first use.
+func (a *Authenticator) limiterFor(claims *Claims) *subjectLimiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if lim, ok := a.limiters[claims.Subject]; ok {
+		return lim
+	}
+
+	rpm := claims.RateLimit
+	if rpm <= 0 {
+		rpm = a.cfg.AuthDefaultRateLimit
+	}
+	maxConcurrency := claims.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = a.cfg.AuthDefaultMaxConcurrency
+	}
+
+	lim := &subjectLimiter{bucket: rate.NewLimiter(rate.Limit(float64(rpm)/60.0), maxInt(rpm, 1))}
+	if maxConcurrency > 0 {
+		lim.sem = make(chan struct{}, maxConcurrency)
+	}
+	a.limiters[claims.Subject] = lim
+	return lim
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// audit emits a structured log line for one authentication attempt, per the request's
+// "audit logs (sub, endpoint, status, latency)" requirement.
+func (a *Authenticator) audit(endpoint, sub string, status int, latency time.Duration) {
+	slog.Info("auth audit", "sub", sub, "endpoint", endpoint, "status", status, "latency_ms", latency.Milliseconds())
+}
+
+// statusResponseWriter wraps http.ResponseWriter to capture the status code next writes,
+// for Require's audit log.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := fmt.Fprintf(w, `{"error":%q}`, message); err != nil {
+		slog.Warn("Failed to write auth error response", "error", err)
+	}
+}
+
+// parseRSAPublicKeyPEM parses a PEM-encoded RSA public key (PKIX or PKCS1).
+func parseRSAPublicKeyPEM(pemStr string) (*rsa.PublicKey, error) {
+	return jwt.ParseRSAPublicKeyFromPEM([]byte(pemStr))
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ChallengeKind identifies which login hurdle a ChallengeSolver is being asked to clear.
+type ChallengeKind string
+
+const (
+	// ChallengeEmailVerification is the "confirm your identity" screen shown when a
+	// login looks unusual, asking the user to re-enter their email address or phone
+	// number.
+	ChallengeEmailVerification ChallengeKind = "email_verification"
+	// ChallengeTOTP is a standard 2FA code prompt (authenticator app or SMS).
+	ChallengeTOTP ChallengeKind = "totp"
+)
+
+// ChallengeSolver supplies the value needed to clear a login challenge (the email/phone
+// to confirm, or a TOTP code) for the given username. Implementations may block while
+// waiting on an out-of-band source such as a human, an authenticator app, or an inbox.
+type ChallengeSolver interface {
+	Solve(ctx context.Context, username string, kind ChallengeKind) (string, error)
+}
+
+// EnvChallengeSolver reads challenge responses from environment variables named
+// "<Prefix>_<USERNAME>_<KIND>" (e.g. "TWITTER_CHALLENGE_ALICE_TOTP"), uppercased. It's
+// the simplest solver, useful for accounts where the email/phone to confirm is static or
+// a TOTP code can be precomputed and exported before the login attempt runs.
+type EnvChallengeSolver struct {
+	Prefix string
+}
+
+// Solve implements ChallengeSolver.
+func (s EnvChallengeSolver) Solve(_ context.Context, username string, kind ChallengeKind) (string, error) {
+	key := fmt.Sprintf("%s_%s_%s", s.Prefix, strings.ToUpper(username), strings.ToUpper(string(kind)))
+	value := os.Getenv(key)
+	if value == "" {
+		return "", fmt.Errorf("no challenge response available in %s for %s/%s", key, username, kind)
+	}
+	return value, nil
+}
+
+// FileChallengeSolver reads the challenge response from a file under Dir named
+// "<username>.<kind>" (e.g. "alice.totp"), trimmed of surrounding whitespace. It's meant
+// for responses dropped by an external process, such as a TOTP generator or a human
+// pasting an emailed code into a watched file, shortly before the solver is polled.
+type FileChallengeSolver struct {
+	Dir string
+}
+
+// Solve implements ChallengeSolver.
+func (s FileChallengeSolver) Solve(_ context.Context, username string, kind ChallengeKind) (string, error) {
+	path := filepath.Join(s.Dir, fmt.Sprintf("%s.%s", username, kind))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read challenge response from %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// challengeCallbackRequest is the JSON body HTTPChallengeSolver posts to its callback URL.
+type challengeCallbackRequest struct {
+	Username string        `json:"username"`
+	Kind     ChallengeKind `json:"kind"`
+}
+
+// HTTPChallengeSolver posts the challenge request to a callback URL and reads the
+// response body as the solved value. This is the extension point for wiring a login
+// flow up to a paging system or chat bot that a human operator answers from their phone.
+type HTTPChallengeSolver struct {
+	URL    string
+	Client *http.Client
+}
+
+// Solve implements ChallengeSolver.
+func (s HTTPChallengeSolver) Solve(ctx context.Context, username string, kind ChallengeKind) (string, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	body, err := json.Marshal(challengeCallbackRequest{Username: username, Kind: kind})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal challenge callback request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build challenge callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("challenge callback request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("challenge callback returned status %d", resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read challenge callback response: %w", err)
+	}
+	return strings.TrimSpace(string(respBody)), nil
+}
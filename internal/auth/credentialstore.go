@@ -0,0 +1,192 @@
+// Package auth provides a persistent, multi-account credential store for extractors
+// that authenticate via cookie-based browser sessions (see TwitterExtractor), along
+// with a pluggable ChallengeSolver for the "unusual login" email-verification and 2FA
+// code screens those sites show during automated login.
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// credentialStoreMasterKeyEnv names the environment variable CredentialStore derives its
+// AES-256-GCM encryption key from. Any length/format passphrase is accepted; it's
+// SHA-256-hashed down to a 32-byte key rather than required to already be one, the same
+// way many CLI tools (e.g. age's passphrase mode) treat a "master key" env var as a
+// human-supplied secret rather than raw key material.
+const credentialStoreMasterKeyEnv = "RAG_FORGE_MASTER_KEY"
+
+// Cookie is a browser-agnostic cookie persisted as part of one account's session.
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Secure   bool      `json:"secure"`
+	HTTPOnly bool      `json:"httpOnly"`
+	Expires  time.Time `json:"expires"`
+}
+
+// CredentialStore persists cookie sessions for multiple accounts under a directory, one
+// file per username, so a server rotating between several logged-in accounts doesn't
+// clobber a single shared cookie file the way the old twitter_cookies.json did. When
+// RAG_FORGE_MASTER_KEY is set, every session file is AES-256-GCM encrypted at rest, so a
+// leaked backup or a compromised host's disk doesn't also hand over every account's live
+// session cookies; when it's unset, sessions are stored as plain JSON exactly as before.
+type CredentialStore struct {
+	dir string
+	mu  sync.RWMutex
+
+	// key is nil unless RAG_FORGE_MASTER_KEY is set, in which case Save/Load AES-GCM
+	// encrypt/decrypt the session file instead of reading/writing plain JSON.
+	key []byte
+}
+
+// NewCredentialStore creates a CredentialStore rooted at dir, creating dir (and any
+// missing parents) with owner-only permissions if it doesn't already exist.
+func NewCredentialStore(dir string) (*CredentialStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("credential store directory must not be empty")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create credential store directory %s: %w", dir, err)
+	}
+
+	store := &CredentialStore{dir: dir}
+	if passphrase := os.Getenv(credentialStoreMasterKeyEnv); passphrase != "" {
+		key := sha256.Sum256([]byte(passphrase))
+		store.key = key[:]
+	}
+	return store, nil
+}
+
+// pathFor returns the on-disk path for username's session file. filepath.Base strips
+// any path separators username might contain, so a malicious or malformed username
+// can't escape the store directory.
+func (s *CredentialStore) pathFor(username string) string {
+	return filepath.Join(s.dir, filepath.Base(username)+".json")
+}
+
+// Save persists cookies for username, overwriting any previously stored session. The
+// file is written with 0600 permissions since it contains live session credentials.
+func (s *CredentialStore) Save(username string, cookies []Cookie) error {
+	if username == "" {
+		return fmt.Errorf("username must not be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cookies for %s: %w", username, err)
+	}
+
+	if s.key != nil {
+		data, err = encryptCredentials(s.key, data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt cookies for %s: %w", username, err)
+		}
+	}
+
+	if err := os.WriteFile(s.pathFor(username), data, 0600); err != nil {
+		return fmt.Errorf("failed to save cookies for %s: %w", username, err)
+	}
+	return nil
+}
+
+// Load returns the cookies previously saved for username, or ok=false if no session has
+// been stored (or it could not be read/parsed).
+func (s *CredentialStore) Load(username string) (cookies []Cookie, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(s.pathFor(username))
+	if err != nil {
+		return nil, false
+	}
+
+	if s.key != nil {
+		data, err = decryptCredentials(s.key, data)
+		if err != nil {
+			return nil, false
+		}
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, false
+	}
+	return cookies, true
+}
+
+// IsLoggedIn reports whether a session has been saved for username. It only checks that
+// a session file exists; it does not verify the session is still accepted by the remote
+// site, since that requires an actual request.
+func (s *CredentialStore) IsLoggedIn(username string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := os.Stat(s.pathFor(username))
+	return err == nil
+}
+
+// Logout removes the stored session for username, if any.
+func (s *CredentialStore) Logout(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.pathFor(username)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stored session for %s: %w", username, err)
+	}
+	return nil
+}
+
+// encryptCredentials AES-256-GCM encrypts plaintext under key, prefixing the returned
+// ciphertext with its random nonce so decryptCredentials doesn't need it passed
+// separately.
+func encryptCredentials(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init AES cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init AES-GCM: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptCredentials reverses encryptCredentials.
+func decryptCredentials(key, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init AES cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init AES-GCM: %w", err)
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
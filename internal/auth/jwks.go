@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksCache fetches and caches a JSON Web Key Set, refreshing it on a timer so
+// Authenticator.keyFunc can look up a token's signing key by "kid" without a network
+// round-trip on every request. Modeled on searxng.InstancePool's refresh-on-a-timer,
+// keep-stale-data-on-failure approach.
+type jwksCache struct {
+	url        string
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+// newJWKSCache creates a jwksCache and starts its background refresh loop.
+func newJWKSCache(url string, refreshInterval time.Duration) *jwksCache {
+	c := &jwksCache{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       make(map[string]*rsa.PublicKey),
+	}
+	go c.refreshLoop(refreshInterval)
+	return c
+}
+
+func (c *jwksCache) refreshLoop(interval time.Duration) {
+	c.refresh()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.refresh()
+	}
+}
+
+// refresh fetches and decodes c.url, replacing the cached key set on success. On
+// failure it logs and keeps the previous key set, so a transient JWKS-endpoint outage
+// doesn't invalidate every token already relying on it.
+func (c *jwksCache) refresh() {
+	keys, err := c.fetch()
+	if err != nil {
+		slog.Warn("auth: JWKS refresh failed, keeping previous key set", "url", c.url, "error", err)
+		return
+	}
+	c.mu.Lock()
+	c.keys = keys
+	c.mu.Unlock()
+	slog.Info("auth: JWKS refreshed", "url", c.url, "keys", len(keys))
+}
+
+func (c *jwksCache) fetch() (map[string]*rsa.PublicKey, error) {
+	resp, err := c.httpClient.Get(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching JWKS: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS request failed with status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("error decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		pub, err := rsaPublicKeyFromJWK(k.N, k.E)
+		if err != nil {
+			slog.Warn("auth: skipping unparseable JWKS entry", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+// key looks up kid in the cache, for Authenticator's RS256 jwt.Keyfunc.
+func (c *jwksCache) key(kid string) (*rsa.PublicKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("token has no kid header")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("no JWKS key found for kid %q", kid)
+	}
+	return key, nil
+}
+
+// rsaPublicKeyFromJWK decodes an RSA JWK's base64url-encoded modulus (n) and exponent
+// (e) fields into an *rsa.PublicKey.
+func rsaPublicKeyFromJWK(nEncoded, eEncoded string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(nEncoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(eEncoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
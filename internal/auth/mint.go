@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"web-search-api-for-llms/internal/config"
+)
+
+// MintToken signs a development token for sub, for the "mint-token" CLI subcommand (see
+// main.go's runMintToken). Only HS256 is supported here: minting an RS256 token needs
+// the JWKS/public-key deployment's private key, which this service never holds.
+func MintToken(cfg *config.AppConfig, sub string, scope []string, ttl time.Duration, rateLimit, maxConcurrency int) (string, error) {
+	if cfg.AuthAlgorithm != "HS256" {
+		return "", fmt.Errorf("mint-token only supports AUTH_ALGORITHM=HS256 (got %s); sign RS256 tokens with your own private key instead", cfg.AuthAlgorithm)
+	}
+	if cfg.AuthHMACSecret == "" {
+		return "", fmt.Errorf("AUTH_HMAC_SECRET is not set")
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Scope:          scope,
+		RateLimit:      rateLimit,
+		MaxConcurrency: maxConcurrency,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.AuthHMACSecret))
+}
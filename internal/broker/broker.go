@@ -0,0 +1,205 @@
+// Package broker consumes extraction jobs from a queue (AMQP or Redis Streams,
+// depending on AppConfig.QueueBackend) and publishes their results back to a reply
+// queue, so other services can fan out extraction work without going through the HTTP
+// API.
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"web-search-api-for-llms/internal/api"
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/extractor"
+	"web-search-api-for-llms/internal/worker"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// queueJobEndpoint is the pseudo-endpoint broker jobs dispatch under, shared by the AMQP
+// and Redis Streams consumers. Queue jobs always get the full-compatibility treatment
+// HTTP's /extract gets (see api.IsBrowserJob), since a queue producer has no equivalent
+// of /search's lightweight-vs-JS-heavy URL list.
+const queueJobEndpoint = "/extract"
+
+// Job is the JSON payload a producer publishes to AppConfig.QueueName, e.g.
+// {"url":"https://example.com","max_chars":50000,"reply_to":"resultsQueue","correlation_id":"abc123"}.
+type Job struct {
+	URL           string `json:"url"`
+	MaxChars      *int   `json:"max_chars,omitempty"`
+	ReplyTo       string `json:"reply_to"`
+	CorrelationID string `json:"correlation_id"`
+}
+
+// Consumer pulls Jobs off an AMQP queue, dispatches them through the same HTTP/browser
+// worker pools SearchHandler uses, and publishes the resulting *extractor.ExtractedResult
+// back to each job's ReplyTo queue.
+type Consumer struct {
+	cfg     *config.AppConfig
+	conn    *amqp.Connection
+	channel *amqp.Channel
+
+	httpWorkerPool    *worker.WorkerPool
+	browserWorkerPool *worker.WorkerPool
+
+	consumerTag string
+	wg          sync.WaitGroup
+}
+
+// NewConsumer dials cfg.QueueURL, opens a channel capped at cfg.QueuePrefetch in-flight
+// jobs (matching AMQP backpressure to the worker pools' own capacity), and declares
+// cfg.QueueName durable with cfg.AMQPDLX as its dead-letter exchange when configured.
+func NewConsumer(cfg *config.AppConfig, httpWorkerPool, browserWorkerPool *worker.WorkerPool) (*Consumer, error) {
+	conn, err := amqp.Dial(cfg.QueueURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial AMQP broker: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open AMQP channel: %w", err)
+	}
+
+	var args amqp.Table
+	if cfg.AMQPDLX != "" {
+		args = amqp.Table{"x-dead-letter-exchange": cfg.AMQPDLX}
+	}
+	if _, err := channel.QueueDeclare(cfg.QueueName, true, false, false, false, args); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare AMQP queue %q: %w", cfg.QueueName, err)
+	}
+
+	if err := channel.Qos(cfg.QueuePrefetch, 0, false); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to set AMQP QoS: %w", err)
+	}
+
+	return &Consumer{
+		cfg:               cfg,
+		conn:              conn,
+		channel:           channel,
+		httpWorkerPool:    httpWorkerPool,
+		browserWorkerPool: browserWorkerPool,
+		consumerTag:       "web-search-api-for-llms",
+	}, nil
+}
+
+// Start begins consuming cfg.QueueName in a background goroutine. Each delivery is
+// dispatched concurrently (bounded by the underlying worker pool, same as HTTP traffic),
+// so Start returns immediately.
+func (c *Consumer) Start() error {
+	deliveries, err := c.channel.Consume(c.cfg.QueueName, c.consumerTag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start consuming AMQP queue %q: %w", c.cfg.QueueName, err)
+	}
+
+	go func() {
+		for d := range deliveries {
+			c.wg.Add(1)
+			go c.handleDelivery(d)
+		}
+	}()
+
+	slog.Info("AMQP broker consumer started", "queue", c.cfg.QueueName, "prefetch", c.cfg.QueuePrefetch)
+	return nil
+}
+
+// Stop cancels the consumer so no new deliveries arrive, waits for every in-flight job
+// to finish (so a shutdown can't drop a job mid-extraction), then closes the channel and
+// connection.
+func (c *Consumer) Stop() error {
+	if err := c.channel.Cancel(c.consumerTag, false); err != nil {
+		slog.Warn("AMQP broker: failed to cancel consumer", "error", err)
+	}
+	c.wg.Wait()
+	if err := c.channel.Close(); err != nil {
+		slog.Warn("AMQP broker: failed to close channel", "error", err)
+	}
+	return c.conn.Close()
+}
+
+// handleDelivery dispatches one Job through the worker pool api.IsBrowserJob selects,
+// publishes its result to ReplyTo, and acks or nacks d depending on whether the failure
+// (if any) looks retryable.
+func (c *Consumer) handleDelivery(d amqp.Delivery) {
+	defer c.wg.Done()
+
+	var job Job
+	if err := json.Unmarshal(d.Body, &job); err != nil {
+		slog.Error("AMQP broker: malformed job payload, dropping", "error", err)
+		if err := d.Nack(false, false); err != nil {
+			slog.Warn("AMQP broker: failed to nack malformed job", "error", err)
+		}
+		return
+	}
+
+	pool := c.httpWorkerPool
+	if api.IsBrowserJob(job.URL, queueJobEndpoint) {
+		pool = c.browserWorkerPool
+	}
+
+	resultChan := make(chan *extractor.ExtractedResult, 1)
+	if err := pool.Enqueue(worker.Job{
+		URL:        job.URL,
+		Endpoint:   queueJobEndpoint,
+		MaxChars:   job.MaxChars,
+		ResultChan: resultChan,
+		Context:    context.Background(),
+	}); err != nil {
+		slog.Error("AMQP broker: failed to enqueue job, nacking for redelivery", "url", job.URL, "error", err)
+		if err := d.Nack(false, true); err != nil {
+			slog.Warn("AMQP broker: failed to nack job after enqueue failure", "error", err)
+		}
+		return
+	}
+	result := <-resultChan
+	defer func() {
+		result.Reset()
+		extractor.ExtractedResultPool.Put(result)
+	}()
+
+	if err := c.publishResult(job, result); err != nil {
+		slog.Error("AMQP broker: failed to publish result", "url", job.URL, "reply_to", job.ReplyTo, "error", err)
+	}
+
+	if result.Error != "" && !isPermanentFailure(result) {
+		// Transient failure: requeue so another worker (here or on another instance) retries it.
+		if err := d.Nack(false, true); err != nil {
+			slog.Warn("AMQP broker: failed to nack job for requeue", "url", job.URL, "error", err)
+		}
+		return
+	}
+	if err := d.Ack(false); err != nil {
+		slog.Warn("AMQP broker: failed to ack job", "url", job.URL, "error", err)
+	}
+}
+
+// isPermanentFailure mirrors the cache layer's retry/don't-retry split (see
+// api.IsPermanentErrorClass and api.CheckIfErrorIsPermanent) to decide whether a failed
+// job should be dead-lettered (via a nack without requeue) instead of retried.
+func isPermanentFailure(result *extractor.ExtractedResult) bool {
+	return api.IsPermanentErrorClass(result.ErrorClass) || api.CheckIfErrorIsPermanent(fmt.Errorf(result.Error))
+}
+
+// publishResult publishes result to job.ReplyTo, carrying job.CorrelationID so the
+// producer can match it back to its request. A job with no ReplyTo is fire-and-forget.
+func (c *Consumer) publishResult(job Job, result *extractor.ExtractedResult) error {
+	if job.ReplyTo == "" {
+		return nil
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return c.channel.PublishWithContext(context.Background(), "", job.ReplyTo, false, false, amqp.Publishing{
+		ContentType:   "application/json",
+		Body:          body,
+		CorrelationId: job.CorrelationID,
+	})
+}
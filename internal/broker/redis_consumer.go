@@ -0,0 +1,261 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"web-search-api-for-llms/internal/api"
+	"web-search-api-for-llms/internal/cache"
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/extractor"
+	"web-search-api-for-llms/internal/worker"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// redisStreamGroup is the consumer group every RedisStreamConsumer joins. A single fixed
+// group name is enough because RAG-Forge instances sharing a queue are meant to load
+// balance the same stream, not read it independently.
+const redisStreamGroup = "web-search-api-for-llms"
+
+// redisStreamBlock bounds how long XREADGROUP waits for new entries before looping back
+// to check for shutdown, so Stop doesn't have to wait an arbitrarily long time for the
+// read loop to notice.
+const redisStreamBlock = 5 * time.Second
+
+// redisStreamClaimMinIdle is how long a delivery can sit unacknowledged in another
+// consumer's PEL before this one's reclaim loop steals and retries it, matching how an
+// AMQP consumer that dies mid-job leaves its unacked deliveries for redelivery.
+const redisStreamClaimMinIdle = 2 * time.Minute
+
+// RedisStreamConsumer pulls Jobs off a Redis Stream via a consumer group, dispatches them
+// through the same HTTP/browser worker pools Consumer (the AMQP backend) uses, and
+// publishes the resulting *extractor.ExtractedResult back to each job's ReplyTo stream.
+// It's the "redis" AppConfig.QueueBackend's counterpart to Consumer.
+type RedisStreamConsumer struct {
+	cfg    *config.AppConfig
+	client redis.UniversalClient
+
+	httpWorkerPool    *worker.WorkerPool
+	browserWorkerPool *worker.WorkerPool
+
+	consumerName string
+	stop         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewRedisStreamConsumer dials redisCfg (the same Redis deployment the cache manager
+// uses) and ensures cfg.QueueName's consumer group exists, creating both the stream and
+// the group if this is the first consumer to start.
+func NewRedisStreamConsumer(cfg *config.AppConfig, redisCfg cache.RedisConfig, httpWorkerPool, browserWorkerPool *worker.WorkerPool) (*RedisStreamConsumer, error) {
+	addrs := redisCfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{redisCfg.Addr}
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:      addrs,
+		MasterName: redisCfg.MasterName,
+		Username:   redisCfg.Username,
+		Password:   redisCfg.Password,
+		DB:         redisCfg.DB,
+	}
+	var client redis.UniversalClient
+	if redisCfg.ClusterMode && redisCfg.MasterName == "" {
+		client = redis.NewClusterClient(opts.Cluster())
+	} else {
+		client = redis.NewUniversalClient(opts)
+	}
+
+	err := client.XGroupCreateMkStream(context.Background(), cfg.QueueName, redisStreamGroup, "0").Err()
+	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+		client.Close()
+		return nil, fmt.Errorf("failed to create Redis Streams consumer group %q on %q: %w", redisStreamGroup, cfg.QueueName, err)
+	}
+
+	return &RedisStreamConsumer{
+		cfg:               cfg,
+		client:            client,
+		httpWorkerPool:    httpWorkerPool,
+		browserWorkerPool: browserWorkerPool,
+		consumerName:      uuid.New().String(),
+		stop:              make(chan struct{}),
+	}, nil
+}
+
+// Start begins reading cfg.QueueName in a background goroutine, up to cfg.QueuePrefetch
+// unacknowledged deliveries at once, plus a second goroutine that periodically reclaims
+// deliveries abandoned by a dead consumer. Both run until Stop is called.
+func (c *RedisStreamConsumer) Start() error {
+	c.wg.Add(2)
+	go c.readLoop()
+	go c.reclaimLoop()
+	slog.Info("Redis Streams broker consumer started", "stream", c.cfg.QueueName, "group", redisStreamGroup, "prefetch", c.cfg.QueuePrefetch)
+	return nil
+}
+
+// Stop signals both background goroutines to exit, waits for every in-flight delivery to
+// finish (so a shutdown can't drop a job mid-extraction), then closes the client.
+func (c *RedisStreamConsumer) Stop() error {
+	close(c.stop)
+	c.wg.Wait()
+	return c.client.Close()
+}
+
+func (c *RedisStreamConsumer) readLoop() {
+	defer c.wg.Done()
+	ctx := context.Background()
+	var jobsWG sync.WaitGroup
+	for {
+		select {
+		case <-c.stop:
+			jobsWG.Wait()
+			return
+		default:
+		}
+
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    redisStreamGroup,
+			Consumer: c.consumerName,
+			Streams:  []string{c.cfg.QueueName, ">"},
+			Count:    int64(c.cfg.QueuePrefetch),
+			Block:    redisStreamBlock,
+		}).Result()
+		if err != nil {
+			if err != redis.Nil {
+				slog.Warn("Redis Streams broker: XREADGROUP failed", "stream", c.cfg.QueueName, "error", err)
+			}
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				jobsWG.Add(1)
+				go func(msg redis.XMessage) {
+					defer jobsWG.Done()
+					c.handleMessage(msg)
+				}(msg)
+			}
+		}
+	}
+}
+
+// reclaimLoop periodically claims pending deliveries idle past redisStreamClaimMinIdle
+// (left behind by a consumer that crashed mid-job) and retries them, the Streams
+// equivalent of an AMQP nack-for-requeue firing automatically on consumer death.
+func (c *RedisStreamConsumer) reclaimLoop() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(redisStreamClaimMinIdle)
+	defer ticker.Stop()
+	ctx := context.Background()
+	for {
+		select {
+		case <-ticker.C:
+			start := "0-0"
+			for {
+				msgs, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+					Stream:   c.cfg.QueueName,
+					Group:    redisStreamGroup,
+					MinIdle:  redisStreamClaimMinIdle,
+					Start:    start,
+					Count:    int64(c.cfg.QueuePrefetch),
+					Consumer: c.consumerName,
+				}).Result()
+				if err != nil {
+					slog.Warn("Redis Streams broker: XAUTOCLAIM failed", "stream", c.cfg.QueueName, "error", err)
+					break
+				}
+				for _, msg := range msgs {
+					c.wg.Add(1)
+					go func(msg redis.XMessage) {
+						defer c.wg.Done()
+						c.handleMessage(msg)
+					}(msg)
+				}
+				if next == "0-0" || len(msgs) == 0 {
+					break
+				}
+				start = next
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// handleMessage dispatches one delivery's Job (stored under its "payload" field) through
+// the worker pool api.IsBrowserJob selects, publishes its result to ReplyTo, and only
+// XACKs on success so a transient failure's entry stays pending for reclaimLoop to retry.
+func (c *RedisStreamConsumer) handleMessage(msg redis.XMessage) {
+	payload, _ := msg.Values["payload"].(string)
+
+	var job Job
+	if err := json.Unmarshal([]byte(payload), &job); err != nil {
+		slog.Error("Redis Streams broker: malformed job payload, dropping", "id", msg.ID, "error", err)
+		c.ack(msg.ID)
+		return
+	}
+
+	pool := c.httpWorkerPool
+	if api.IsBrowserJob(job.URL, queueJobEndpoint) {
+		pool = c.browserWorkerPool
+	}
+
+	resultChan := make(chan *extractor.ExtractedResult, 1)
+	if err := pool.Enqueue(worker.Job{
+		URL:        job.URL,
+		Endpoint:   queueJobEndpoint,
+		MaxChars:   job.MaxChars,
+		ResultChan: resultChan,
+		Context:    context.Background(),
+	}); err != nil {
+		slog.Error("Redis Streams broker: failed to enqueue job, leaving pending for retry", "id", msg.ID, "url", job.URL, "error", err)
+		return
+	}
+	result := <-resultChan
+	defer func() {
+		result.Reset()
+		extractor.ExtractedResultPool.Put(result)
+	}()
+
+	if err := c.publishResult(job, result); err != nil {
+		slog.Error("Redis Streams broker: failed to publish result", "url", job.URL, "reply_to", job.ReplyTo, "error", err)
+	}
+
+	if result.Error != "" && !isPermanentFailure(result) {
+		// Transient failure: leave unacked so reclaimLoop hands it to another attempt.
+		return
+	}
+	c.ack(msg.ID)
+}
+
+func (c *RedisStreamConsumer) ack(id string) {
+	if err := c.client.XAck(context.Background(), c.cfg.QueueName, redisStreamGroup, id).Err(); err != nil {
+		slog.Warn("Redis Streams broker: failed to XACK job", "id", id, "error", err)
+	}
+}
+
+// publishResult publishes result to job.ReplyTo as a Redis Stream entry carrying
+// job.CorrelationID so the producer can match it back to its request. A job with no
+// ReplyTo is fire-and-forget.
+func (c *RedisStreamConsumer) publishResult(job Job, result *extractor.ExtractedResult) error {
+	if job.ReplyTo == "" {
+		return nil
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return c.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: job.ReplyTo,
+		Values: map[string]interface{}{
+			"payload":        string(body),
+			"correlation_id": job.CorrelationID,
+		},
+	}).Err()
+}
@@ -1,20 +1,27 @@
 package browser
 
 import (
+	"fmt"
 	"log/slog"
 	"sync"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+
+	"web-search-api-for-llms/internal/cookies"
 )
 
 // Pool manages a pool of browser instances.
 type Pool struct {
 	launcher    *launcher.Launcher
+	launcherURL string         // <-- Remembered so Resize can spin up more browsers against it
 	allBrowsers []*rod.Browser // <-- Track all created browsers
 	activePool  chan *rod.Browser
 	mu          sync.Mutex
 	isClosed    bool // <-- Add a closed flag
+	cookies     []*proto.NetworkCookieParam
+	targetSize  int // desired pool size; Return() closes excess browsers down to this
 }
 
 // NewPool creates and initializes a new browser pool.
@@ -24,8 +31,10 @@ func NewPool(size int) (*Pool, error) {
 
 	pool := &Pool{
 		launcher:    launcherInstance,
+		launcherURL: launcherURL,
 		allBrowsers: make([]*rod.Browser, 0, size), // <-- Initialize
 		activePool:  make(chan *rod.Browser, size),
+		targetSize:  size,
 	}
 
 	for i := 0; i < size; i++ {
@@ -38,12 +47,48 @@ func NewPool(size int) (*Pool, error) {
 	return pool, nil
 }
 
-// Get retrieves a browser from the pool.
+// Get retrieves a browser from the pool, applying any cookies set via SetCookies
+// so new pages start with an authenticated session.
 func (p *Pool) Get() *rod.Browser {
-	return <-p.activePool
+	b := <-p.activePool
+
+	p.mu.Lock()
+	cks := p.cookies
+	p.mu.Unlock()
+	if len(cks) > 0 {
+		if err := b.SetCookies(cks); err != nil {
+			slog.Warn("Failed to install cookies on browser", "error", err)
+		}
+	}
+
+	return b
+}
+
+// SetCookies imports cookies read from a local browser profile (see internal/cookies)
+// so that every browser checked out of the pool afterward carries the same session.
+func (p *Pool) SetCookies(imported []cookies.Cookie) {
+	params := make([]*proto.NetworkCookieParam, 0, len(imported))
+	for _, c := range imported {
+		params = append(params, &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			Expires:  proto.TimeSinceEpoch(c.Expires.Unix()),
+		})
+	}
+
+	p.mu.Lock()
+	p.cookies = params
+	p.mu.Unlock()
 }
 
-// Return gives a browser back to the pool.
+// Return gives a browser back to the pool. If a Resize has shrunk the pool since this
+// browser was checked out, it's closed and dropped here instead of being returned, so a
+// resize-down takes effect without interrupting whatever job the browser was in the
+// middle of.
 func (p *Pool) Return(browser *rod.Browser) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -55,9 +100,104 @@ func (p *Pool) Return(browser *rod.Browser) {
 		return
 	}
 
+	if len(p.allBrowsers) > p.targetSize {
+		browser.MustClose()
+		p.removeBrowserLocked(browser)
+		return
+	}
+
 	p.activePool <- browser
 }
 
+// removeBrowserLocked drops browser from allBrowsers. Callers must hold p.mu.
+func (p *Pool) removeBrowserLocked(browser *rod.Browser) {
+	for i, b := range p.allBrowsers {
+		if b == browser {
+			p.allBrowsers = append(p.allBrowsers[:i], p.allBrowsers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Resize grows or shrinks the pool to n browsers, reusing the existing launcher URL for
+// any new browsers. Growing connects the extra browsers immediately. Shrinking closes
+// idle browsers right away; browsers currently checked out are closed as they're
+// Returned instead of being killed mid-job.
+func (p *Pool) Resize(n int) error {
+	if n < 1 {
+		n = 1
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed {
+		return fmt.Errorf("browser pool is closed")
+	}
+
+	current := len(p.allBrowsers)
+	p.targetSize = n
+
+	if n > current {
+		// The activePool channel's buffer is fixed at creation size, so growing beyond it
+		// means swapping in a bigger channel, carrying over whatever's currently idle.
+		if n > cap(p.activePool) {
+			grown := make(chan *rod.Browser, n)
+		drainIdle:
+			for {
+				select {
+				case b := <-p.activePool:
+					grown <- b
+				default:
+					break drainIdle
+				}
+			}
+			p.activePool = grown
+		}
+		for i := current; i < n; i++ {
+			b := rod.New().ControlURL(p.launcherURL).MustConnect()
+			p.allBrowsers = append(p.allBrowsers, b)
+			p.activePool <- b
+		}
+		slog.Info("Browser pool resized up", "from", current, "to", n)
+		return nil
+	}
+
+	closed := 0
+	for closed < current-n {
+		select {
+		case b := <-p.activePool:
+			b.MustClose()
+			p.removeBrowserLocked(b)
+			closed++
+		default:
+			// No more idle browsers to close now; the rest close as Return sees them.
+			slog.Info("Browser pool resize down pending", "idle_closed", closed, "still_checked_out", current-n-closed)
+			return nil
+		}
+	}
+	slog.Info("Browser pool resized down", "from", current, "to", n)
+	return nil
+}
+
+// Status reports the pool's target size, how many browsers exist, and how many are idle.
+type Status struct {
+	TargetSize int `json:"target_size"`
+	Total      int `json:"total"`
+	Idle       int `json:"idle"`
+}
+
+// Status returns the pool's current runtime state for the admin API.
+func (p *Pool) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Status{
+		TargetSize: p.targetSize,
+		Total:      len(p.allBrowsers),
+		Idle:       len(p.activePool),
+	}
+}
+
 // Cleanup closes all browsers in the pool.
 func (p *Pool) Cleanup() {
 	p.mu.Lock()
@@ -92,7 +232,7 @@ func NewLauncher() *launcher.Launcher {
 		Set("--disable-plugins-discovery"). // Changed from --disable-plugins
 		Set("--disable-images").
 		Set("--disable-background-networking").
-        // ---- ADD THESE ----
+		// ---- ADD THESE ----
 		Set("--disable-background-timer-throttling").
 		Set("--disable-backgrounding-occluded-windows").
 		Set("--disable-breakpad").
@@ -117,5 +257,5 @@ func NewLauncher() *launcher.Launcher {
 		Set("--enable-automation").
 		Set("--password-store", "basic").
 		Set("--use-mock-keychain")
-        // Note: --disable-javascript-harmony-shipping is deprecated
-}
\ No newline at end of file
+	// Note: --disable-javascript-harmony-shipping is deprecated
+}
@@ -14,4 +14,13 @@ type Cache interface {
 	// Add this new method for batched lookups
 	MGetExtractedResults(ctx context.Context, keys []string) (map[string]*extractor.ExtractedResult, error)
 	MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error
-}
\ No newline at end of file
+	// InvalidatePrefix deletes every entry whose key starts with prefix, for the admin
+	// "invalidate a whole cache group" endpoint (see CacheManager.InvalidateNamespace).
+	InvalidatePrefix(ctx context.Context, prefix string) error
+	// InvalidateKey deletes a single entry, for the admin "purge one URL" endpoint (see
+	// CacheManager.InvalidateKey). On a backend shared across replicas (RedisCache,
+	// RueidisCache), this also propagates the purge to every other replica's local tier
+	// (see TieredCache), so an operator purging a URL doesn't have to wait out that
+	// tier's TTL on each replica individually.
+	InvalidateKey(ctx context.Context, key string) error
+}
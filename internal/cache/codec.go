@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"fmt"
+
+	"web-search-api-for-llms/internal/config"
+)
+
+// Codec converts a value (an *extractor.ExtractedResult or a []string of search URLs)
+// to and from bytes for a backend that serializes over the wire, like RedisCache.
+// RueidisCache and the in-process backends don't need this: they either hand the value
+// straight to Redis's own JSON-over-RESP encoding (today, via go-redis) or never leave
+// the process at all.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	// ContentType names the codec for logging, e.g. "application/json".
+	ContentType() string
+}
+
+// cacheValueVersion is stored in bits 4-6 of every codecTag byte RedisCache prefixes a
+// value with; it's separate from the codec id so a future incompatible change to one
+// codec's encoding (not a new codec) can still be detected on read.
+const cacheValueVersion byte = 1
+
+// compressedBit, set in bit 7 of codecTag, means the bytes following the tag were run
+// through RedisCache's configured compressionCodec before being stored (see
+// compression.go). Clear for every value written before compression existed, so old
+// entries keep decoding as plain codec output.
+const compressedBit byte = 0x80
+
+// codecTag is the one-byte prefix RedisCache.Set/MSet store before a value's (optionally
+// compressed) encoded bytes, identifying cacheValueVersion, which Codec wrote it, and
+// whether compressedBit applies. Storing it per-value (rather than trusting whatever
+// codec/compression the namespace is configured with today) means an operator can flip
+// REDIS_CODEC or REDIS_COMPRESSION and have old entries keep decoding correctly until
+// they age out, instead of every pre-existing key becoming a silent unmarshal failure.
+type codecTag byte
+
+const (
+	codecTagJSON codecTag = codecTag(cacheValueVersion)<<4 | 1
+	codecTagGob  codecTag = codecTag(cacheValueVersion)<<4 | 2
+	codecTagCBOR codecTag = codecTag(cacheValueVersion)<<4 | 3
+)
+
+// compressed returns a copy of t with compressedBit set, used when a value's marshaled
+// size clears RedisConfig.CompressionMinBytes.
+func (t codecTag) compressed() codecTag { return t | codecTag(compressedBit) }
+
+// isCompressed reports whether compressedBit is set.
+func (t codecTag) isCompressed() bool { return byte(t)&compressedBit != 0 }
+
+// codecForName resolves a config.CacheCodec to the Codec RedisCache should encode new
+// writes with, and the (uncompressed) tag byte that identifies it on the wire.
+func codecForName(name config.CacheCodec) (Codec, codecTag, error) {
+	switch name {
+	case config.CacheCodecGob:
+		return gobCodec{}, codecTagGob, nil
+	case config.CacheCodecCBOR:
+		return cborCodec{}, codecTagCBOR, nil
+	case config.CacheCodecJSON, "":
+		return jsonCodec{}, codecTagJSON, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported cache codec %q", name)
+	}
+}
+
+// codecForTag resolves the codec identified by tag's low nibble, ignoring
+// compressedBit, back to the Codec that can decode it, regardless of which codec the
+// namespace is configured with now.
+func codecForTag(tag codecTag) (Codec, error) {
+	switch tag &^ codecTag(compressedBit) {
+	case codecTagJSON:
+		return jsonCodec{}, nil
+	case codecTagGob:
+		return gobCodec{}, nil
+	case codecTagCBOR:
+		return cborCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized cache value tag %#x", tag)
+	}
+}
+
+// encodeTagged marshals v with codec, compresses it with compression when the
+// marshaled size exceeds minBytes, and prefixes the result with a tag identifying both,
+// ready to store.
+func encodeTagged(codec Codec, tag codecTag, compression compressionCodec, minBytes int64, v interface{}) ([]byte, error) {
+	encoded, err := codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := encoded
+	if compression != nil && int64(len(encoded)) > minBytes {
+		compressed, err := compression.Compress(encoded)
+		if err != nil {
+			return nil, err
+		}
+		payload = compressed
+		tag = tag.compressed()
+	}
+
+	tagged := make([]byte, 1+len(payload))
+	tagged[0] = byte(tag)
+	copy(tagged[1:], payload)
+	return tagged, nil
+}
+
+// decodeTagged reads the codecTag off the front of tagged, decompresses the rest if
+// compressedBit is set, then unmarshals it into v with whichever Codec wrote it.
+func decodeTagged(tagged []byte, v interface{}) error {
+	if len(tagged) == 0 {
+		return fmt.Errorf("empty cache value")
+	}
+	tag := codecTag(tagged[0])
+	codec, err := codecForTag(tag)
+	if err != nil {
+		return err
+	}
+
+	payload := tagged[1:]
+	if tag.isCompressed() {
+		decompressed, err := decompressAny(payload)
+		if err != nil {
+			return fmt.Errorf("decompress cache value: %w", err)
+		}
+		payload = decompressed
+	}
+	return codec.Unmarshal(payload, v)
+}
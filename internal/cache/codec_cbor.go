@@ -0,0 +1,14 @@
+package cache
+
+import "github.com/fxamacker/cbor/v2"
+
+// cborCodec encodes with RFC 8949 CBOR: a binary format that shrinks large
+// HTML/markdown bodies similarly to gob while, unlike gob, still being a standard format
+// other languages' tooling can decode.
+type cborCodec struct{}
+
+func (cborCodec) Marshal(v interface{}) ([]byte, error) { return cbor.Marshal(v) }
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error { return cbor.Unmarshal(data, v) }
+
+func (cborCodec) ContentType() string { return "application/cbor" }
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"web-search-api-for-llms/internal/extractor"
+)
+
+func init() {
+	// ExtractedResult.Data is an interface{} holding one of these concrete types
+	// depending on SourceType (see dispatcher.go's extractor registry); gob needs each
+	// one registered before it can encode or decode a value behind an interface. The
+	// generic map/slice entries cover YouTubeData.Comments, whose elements come straight
+	// off a JSON decode rather than a typed struct.
+	gob.Register(extractor.YouTubeData{})
+	gob.Register(extractor.YouTubePlaylistData{})
+	gob.Register(extractor.RedditData{})
+	gob.Register(extractor.PDFData{})
+	gob.Register(extractor.WebpageData{})
+	gob.Register(&extractor.TwitterData{})
+	gob.Register(&extractor.TwitterSearchResult{})
+	gob.Register(&extractor.TwitterProfileResult{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// gobCodec encodes with encoding/gob: smaller and cheaper to decode than JSON for
+// Go-shaped data, at the cost of being readable only by this program.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (gobCodec) ContentType() string { return "application/x-gob" }
@@ -0,0 +1,10 @@
+package cache
+
+// jsonCodec is the original jsoniter-based Codec, and RedisCache's default.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+func (jsonCodec) ContentType() string { return "application/json" }
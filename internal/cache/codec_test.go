@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+type codecTestValue struct {
+	URL   string
+	Title string
+	Tags  []string
+}
+
+func TestEncodeDecodeTaggedRoundTrip(t *testing.T) {
+	in := codecTestValue{URL: "https://example.com", Title: "Example", Tags: []string{"a", "b"}}
+
+	tests := []struct {
+		name        string
+		codec       Codec
+		tag         codecTag
+		compression compressionCodec
+		minBytes    int64
+	}{
+		{name: "json, uncompressed", codec: jsonCodec{}, tag: codecTagJSON, compression: nil, minBytes: 0},
+		{name: "gob, uncompressed", codec: gobCodec{}, tag: codecTagGob, compression: nil, minBytes: 0},
+		{name: "cbor, uncompressed", codec: cborCodec{}, tag: codecTagCBOR, compression: nil, minBytes: 0},
+		{name: "json, zstd-compressed", codec: jsonCodec{}, tag: codecTagJSON, compression: zstdCodec{}, minBytes: 0},
+		{name: "json, snappy-compressed", codec: jsonCodec{}, tag: codecTagJSON, compression: snappyCodec{}, minBytes: 0},
+		{name: "gob, zstd-compressed", codec: gobCodec{}, tag: codecTagGob, compression: zstdCodec{}, minBytes: 0},
+		{name: "cbor, snappy-compressed", codec: cborCodec{}, tag: codecTagCBOR, compression: snappyCodec{}, minBytes: 0},
+		{name: "below minBytes stays uncompressed", codec: jsonCodec{}, tag: codecTagJSON, compression: zstdCodec{}, minBytes: 1 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagged, err := encodeTagged(tt.codec, tt.tag, tt.compression, tt.minBytes, in)
+			if err != nil {
+				t.Fatalf("encodeTagged() error = %v", err)
+			}
+
+			wantCompressed := tt.compression != nil && tt.minBytes == 0
+			gotTag := codecTag(tagged[0])
+			if gotTag.isCompressed() != wantCompressed {
+				t.Errorf("tag.isCompressed() = %v, want %v", gotTag.isCompressed(), wantCompressed)
+			}
+
+			var out codecTestValue
+			if err := decodeTagged(tagged, &out); err != nil {
+				t.Fatalf("decodeTagged() error = %v", err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("round-trip mismatch: got %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestDecodeTaggedEmpty(t *testing.T) {
+	var out codecTestValue
+	if err := decodeTagged(nil, &out); err == nil {
+		t.Error("decodeTagged(nil) error = nil, want an error")
+	}
+}
+
+func TestDecodeTaggedUnrecognizedTag(t *testing.T) {
+	var out codecTestValue
+	tagged := []byte{0xff, 1, 2, 3}
+	if err := decodeTagged(tagged, &out); err == nil {
+		t.Error("decodeTagged() with an unrecognized tag error = nil, want an error")
+	}
+}
+
+func TestCodecForNameAndTag(t *testing.T) {
+	codec, tag, err := codecForName("")
+	if err != nil {
+		t.Fatalf("codecForName(\"\") error = %v", err)
+	}
+	if _, ok := codec.(jsonCodec); !ok {
+		t.Errorf("codecForName(\"\") codec = %T, want jsonCodec", codec)
+	}
+	if tag != codecTagJSON {
+		t.Errorf("codecForName(\"\") tag = %v, want codecTagJSON", tag)
+	}
+
+	if _, _, err := codecForName("bogus"); err == nil {
+		t.Error("codecForName(\"bogus\") error = nil, want an error")
+	}
+
+	if _, err := codecForTag(codecTag(0xff)); err == nil {
+		t.Error("codecForTag(0xff) error = nil, want an error")
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+
+	"web-search-api-for-llms/internal/config"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressionCodec compresses the already-codec-marshaled bytes RedisCache is about to
+// store. Unlike Codec, there's no per-algorithm tag byte for which compressionCodec
+// wrote a value: encodeTagged/decodeTagged only need a single compressedBit, and
+// decompressAny tries each known algorithm's magic bytes in turn (see below), so adding
+// a new algorithm here never needs a wire-format change.
+type compressionCodec interface {
+	Compress(data []byte) ([]byte, error)
+}
+
+// compressionForName resolves a config.CacheCompression to the compressionCodec
+// RedisCache should compress new writes with above its configured size threshold. Nil,
+// nil means "never compress" (CacheCompressionNone).
+func compressionForName(name config.CacheCompression) (compressionCodec, error) {
+	switch name {
+	case config.CacheCompressionZstd:
+		return zstdCodec{}, nil
+	case config.CacheCompressionSnappy:
+		return snappyCodec{}, nil
+	case config.CacheCompressionNone, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported cache compression %q", name)
+	}
+}
+
+// zstdMagic is the 4-byte frame magic every zstd.Encoder output starts with (RFC 8878
+// section 3.1.1); decompressAny uses it to tell a zstd frame apart from snappy's, which
+// has no fixed magic of its own.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// zstdCodec compresses with github.com/klauspost/compress/zstd, favoring ratio over CPU
+// cost; good for large, infrequently-rewritten bodies like rendered HTML or transcripts.
+type zstdCodec struct{}
+
+// zstdEncoderPool reuses zstd.Encoders: constructing one allocates its match-finder
+// tables, which would otherwise happen on every RedisCache.Set.
+var zstdEncoderPool = sync.Pool{
+	New: func() interface{} {
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		if err != nil {
+			// zstd.NewWriter(nil, ...) with a nil writer only fails on an invalid
+			// option, which WithEncoderLevel(zstd.SpeedDefault) never is.
+			panic(err)
+		}
+		return enc
+	},
+}
+
+func (zstdCodec) Compress(data []byte) ([]byte, error) {
+	enc := zstdEncoderPool.Get().(*zstd.Encoder)
+	defer zstdEncoderPool.Put(enc)
+	return enc.EncodeAll(data, make([]byte, 0, len(data))), nil
+}
+
+func zstdDecompress(data []byte) ([]byte, error) {
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	return dec.DecodeAll(data, nil)
+}
+
+// snappyCodec compresses with github.com/golang/snappy, favoring low CPU cost over
+// ratio; good for namespaces with high write volume.
+type snappyCodec struct{}
+
+func (snappyCodec) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+// decompressAny decompresses data written by either zstdCodec or snappyCodec, telling
+// them apart by zstd's frame magic since neither the stored tag byte nor snappy's own
+// format identifies which algorithm produced it.
+func decompressAny(data []byte) ([]byte, error) {
+	if len(data) >= len(zstdMagic) && string(data[:len(zstdMagic)]) == string(zstdMagic) {
+		return zstdDecompress(data)
+	}
+	return snappy.Decode(nil, data)
+}
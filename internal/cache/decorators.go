@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"web-search-api-for-llms/internal/extractor"
+)
+
+// noopCache backs a namespace whose config.CacheNamespaceConfig.Enabled is false: every
+// Get is a miss and every Set/MSet is dropped, so a disabled namespace behaves exactly
+// like an always-cold cache instead of requiring every caller to special-case it.
+type noopCache struct{}
+
+func (noopCache) GetExtractedResult(ctx context.Context, key string) (*extractor.ExtractedResult, bool) {
+	return nil, false
+}
+
+func (noopCache) GetSearchURLs(ctx context.Context, key string) ([]string, bool) {
+	return nil, false
+}
+
+func (noopCache) Set(ctx context.Context, key string, value interface{}, duration time.Duration) {}
+
+func (noopCache) MGetExtractedResults(ctx context.Context, keys []string) (map[string]*extractor.ExtractedResult, error) {
+	return map[string]*extractor.ExtractedResult{}, nil
+}
+
+func (noopCache) MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error {
+	return nil
+}
+
+func (noopCache) InvalidatePrefix(ctx context.Context, prefix string) error { return nil }
+
+func (noopCache) InvalidateKey(ctx context.Context, key string) error { return nil }
+
+// sizeLimitedCache wraps a Cache and drops any Set/MSet whose JSON-encoded value exceeds
+// maxEntryBytes, so one oversized page (e.g. a very long YouTube transcript) can't crowd
+// out the rest of its namespace. Reads and invalidation pass through unchanged.
+type sizeLimitedCache struct {
+	Cache
+	maxEntryBytes int64
+}
+
+func (c *sizeLimitedCache) Set(ctx context.Context, key string, value interface{}, duration time.Duration) {
+	if !c.fitsLimit(key, value) {
+		return
+	}
+	c.Cache.Set(ctx, key, value, duration)
+}
+
+func (c *sizeLimitedCache) MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error {
+	filtered := make(map[string]interface{}, len(items))
+	for key, value := range items {
+		if c.fitsLimit(key, value) {
+			filtered[key] = value
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return c.Cache.MSet(ctx, filtered, duration)
+}
+
+func (c *sizeLimitedCache) fitsLimit(key string, value interface{}) bool {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return true // let the underlying backend's own marshal call surface the error
+	}
+	if int64(len(raw)) > c.maxEntryBytes {
+		slog.Debug("sizeLimitedCache: dropping entry over the configured max size", "key", key, "bytes", len(raw), "max_bytes", c.maxEntryBytes)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,372 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"web-search-api-for-llms/internal/extractor"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// FileCache is a disk-backed Cache that survives process restarts, for entries (e.g.
+// JS-rendered pages) expensive enough that losing them on redeploy isn't acceptable.
+// Each entry is stored gzip-compressed as <dir>/<sha1(key)[:2]>/<sha1(key)>.json.gz,
+// alongside a ".meta" sidecar recording the write time so Get can enforce maxAge without
+// relying on filesystem mtimes (which backup/rsync tooling can rewrite).
+type FileCache struct {
+	dir          string
+	maxAge       time.Duration // <= 0 means forever
+	maxSizeBytes int64         // 0 means unbounded
+	stopSweep    chan struct{}
+}
+
+// fileCacheSweepInterval is how often the sweep loop runs when only maxSizeBytes (and
+// not maxAge) bounds a FileCache, since there's no maxAge to derive a cadence from.
+const fileCacheSweepInterval = 10 * time.Minute
+
+// fileCacheEntry is the on-disk envelope around a cached value, tagged with its Go type
+// so GetExtractedResult/GetSearchURLs can refuse to decode a value written for the other.
+type fileCacheEntry struct {
+	Kind  string              `json:"kind"`
+	Value jsoniter.RawMessage `json:"value"`
+}
+
+const (
+	fileCacheKindExtractedResult = "extracted_result"
+	fileCacheKindSearchURLs      = "search_urls"
+)
+
+// NewFileCache creates dir (if needed), starts its eviction sweep when maxAge bounds
+// entries, and returns a FileCache rooted at dir. maxAge <= 0 means entries never expire.
+func NewFileCache(dir string, maxAge time.Duration, maxSizeBytes int64) (*FileCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("file cache requires a non-empty dir")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create file cache directory %s: %w", dir, err)
+	}
+	c := &FileCache{
+		dir:          dir,
+		maxAge:       maxAge,
+		maxSizeBytes: maxSizeBytes,
+		stopSweep:    make(chan struct{}),
+	}
+	if maxAge > 0 || maxSizeBytes > 0 {
+		go c.sweepLoop()
+	}
+	return c, nil
+}
+
+// Close stops the eviction sweep goroutine. Safe to call even if maxAge <= 0 never
+// started one.
+func (c *FileCache) Close() {
+	select {
+	case <-c.stopSweep:
+	default:
+		close(c.stopSweep)
+	}
+}
+
+func (c *FileCache) paths(key string) (dataPath, metaPath string) {
+	sum := sha1.Sum([]byte(key))
+	hexSum := hex.EncodeToString(sum[:])
+	shard := filepath.Join(c.dir, hexSum[:2])
+	return filepath.Join(shard, hexSum+".json.gz"), filepath.Join(shard, hexSum+".meta")
+}
+
+// expired reports whether the entry whose sidecar lives at metaPath has outlived
+// c.maxAge, treating a missing or unreadable sidecar as expired so a partial write
+// can't wedge an entry in the cache forever.
+func (c *FileCache) expired(metaPath string) bool {
+	if c.maxAge <= 0 {
+		return false
+	}
+	raw, err := os.ReadFile(metaPath)
+	if err != nil {
+		return true
+	}
+	writtenAt, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return true
+	}
+	return time.Since(writtenAt) > c.maxAge
+}
+
+func (c *FileCache) read(key string) (*fileCacheEntry, bool) {
+	dataPath, metaPath := c.paths(key)
+	if c.expired(metaPath) {
+		return nil, false
+	}
+	f, err := os.Open(dataPath)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, false
+	}
+	defer gz.Close()
+	var entry fileCacheEntry
+	if err := json.NewDecoder(gz).Decode(&entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (c *FileCache) write(key string, entry *fileCacheEntry) {
+	dataPath, metaPath := c.paths(key)
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		slog.Warn("FileCache: failed to create shard directory", "path", filepath.Dir(dataPath), "error", err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	if err := json.NewEncoder(gz).Encode(entry); err != nil {
+		slog.Warn("FileCache: failed to encode entry", "key", key, "error", err)
+		return
+	}
+	if err := gz.Close(); err != nil {
+		slog.Warn("FileCache: failed to flush gzip writer", "key", key, "error", err)
+		return
+	}
+	if err := os.WriteFile(dataPath, buf.Bytes(), 0644); err != nil {
+		slog.Warn("FileCache: failed to write entry", "path", dataPath, "error", err)
+		return
+	}
+	if err := os.WriteFile(metaPath, []byte(time.Now().UTC().Format(time.RFC3339Nano)), 0644); err != nil {
+		slog.Warn("FileCache: failed to write meta sidecar", "path", metaPath, "error", err)
+	}
+}
+
+// GetExtractedResult retrieves an ExtractedResult previously Set under key.
+func (c *FileCache) GetExtractedResult(ctx context.Context, key string) (*extractor.ExtractedResult, bool) {
+	entry, ok := c.read(key)
+	if !ok || entry.Kind != fileCacheKindExtractedResult {
+		return nil, false
+	}
+	var result extractor.ExtractedResult
+	if err := json.Unmarshal(entry.Value, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// GetSearchURLs retrieves a []string previously Set under key.
+func (c *FileCache) GetSearchURLs(ctx context.Context, key string) ([]string, bool) {
+	entry, ok := c.read(key)
+	if !ok || entry.Kind != fileCacheKindSearchURLs {
+		return nil, false
+	}
+	var urls []string
+	if err := json.Unmarshal(entry.Value, &urls); err != nil {
+		return nil, false
+	}
+	return urls, true
+}
+
+// Set writes value to disk under key, gzip-compressed, alongside a meta sidecar
+// recording the write time. duration is accepted to satisfy the Cache interface but
+// ignored: a FileCache namespace's lifetime is governed by its configured maxAge
+// (AppConfig.Caches[name].MaxAge) uniformly for every entry.
+func (c *FileCache) Set(ctx context.Context, key string, value interface{}, duration time.Duration) {
+	entry, err := newFileCacheEntry(value)
+	if err != nil {
+		slog.Warn("FileCache: unsupported value type, not caching", "key", key, "error", err)
+		return
+	}
+	c.write(key, entry)
+}
+
+func newFileCacheEntry(value interface{}) (*fileCacheEntry, error) {
+	var kind string
+	switch value.(type) {
+	case *extractor.ExtractedResult:
+		kind = fileCacheKindExtractedResult
+	case []string:
+		kind = fileCacheKindSearchURLs
+	default:
+		return nil, fmt.Errorf("unsupported cache value type %T", value)
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCacheEntry{Kind: kind, Value: raw}, nil
+}
+
+// MGetExtractedResults looks up keys sequentially; a FileCache has no batched read path
+// analogous to Redis's pipeline, but satisfying the Cache interface lets it sit behind a
+// CacheManager namespace the same as MemoryCache/RedisCache.
+func (c *FileCache) MGetExtractedResults(ctx context.Context, keys []string) (map[string]*extractor.ExtractedResult, error) {
+	results := make(map[string]*extractor.ExtractedResult)
+	for _, key := range keys {
+		if result, found := c.GetExtractedResult(ctx, key); found {
+			results[key] = result
+		}
+	}
+	return results, nil
+}
+
+// MSet writes every item sequentially; see MGetExtractedResults.
+func (c *FileCache) MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error {
+	for key, value := range items {
+		c.Set(ctx, key, value, duration)
+	}
+	return nil
+}
+
+// sweepLoop periodically removes entries that have outlived maxAge (sweep) and, once
+// maxSizeBytes bounds this namespace, evicts the least-recently-written entries past
+// that budget (evictBySize), so a FileCache namespace doesn't grow unbounded across
+// restarts either in age or in total disk size.
+func (c *FileCache) sweepLoop() {
+	interval := fileCacheSweepInterval
+	if c.maxAge > 0 && c.maxAge/2 < interval {
+		interval = c.maxAge / 2
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.sweep()
+			c.evictBySize()
+		case <-c.stopSweep:
+			return
+		}
+	}
+}
+
+func (c *FileCache) sweep() {
+	if c.maxAge <= 0 {
+		return
+	}
+	removed := 0
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".meta" {
+			return nil
+		}
+		if c.expired(path) {
+			dataPath := path[:len(path)-len(".meta")] + ".json.gz"
+			_ = os.Remove(dataPath)
+			_ = os.Remove(path)
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("FileCache: sweep failed", "dir", c.dir, "error", err)
+		return
+	}
+	if removed > 0 {
+		slog.Info("FileCache: evicted expired entries", "dir", c.dir, "count", removed)
+	}
+}
+
+// fileCacheEntryStat describes one entry found by evictBySize: its data file's path and
+// size, plus the write time its meta sidecar recorded (falling back to the data file's
+// mtime if the sidecar is missing or unreadable) so eviction order approximates LRU
+// without needing a separate access-time index.
+type fileCacheEntryStat struct {
+	dataPath  string
+	metaPath  string
+	size      int64
+	writtenAt time.Time
+}
+
+// evictBySize walks dir and removes the oldest-written entries (by their meta sidecar's
+// write time) until the namespace's total size is back under maxSizeBytes. A no-op when
+// maxSizeBytes <= 0.
+func (c *FileCache) evictBySize() {
+	if c.maxSizeBytes <= 0 {
+		return
+	}
+
+	var entries []fileCacheEntryStat
+	var total int64
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json.gz" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		metaPath := path[:len(path)-len(".json.gz")] + ".meta"
+		writtenAt := info.ModTime()
+		if raw, err := os.ReadFile(metaPath); err == nil {
+			if t, err := time.Parse(time.RFC3339Nano, string(raw)); err == nil {
+				writtenAt = t
+			}
+		}
+		entries = append(entries, fileCacheEntryStat{dataPath: path, metaPath: metaPath, size: info.Size(), writtenAt: writtenAt})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		slog.Warn("FileCache: size eviction walk failed", "dir", c.dir, "error", err)
+		return
+	}
+	if total <= c.maxSizeBytes {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].writtenAt.Before(entries[j].writtenAt) })
+
+	removed := 0
+	for _, entry := range entries {
+		if total <= c.maxSizeBytes {
+			break
+		}
+		_ = os.Remove(entry.dataPath)
+		_ = os.Remove(entry.metaPath)
+		total -= entry.size
+		removed++
+	}
+	if removed > 0 {
+		slog.Info("FileCache: evicted oldest entries over size budget", "dir", c.dir, "count", removed, "max_size_bytes", c.maxSizeBytes)
+	}
+}
+
+// InvalidatePrefix removes every entry under c.dir. prefix is ignored: keys are sharded
+// onto disk by sha1(key), not stored alongside the original key, so a FileCache can't
+// filter by prefix directly. This is safe because NewCacheManager gives every namespace
+// its own FileCache instance (its own Dir), so wiping c.dir only ever invalidates the one
+// namespace this FileCache backs.
+func (c *FileCache) InvalidatePrefix(ctx context.Context, prefix string) error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list file cache dir %s: %w", c.dir, err)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// InvalidateKey removes key's data and sidecar files, if present.
+func (c *FileCache) InvalidateKey(ctx context.Context, key string) error {
+	dataPath, metaPath := c.paths(key)
+	if err := os.Remove(dataPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", dataPath, err)
+	}
+	if err := os.Remove(metaPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", metaPath, err)
+	}
+	return nil
+}
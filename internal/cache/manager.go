@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"web-search-api-for-llms/internal/config"
+)
+
+// Namespace wraps one named Cache with a key builder scoped to that name, so two
+// namespaces hashing overlapping identifiers (e.g. a "pdf" and a "readability" cache
+// both keyed by URL) never collide on the same underlying backend.
+type Namespace struct {
+	Cache
+	name string
+}
+
+// Key joins parts into a cache key scoped to n's namespace, e.g.
+// caches.Namespace("pdf").Key(url, "4000") -> "pdf:https://example.com:4000".
+func (n *Namespace) Key(parts ...string) string {
+	key := n.name
+	for _, p := range parts {
+		key += ":" + p
+	}
+	return key
+}
+
+// CacheManager resolves a logical namespace (search, youtube, reddit, twitter, pdf,
+// browser_html, ...) to the concrete Cache backing it, so operators can keep expensive
+// captures on disk across restarts while short-lived results stay in memory, all under
+// one SearchHandler.Caches dependency.
+type CacheManager struct {
+	mu         sync.Mutex
+	namespaces map[string]*Namespace
+}
+
+// NewCacheManager builds a Cache for every namespace in cfg according to its Backend,
+// routing every "redis" namespace through the same redisCfg connection (standalone,
+// Sentinel, or Cluster per redisCfg's fields). An unsupported backend fails fast so
+// misconfiguration is caught at startup rather than on the first request. A namespace
+// with Enabled == false still gets a Namespace (so callers don't need a nil check) but
+// it's backed by a noopCache, and a namespace with MaxEntryBytes > 0 has its writes
+// capped by a sizeLimitedCache.
+func NewCacheManager(cfg map[string]config.CacheNamespaceConfig, redisCfg RedisConfig) (*CacheManager, error) {
+	m := &CacheManager{
+		namespaces: make(map[string]*Namespace, len(cfg)),
+	}
+	for name, nsCfg := range cfg {
+		c, err := newNamespaceCache(name, nsCfg, redisCfg)
+		if err != nil {
+			return nil, fmt.Errorf("cache namespace %q: %w", name, err)
+		}
+		m.namespaces[name] = &Namespace{Cache: c, name: name}
+	}
+	return m, nil
+}
+
+func newNamespaceCache(name string, nsCfg config.CacheNamespaceConfig, redisCfg RedisConfig) (Cache, error) {
+	if !nsCfg.Enabled {
+		return noopCache{}, nil
+	}
+
+	var c Cache
+	var err error
+	switch nsCfg.Backend {
+	case config.CacheBackendRedis:
+		c, err = NewRedisCache(redisCfg)
+	case config.CacheBackendRueidis:
+		c, err = NewRueidisCache(redisCfg)
+	case config.CacheBackendFile:
+		c, err = NewFileCache(nsCfg.Dir, nsCfg.MaxAge, nsCfg.MaxSizeBytes)
+	case config.CacheBackendMemory, "":
+		c = NewShardedMemoryCache(name, nsCfg.MaxAge, 2*nsCfg.MaxAge)
+	default:
+		return nil, fmt.Errorf("unsupported backend %q", nsCfg.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if nsCfg.LocalCacheSize > 0 {
+		localTTL := nsCfg.LocalCacheTTL
+		if localTTL <= 0 {
+			localTTL = time.Minute
+		}
+		c = NewTieredCache(c, nsCfg.LocalCacheSize, localTTL)
+	}
+
+	if nsCfg.MaxEntryBytes > 0 {
+		c = &sizeLimitedCache{Cache: c, maxEntryBytes: nsCfg.MaxEntryBytes}
+	}
+	return c, nil
+}
+
+// Namespace returns the Cache for name, falling back to a fresh in-memory cache (and a
+// warning log) for a name NewCacheManager wasn't configured with, so a typo'd or new
+// namespace degrades gracefully instead of panicking the request path.
+func (m *CacheManager) Namespace(name string) *Namespace {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ns, ok := m.namespaces[name]; ok {
+		return ns
+	}
+	slog.Warn("Unknown cache namespace requested, falling back to an unconfigured in-memory cache", "namespace", name)
+	ns := &Namespace{Cache: NewShardedMemoryCache(name, 10*time.Minute, 15*time.Minute), name: name}
+	m.namespaces[name] = ns
+	return ns
+}
+
+// InvalidateNamespace deletes every entry in the namespace name, for the admin
+// DELETE /cache/{group} endpoint (see api.AdminHandler). Returns an error if name was
+// never configured, rather than falling back to a fresh cache the way Namespace does,
+// so an operator invalidating a typo'd group name finds out immediately.
+func (m *CacheManager) InvalidateNamespace(ctx context.Context, name string) error {
+	m.mu.Lock()
+	ns, ok := m.namespaces[name]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown cache namespace %q", name)
+	}
+	return ns.Cache.InvalidatePrefix(ctx, ns.name+":")
+}
+
+// InvalidateKey deletes the single entry identified by key (the same raw parts callers
+// pass to Namespace(name).Key(...)) within namespace name, for the admin
+// DELETE /cache/{group}?key= endpoint (see api.AdminHandler). Returns an error if name
+// was never configured, matching InvalidateNamespace.
+func (m *CacheManager) InvalidateKey(ctx context.Context, name, key string) error {
+	m.mu.Lock()
+	ns, ok := m.namespaces[name]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown cache namespace %q", name)
+	}
+	return ns.Cache.InvalidateKey(ctx, ns.Key(key))
+}
+
+// deleteExpirer is implemented by Cache backends that skip go-cache's own janitor (see
+// ShardedMemoryCache) and so need a periodic sweep to reclaim memory held by expired
+// entries.
+type deleteExpirer interface {
+	DeleteExpired()
+}
+
+// Sweep deletes expired entries from every namespace whose Cache needs a manual sweep.
+// Safe to call periodically from a single ticker (see main.go's cache cleanup
+// goroutine); namespaces that don't implement deleteExpirer (MemoryCache's own janitor,
+// RedisCache's TTLs, FileCache's own sweep goroutine) are skipped.
+func (m *CacheManager) Sweep() {
+	m.mu.Lock()
+	namespaces := make([]*Namespace, 0, len(m.namespaces))
+	for _, ns := range m.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	m.mu.Unlock()
+
+	for _, ns := range namespaces {
+		if sweeper, ok := ns.Cache.(deleteExpirer); ok {
+			sweeper.DeleteExpired()
+		}
+	}
+}
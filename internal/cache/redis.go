@@ -2,9 +2,13 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"sync/atomic"
 	"time"
+	"web-search-api-for-llms/internal/config"
 	"web-search-api-for-llms/internal/extractor"
 
 	"github.com/go-redis/redis/v8"
@@ -13,27 +17,173 @@ import (
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
+// RedisConfig describes how to connect to a Redis deployment, whether that's a single
+// node, a Sentinel-managed failover group, or a Redis Cluster.
+type RedisConfig struct {
+	// Addr is the single-node address, used when Addrs is empty.
+	Addr string
+	// Addrs is a seed list of host:port addresses: the sentinel nodes of a
+	// Sentinel-managed deployment (with MasterName set), or the cluster nodes of a Redis
+	// Cluster. Empty falls back to a single-node client at Addr.
+	Addrs []string
+	// MasterName is the sentinel master name to follow; non-empty selects a
+	// Sentinel-backed failover client regardless of ClusterMode.
+	MasterName string
+	// ClusterMode forces a Redis Cluster client even when Addrs has a single seed
+	// address (redis.NewUniversalClient otherwise only detects cluster mode from
+	// len(Addrs) > 1).
+	ClusterMode bool
+	Username    string
+	Password    string
+	DB          int
+	// TLS wraps the connection in TLS using the Go runtime's default root CA set.
+	TLS bool
+	// Codec selects the wire format NewRedisCache encodes new values with (see
+	// config.CacheCodec). Empty falls back to CacheCodecJSON, today's only format.
+	Codec config.CacheCodec
+	// Compression selects the algorithm NewRedisCache compresses new values past
+	// CompressionMinBytes with (see config.CacheCompression). Empty/CacheCompressionNone
+	// never compresses.
+	Compression config.CacheCompression
+	// CompressionMinBytes is the marshaled size above which Compression applies.
+	// Ignored when Compression is CacheCompressionNone.
+	CompressionMinBytes int64
+	// Namespace, once non-empty, is prepended to every key RedisCache reads or writes,
+	// together with a version counter stored in Redis under "{Namespace}:version" (see
+	// RedisCache.InvalidateNamespace). Lets multiple RAG-Forge deployments or tenants
+	// share one Redis instance without key collisions, e.g. "ragforge" and "ragforge-eu".
+	// Empty keeps keys unprefixed, matching every version of this cache before namespacing
+	// existed.
+	Namespace string
+}
+
 // RedisCache is a Redis-backed cache that implements the Cache interface.
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
+	// codec and tag encode every new write; see codecForTag for how a read picks the
+	// right codec regardless of which one wrote the value.
+	codec Codec
+	tag   codecTag
+	// compression and compressionMinBytes gate whether Set/MSet compress an encoded
+	// value before storing it; nil compression means never compress.
+	compression         compressionCodec
+	compressionMinBytes int64
+	// namespace and version scope every key this RedisCache builds; see
+	// RedisConfig.Namespace and InvalidateNamespace. namespace == "" disables
+	// versioning entirely, so key() is a no-op and behaves like every RedisCache before
+	// namespacing existed.
+	namespace string
+	version   atomic.Int64
+}
+
+// cacheInvalidationChannel is the Redis pub/sub channel RedisCache.InvalidateKey
+// publishes a purged key to, so every other replica's TieredCache can evict it from its
+// local tier immediately instead of waiting out that tier's TTL. Payload is the raw,
+// un-namespaced key (the same string callers pass to Get/Set), so it matches what
+// TieredCache's local LRU is keyed by regardless of this RedisCache's namespace/version.
+const cacheInvalidationChannel = "ragforge:cache:invalidate"
+
+// namespaceVersionKey is the Redis key RedisCache.InvalidateNamespace increments and
+// NewRedisCache reads at startup; kept out of namespace's own "{namespace}:v{n}:" key
+// space (which is itself versioned) so it never collides with a cached entry.
+func namespaceVersionKey(namespace string) string {
+	return namespace + ":__version__"
+}
+
+// key scopes k under c.namespace and its current version, e.g. key("search:abc") ->
+// "ragforge:v3:search:abc". A no-op when c.namespace is empty.
+func (c *RedisCache) key(k string) string {
+	if c.namespace == "" {
+		return k
+	}
+	return c.namespace + ":v" + strconv.FormatInt(c.version.Load(), 10) + ":" + k
+}
+
+// InvalidateNamespace atomically bumps c.namespace's version counter in Redis via INCR,
+// making every key written under the previous version unreachable (though not deleted;
+// they expire on their own TTL, or an operator can SCAN+DEL the old version's prefix to
+// reclaim space immediately). O(1) regardless of how many entries were cached, unlike
+// InvalidatePrefix's SCAN+DEL sweep. A no-op returning nil if c.namespace is empty.
+func (c *RedisCache) InvalidateNamespace(ctx context.Context) error {
+	if c.namespace == "" {
+		return nil
+	}
+	newVersion, err := c.client.Incr(ctx, namespaceVersionKey(c.namespace)).Result()
+	if err != nil {
+		return fmt.Errorf("redis INCR of namespace version failed: %w", err)
+	}
+	c.version.Store(newVersion)
+	slog.Info("RedisCache: bumped namespace version", "namespace", c.namespace, "version", newVersion)
+	return nil
 }
 
-// NewRedisCache creates a new RedisCache.
-func NewRedisCache(addr, password string, db int) *RedisCache {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
-	})
-	// Add connection pooling options for high concurrency
-	rdb.Options().PoolSize = 500
-	rdb.Options().MinIdleConns = 50
-	return &RedisCache{client: rdb}
+// NewRedisCache creates a new RedisCache, dispatching to a standalone, Sentinel, or
+// Cluster client depending on cfg, encoding new writes with cfg.Codec (default
+// CacheCodecJSON), and compressing them past cfg.CompressionMinBytes with cfg.Compression
+// (default: never). Returns an error only if cfg.Codec or cfg.Compression names an
+// unsupported value.
+func NewRedisCache(cfg RedisConfig) (*RedisCache, error) {
+	codec, tag, err := codecForName(cfg.Codec)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := compressionForName(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{cfg.Addr}
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS {
+		tlsConfig = &tls.Config{}
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:        addrs,
+		MasterName:   cfg.MasterName,
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		TLSConfig:    tlsConfig,
+		PoolSize:     500,
+		MinIdleConns: 50,
+	}
+
+	// redis.NewUniversalClient only picks a ClusterClient when len(Addrs) > 1; force it
+	// here so a single-seed cluster (e.g. one discovery node fronting the others) is
+	// still routed as a cluster.
+	var client redis.UniversalClient
+	if cfg.ClusterMode && cfg.MasterName == "" {
+		client = redis.NewClusterClient(opts.Cluster())
+	} else {
+		client = redis.NewUniversalClient(opts)
+	}
+
+	c := &RedisCache{
+		client:              client,
+		codec:               codec,
+		tag:                 tag,
+		compression:         compression,
+		compressionMinBytes: cfg.CompressionMinBytes,
+		namespace:           cfg.Namespace,
+	}
+	if cfg.Namespace != "" {
+		version, err := client.Get(context.Background(), namespaceVersionKey(cfg.Namespace)).Int64()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("fetch initial namespace version: %w", err)
+		}
+		c.version.Store(version)
+	}
+	return c, nil
 }
 
 // GetExtractedResult retrieves an ExtractedResult from the cache.
 func (c *RedisCache) GetExtractedResult(ctx context.Context, key string) (*extractor.ExtractedResult, bool) {
-	val, err := c.client.Get(ctx, key).Result()
+	val, err := c.client.Get(ctx, c.key(key)).Bytes()
 	if err != nil {
 		if err != redis.Nil {
 			slog.Warn("Redis GET failed", "key", key, "error", err)
@@ -41,42 +191,50 @@ func (c *RedisCache) GetExtractedResult(ctx context.Context, key string) (*extra
 		return nil, false
 	}
 	var result extractor.ExtractedResult
-	if err := json.Unmarshal([]byte(val), &result); err != nil {
+	if err := decodeTagged(val, &result); err != nil {
 		slog.Warn("RedisCache: Failed to unmarshal ExtractedResult", "key", key, "error", err)
 		return nil, false
 	}
 	return &result, true
 }
 
-// Add MGetExtractedResults to RedisCache
+// MGetExtractedResults fetches several keys at once. This is a pipeline of individual
+// GET commands rather than a single MGET: a Redis Cluster only allows a multi-key
+// command when every key hashes to the same slot, but ClusterClient.Pipeline splits
+// per-key commands across the owning nodes automatically, so this stays correct
+// (and still a single round trip per node) whether client is standalone, Sentinel, or
+// Cluster.
 func (c *RedisCache) MGetExtractedResults(ctx context.Context, keys []string) (map[string]*extractor.ExtractedResult, error) {
 	if len(keys) == 0 {
 		return make(map[string]*extractor.ExtractedResult), nil
 	}
-	results := make(map[string]*extractor.ExtractedResult, len(keys))
-	vals, err := c.client.MGet(ctx, keys...).Result()
-	if err != nil {
-		// Don't treat redis.Nil as a critical error for MGET
-		if err == redis.Nil {
-			return results, nil
-		}
-		return nil, fmt.Errorf("redis MGET failed: %w", err)
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, c.key(key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("redis pipelined GET failed: %w", err)
 	}
 
-	for i, val := range vals {
-		if val == nil {
-			continue // Key not found
-		}
-		if strVal, ok := val.(string); ok && strVal != "" {
-			// Use the pool to avoid allocation inside the loop
-			pooledResult := extractor.ExtractedResultPool.Get().(*extractor.ExtractedResult)
-			if err := json.Unmarshal([]byte(strVal), pooledResult); err == nil {
-				results[keys[i]] = pooledResult
-			} else {
-				slog.Warn("RedisCache: MGET failed to unmarshal ExtractedResult", "key", keys[i], "error", err)
-				// IMPORTANT: Put back in the pool if unmarshal fails
-				extractor.ExtractedResultPool.Put(pooledResult)
+	results := make(map[string]*extractor.ExtractedResult, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Bytes()
+		if err != nil {
+			if err != redis.Nil {
+				slog.Warn("RedisCache: MGET failed for key", "key", keys[i], "error", err)
 			}
+			continue
+		}
+		// Use the pool to avoid allocation inside the loop
+		pooledResult := extractor.ExtractedResultPool.Get().(*extractor.ExtractedResult)
+		if err := decodeTagged(val, pooledResult); err == nil {
+			results[keys[i]] = pooledResult
+		} else {
+			slog.Warn("RedisCache: MGET failed to unmarshal ExtractedResult", "key", keys[i], "error", err)
+			// IMPORTANT: Put back in the pool if unmarshal fails
+			extractor.ExtractedResultPool.Put(pooledResult)
 		}
 	}
 	return results, nil
@@ -84,7 +242,7 @@ func (c *RedisCache) MGetExtractedResults(ctx context.Context, keys []string) (m
 
 // GetSearchURLs retrieves a slice of URLs from the cache.
 func (c *RedisCache) GetSearchURLs(ctx context.Context, key string) ([]string, bool) {
-	val, err := c.client.Get(ctx, key).Result()
+	val, err := c.client.Get(ctx, c.key(key)).Bytes()
 	if err != nil {
 		if err != redis.Nil {
 			slog.Warn("Redis GET failed for search URLs", "key", key, "error", err)
@@ -92,26 +250,28 @@ func (c *RedisCache) GetSearchURLs(ctx context.Context, key string) ([]string, b
 		return nil, false
 	}
 	var urls []string
-	if err := json.Unmarshal([]byte(val), &urls); err != nil {
+	if err := decodeTagged(val, &urls); err != nil {
 		slog.Warn("RedisCache: Failed to unmarshal URL slice", "key", key, "error", err)
 		return nil, false
 	}
 	return urls, true
 }
 
-// Set adds a value to the cache.
+// Set adds a value to the cache, encoded with c.codec and prefixed with c.tag.
 func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, duration time.Duration) {
-	jsonBytes, err := json.Marshal(value)
+	tagged, err := encodeTagged(c.codec, c.tag, c.compression, c.compressionMinBytes, value)
 	if err != nil {
 		slog.Warn("RedisCache: Failed to marshal value", "key", key, "error", err)
 		return
 	}
-	if err := c.client.Set(ctx, key, jsonBytes, duration).Err(); err != nil {
+	if err := c.client.Set(ctx, c.key(key), tagged, duration).Err(); err != nil {
 		slog.Warn("Redis SET failed", "key", key, "error", err)
 	}
 }
 
-// MSet is a batched/pipelined SET for Redis.
+// MSet is a batched/pipelined SET for Redis. Like MGetExtractedResults, this pipelines
+// individual per-key SET commands rather than issuing one multi-key MSET, so
+// ClusterClient.Pipeline can fan each command out to the node owning its slot.
 func (c *RedisCache) MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error {
 	if len(items) == 0 {
 		return nil
@@ -119,12 +279,12 @@ func (c *RedisCache) MSet(ctx context.Context, items map[string]interface{}, dur
 
 	pipe := c.client.Pipeline()
 	for key, value := range items {
-		jsonBytes, err := json.Marshal(value)
+		tagged, err := encodeTagged(c.codec, c.tag, c.compression, c.compressionMinBytes, value)
 		if err != nil {
 			slog.Warn("RedisCache MSet: Failed to marshal value, skipping item", "key", key, "error", err)
 			continue
 		}
-		pipe.Set(ctx, key, jsonBytes, duration)
+		pipe.Set(ctx, c.key(key), tagged, duration)
 	}
 
 	_, err := pipe.Exec(ctx)
@@ -134,3 +294,116 @@ func (c *RedisCache) MSet(ctx context.Context, items map[string]interface{}, dur
 	}
 	return nil
 }
+
+// InvalidatePrefix deletes every key starting with prefix, via SCAN so it doesn't block
+// the shared Redis instance the way KEYS would on a large keyspace. Multiple namespaces
+// can share one Redis connection (unlike the per-namespace Memory/File backends), so
+// this is the only backend that needs prefix matching rather than a full flush. Against
+// a Redis Cluster, SCAN only sees one node's keyspace at a time, so this fans out across
+// every master node instead of scanning a single connection.
+func (c *RedisCache) InvalidatePrefix(ctx context.Context, prefix string) error {
+	prefix = c.key(prefix)
+	if clusterClient, ok := c.client.(*redis.ClusterClient); ok {
+		var deleted int64
+		err := clusterClient.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			// perKey: true. Even though node is scoped to a single physical master, a
+			// master owns a whole range of hash slots, so a batched multi-key DEL across
+			// keys from different slots still gets rejected with CROSSSLOT.
+			n, err := scanAndDelete(ctx, node, prefix, true)
+			if err != nil {
+				return err
+			}
+			atomic.AddInt64(&deleted, n)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("redis cluster SCAN/DEL failed: %w", err)
+		}
+		slog.Info("RedisCache: invalidated prefix across cluster", "prefix", prefix, "count", deleted)
+		return nil
+	}
+
+	deleted, err := scanAndDelete(ctx, c.client, prefix, false)
+	if err != nil {
+		return fmt.Errorf("redis SCAN failed: %w", err)
+	}
+	slog.Info("RedisCache: invalidated prefix", "prefix", prefix, "count", deleted)
+	return nil
+}
+
+// InvalidateKey deletes a single key and publishes it on cacheInvalidationChannel, so
+// every replica's TieredCache (see SubscribeKeyInvalidations) drops it from their local
+// tier too, instead of only this replica's local tier and the shared Redis entry.
+func (c *RedisCache) InvalidateKey(ctx context.Context, key string) error {
+	if err := c.client.Del(ctx, c.key(key)).Err(); err != nil {
+		return fmt.Errorf("redis DEL failed: %w", err)
+	}
+	if err := c.client.Publish(ctx, cacheInvalidationChannel, key).Err(); err != nil {
+		slog.Warn("RedisCache: failed to publish key invalidation", "key", key, "error", err)
+	}
+	return nil
+}
+
+// SubscribeKeyInvalidations subscribes to cacheInvalidationChannel and calls onInvalidate
+// for every key InvalidateKey publishes, from any replica (including this one). The
+// subscription runs until ctx is done. TieredCache calls this when it wraps a RedisCache,
+// to keep its local tier from serving a key another replica just purged.
+func (c *RedisCache) SubscribeKeyInvalidations(ctx context.Context, onInvalidate func(key string)) {
+	pubsub := c.client.Subscribe(ctx, cacheInvalidationChannel)
+	go func() {
+		defer func() {
+			if err := pubsub.Close(); err != nil {
+				slog.Warn("RedisCache: failed to close invalidation subscription", "error", err)
+			}
+		}()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				onInvalidate(msg.Payload)
+			}
+		}
+	}()
+}
+
+// scanAndDelete runs the SCAN+DEL loop against a single node (standalone client, or one
+// master of a cluster) and returns how many keys it deleted. perKey must be true when
+// client is scoped to one cluster node: a master owns a whole range of hash slots, so a
+// single batched multi-key DEL across keys from different slots fails with CROSSSLOT
+// even though every key is local to that node. Pipelining one DEL per key keeps that
+// case a single round trip per node, same as the batched DEL used for a standalone or
+// Sentinel client (perKey false), where there's no slot concept to violate.
+func scanAndDelete(ctx context.Context, client redis.UniversalClient, prefix string, perKey bool) (int64, error) {
+	var cursor uint64
+	var deleted int64
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, prefix+"*", 1000).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			if perKey {
+				pipe := client.Pipeline()
+				for _, k := range keys {
+					pipe.Del(ctx, k)
+				}
+				if _, err := pipe.Exec(ctx); err != nil {
+					return deleted, err
+				}
+			} else if err := client.Del(ctx, keys...).Err(); err != nil {
+				return deleted, err
+			}
+			deleted += int64(len(keys))
+		}
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+	return deleted, nil
+}
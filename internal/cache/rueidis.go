@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"time"
+	"web-search-api-for-llms/internal/extractor"
+
+	"github.com/redis/rueidis"
+)
+
+// rueidisClientCacheTTL bounds how long a value served via DoCache/DoMultiCache is
+// trusted locally before rueidis re-validates it against Redis, independent of the
+// entry's own TTL. A cache invalidation pushed over the CLIENT TRACKING connection
+// evicts it sooner, so this is a ceiling rather than the expected lifetime.
+const rueidisClientCacheTTL = 10 * time.Minute
+
+// RueidisCache is a Redis-backed cache like RedisCache, but built on
+// github.com/redis/rueidis instead of go-redis so GetExtractedResult and
+// MGetExtractedResults can be served from rueidis's opt-in client-side cache (Redis 6+
+// CLIENT TRACKING) instead of round-tripping to Redis on every lookup. This matters for
+// "hot" URLs that show up in many concurrent search results: once the first request
+// populates the client-side cache, every later one for the same URL is answered
+// in-process until Redis pushes an invalidation or the local TTL below expires.
+type RueidisCache struct {
+	client rueidis.Client
+}
+
+// NewRueidisCache creates a new RueidisCache from the same RedisConfig used by
+// NewRedisCache, dialing a standalone, Sentinel, or Cluster deployment according to
+// cfg's fields. Unlike go-redis's UniversalClient, rueidis autodetects cluster vs.
+// standalone topology from the server itself, so cfg.ClusterMode isn't needed to force
+// it; it exists only so callers can share one RedisConfig across both backends.
+func NewRueidisCache(cfg RedisConfig) (*RueidisCache, error) {
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{cfg.Addr}
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS {
+		tlsConfig = &tls.Config{}
+	}
+
+	opt := rueidis.ClientOption{
+		InitAddress: addrs,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		SelectDB:    cfg.DB,
+		TLSConfig:   tlsConfig,
+	}
+	if cfg.MasterName != "" {
+		opt.Sentinel = rueidis.SentinelOption{
+			MasterSet: cfg.MasterName,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+		}
+	}
+
+	client, err := rueidis.NewClient(opt)
+	if err != nil {
+		return nil, fmt.Errorf("rueidis: failed to create client: %w", err)
+	}
+	return &RueidisCache{client: client}, nil
+}
+
+// GetExtractedResult retrieves an ExtractedResult from the cache, served from the
+// in-process tracked cache when the key was read recently and hasn't been invalidated.
+func (c *RueidisCache) GetExtractedResult(ctx context.Context, key string) (*extractor.ExtractedResult, bool) {
+	resp := c.client.DoCache(ctx, c.client.B().Get().Key(key).Cache(), rueidisClientCacheTTL)
+	val, err := resp.ToString()
+	if err != nil {
+		if !rueidis.IsRedisNil(err) {
+			slog.Warn("Rueidis GET failed", "key", key, "error", err)
+		}
+		return nil, false
+	}
+	var result extractor.ExtractedResult
+	if err := json.Unmarshal([]byte(val), &result); err != nil {
+		slog.Warn("RueidisCache: Failed to unmarshal ExtractedResult", "key", key, "error", err)
+		return nil, false
+	}
+	return &result, true
+}
+
+// MGetExtractedResults fetches several keys at once via DoMultiCache, which groups the
+// cacheable GETs by slot and only goes to Redis for the ones that missed the tracked
+// cache.
+func (c *RueidisCache) MGetExtractedResults(ctx context.Context, keys []string) (map[string]*extractor.ExtractedResult, error) {
+	if len(keys) == 0 {
+		return make(map[string]*extractor.ExtractedResult), nil
+	}
+
+	cmds := make([]rueidis.CacheableTTL, len(keys))
+	for i, key := range keys {
+		cmds[i] = rueidis.CT(c.client.B().Get().Key(key).Cache(), rueidisClientCacheTTL)
+	}
+	resps := c.client.DoMultiCache(ctx, cmds...)
+
+	results := make(map[string]*extractor.ExtractedResult, len(keys))
+	for i, resp := range resps {
+		val, err := resp.ToString()
+		if err != nil {
+			if !rueidis.IsRedisNil(err) {
+				slog.Warn("RueidisCache: MGET failed for key", "key", keys[i], "error", err)
+			}
+			continue
+		}
+		// Use the pool to avoid allocation inside the loop
+		pooledResult := extractor.ExtractedResultPool.Get().(*extractor.ExtractedResult)
+		if err := json.Unmarshal([]byte(val), pooledResult); err == nil {
+			results[keys[i]] = pooledResult
+		} else {
+			slog.Warn("RueidisCache: MGET failed to unmarshal ExtractedResult", "key", keys[i], "error", err)
+			// IMPORTANT: Put back in the pool if unmarshal fails
+			extractor.ExtractedResultPool.Put(pooledResult)
+		}
+	}
+	return results, nil
+}
+
+// GetSearchURLs retrieves a slice of URLs from the cache, also served through the
+// tracked client-side cache.
+func (c *RueidisCache) GetSearchURLs(ctx context.Context, key string) ([]string, bool) {
+	resp := c.client.DoCache(ctx, c.client.B().Get().Key(key).Cache(), rueidisClientCacheTTL)
+	val, err := resp.ToString()
+	if err != nil {
+		if !rueidis.IsRedisNil(err) {
+			slog.Warn("Rueidis GET failed for search URLs", "key", key, "error", err)
+		}
+		return nil, false
+	}
+	var urls []string
+	if err := json.Unmarshal([]byte(val), &urls); err != nil {
+		slog.Warn("RueidisCache: Failed to unmarshal URL slice", "key", key, "error", err)
+		return nil, false
+	}
+	return urls, true
+}
+
+// Set adds a value to the cache. Writes always go through SETEX/SET rather than
+// DoCache, which is read-only; the tracked client-side caches of every other process
+// (including this one's) are invalidated automatically once Redis sees the write.
+func (c *RueidisCache) Set(ctx context.Context, key string, value interface{}, duration time.Duration) {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		slog.Warn("RueidisCache: Failed to marshal value", "key", key, "error", err)
+		return
+	}
+	cmd := setCommand(c.client.B(), key, string(jsonBytes), duration)
+	if err := c.client.Do(ctx, cmd).Error(); err != nil {
+		slog.Warn("Rueidis SET failed", "key", key, "error", err)
+	}
+}
+
+// MSet is a batched/pipelined SET for rueidis: DoMulti sends every command in one
+// round trip (per node, for a cluster) the same way go-redis's Pipeline does.
+func (c *RueidisCache) MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	cmds := make([]rueidis.Completed, 0, len(items))
+	for key, value := range items {
+		jsonBytes, err := json.Marshal(value)
+		if err != nil {
+			slog.Warn("RueidisCache MSet: Failed to marshal value, skipping item", "key", key, "error", err)
+			continue
+		}
+		cmds = append(cmds, setCommand(c.client.B(), key, string(jsonBytes), duration))
+	}
+
+	for _, resp := range c.client.DoMulti(ctx, cmds...) {
+		if err := resp.Error(); err != nil {
+			slog.Warn("Rueidis pipelined SET failed", "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// setCommand builds a SET (duration <= 0, meaning "never expire") or SETEX (duration >
+// 0) command, since SETEX rejects a non-positive expiry.
+func setCommand(b rueidis.Builder, key, value string, duration time.Duration) rueidis.Completed {
+	if duration <= 0 {
+		return b.Set().Key(key).Value(value).Build()
+	}
+	return b.Setex().Key(key).Seconds(int64(duration.Seconds())).Value(value).Build()
+}
+
+// InvalidatePrefix deletes every key starting with prefix, via SCAN so it doesn't block
+// the shared Redis instance the way KEYS would on a large keyspace. Client.Nodes()
+// already returns one client per cluster shard (or the single node itself for a
+// standalone/Sentinel deployment), so fanning out over it covers every topology without
+// a separate cluster-only code path like RedisCache.InvalidatePrefix needs.
+func (c *RueidisCache) InvalidatePrefix(ctx context.Context, prefix string) error {
+	var deleted int64
+	for addr, node := range c.client.Nodes() {
+		n, err := rueidisScanAndDelete(ctx, node, prefix)
+		if err != nil {
+			return fmt.Errorf("rueidis SCAN/DEL failed on node %s: %w", addr, err)
+		}
+		deleted += n
+	}
+	slog.Info("RueidisCache: invalidated prefix", "prefix", prefix, "count", deleted)
+	return nil
+}
+
+// InvalidateKey deletes a single key. Unlike RedisCache, this needs no explicit pub/sub:
+// every replica's DoCache/DoMultiCache reads are served through Redis's CLIENT TRACKING,
+// so this DEL alone pushes an invalidation to every replica's tracked client-side cache
+// for key, the same way a Set already does.
+func (c *RueidisCache) InvalidateKey(ctx context.Context, key string) error {
+	if err := c.client.Do(ctx, c.client.B().Del().Key(key).Build()).Error(); err != nil {
+		return fmt.Errorf("rueidis DEL failed: %w", err)
+	}
+	return nil
+}
+
+// rueidisScanAndDelete runs the SCAN+DEL loop against a single node and returns how
+// many keys it deleted.
+func rueidisScanAndDelete(ctx context.Context, node rueidis.Client, prefix string) (int64, error) {
+	var cursor uint64
+	var deleted int64
+	for {
+		entry, err := node.Do(ctx, node.B().Scan().Cursor(cursor).Match(prefix+"*").Count(1000).Build()).AsScanEntry()
+		if err != nil {
+			return deleted, err
+		}
+		if len(entry.Elements) > 0 {
+			if err := node.Do(ctx, node.B().Del().Key(entry.Elements...).Build()).Error(); err != nil {
+				return deleted, err
+			}
+			deleted += int64(len(entry.Elements))
+		}
+		cursor = entry.Cursor
+		if cursor == 0 {
+			break
+		}
+	}
+	return deleted, nil
+}
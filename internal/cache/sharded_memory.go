@@ -2,9 +2,11 @@ package cache
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 	"web-search-api-for-llms/internal/extractor"
+	"web-search-api-for-llms/internal/metrics"
 
 	"github.com/cespare/xxhash/v2"
 	"github.com/patrickmn/go-cache"
@@ -12,13 +14,21 @@ import (
 
 const shardCount = 256 // A power of 2 is good. Adjust based on expected load.
 
+// name, when non-empty, labels ShardedMemoryCache's metrics.CacheHits/CacheMisses
+// observations so /metrics can break hit rate down per cache namespace; an unnamed
+// instance (e.g. CacheManager.Namespace's fallback for an unconfigured namespace)
+// reports under "unknown" rather than being silently dropped from the label set.
 type ShardedMemoryCache struct {
 	shards []*cache.Cache
+	name   string
 }
 
-func NewShardedMemoryCache(defaultExpiration, cleanupInterval time.Duration) *ShardedMemoryCache {
+// NewShardedMemoryCache creates a sharded in-memory cache labeled name for metrics
+// purposes (see CacheHits/CacheMisses).
+func NewShardedMemoryCache(name string, defaultExpiration, cleanupInterval time.Duration) *ShardedMemoryCache {
 	c := &ShardedMemoryCache{
 		shards: make([]*cache.Cache, shardCount),
+		name:   name,
 	}
 	for i := 0; i < shardCount; i++ {
 		// Pass -1 for cleanupInterval to prevent go-cache from starting its own janitor.
@@ -59,12 +69,19 @@ func (c *ShardedMemoryCache) Set(ctx context.Context, key string, value interfac
 	shard.Set(key, value, duration)
 }
 
-// MGetExtractedResults retrieves multiple ExtractedResults from the sharded cache concurrently.
+// MGetExtractedResults retrieves multiple ExtractedResults from the sharded cache
+// concurrently, recording a metrics.CacheHits/CacheMisses observation per key so
+// /metrics shows this namespace's hit rate.
 func (c *ShardedMemoryCache) MGetExtractedResults(ctx context.Context, keys []string) (map[string]*extractor.ExtractedResult, error) {
 	if len(keys) == 0 {
 		return make(map[string]*extractor.ExtractedResult), nil
 	}
 
+	cacheLabel := c.name
+	if cacheLabel == "" {
+		cacheLabel = "unknown"
+	}
+
 	// Group keys by shard index
 	keysByShard := make([][]string, shardCount)
 	for _, key := range keys {
@@ -85,13 +102,17 @@ func (c *ShardedMemoryCache) MGetExtractedResults(ctx context.Context, keys []st
 			go func(shard *cache.Cache, keys []string) {
 				defer wg.Done()
 				for _, key := range keys {
-					if val, found := shard.Get(key); found {
+					val, found := shard.Get(key)
+					if found {
 						if result, ok := val.(*extractor.ExtractedResult); ok {
 							mu.Lock()
 							resultsMap[key] = result
 							mu.Unlock()
+							metrics.CacheHits.WithLabelValues(cacheLabel).Inc()
+							continue
 						}
 					}
+					metrics.CacheMisses.WithLabelValues(cacheLabel).Inc()
 				}
 			}(c.shards[i], shardKeys)
 		}
@@ -100,6 +121,7 @@ func (c *ShardedMemoryCache) MGetExtractedResults(ctx context.Context, keys []st
 	wg.Wait()
 	return resultsMap, nil
 }
+
 // MSet provides a batched write for the sharded in-memory cache.
 // Note: This is not a true pipelined operation like in Redis, but it satisfies the interface.
 func (c *ShardedMemoryCache) MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error {
@@ -116,4 +138,23 @@ func (c *ShardedMemoryCache) DeleteExpired() {
 	for _, shard := range c.shards {
 		shard.DeleteExpired()
 	}
-}
\ No newline at end of file
+}
+
+// InvalidatePrefix deletes every entry whose key starts with prefix, across all shards.
+func (c *ShardedMemoryCache) InvalidatePrefix(ctx context.Context, prefix string) error {
+	for _, shard := range c.shards {
+		for key := range shard.Items() {
+			if strings.HasPrefix(key, prefix) {
+				shard.Delete(key)
+			}
+		}
+	}
+	return nil
+}
+
+// InvalidateKey deletes a single entry. A ShardedMemoryCache only exists within one
+// process, so unlike RedisCache this has no other replica to notify.
+func (c *ShardedMemoryCache) InvalidateKey(ctx context.Context, key string) error {
+	c.getShard(key).Delete(key)
+	return nil
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"time"
+	"web-search-api-for-llms/internal/extractor"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/singleflight"
+)
+
+// TieredCache fronts another Cache (typically RedisCache or RueidisCache) with a
+// bounded, TTL-expiring in-process LRU keyed by the same cache key the backend uses, so
+// a popular SERP domain re-requested by many concurrent callers in this process costs
+// one Redis round trip instead of one per request. Reads for a key missing from the
+// local tier are coalesced with singleflight, so concurrent callers for that same key
+// share one backend fetch (and, upstream of that, one downstream extraction) rather than
+// each racing to populate the cache. Writes go to both tiers so a value this process just
+// stored is immediately visible to its own local reads.
+type TieredCache struct {
+	Cache
+	local *lru.LRU[string, any]
+	group singleflight.Group
+}
+
+// keyInvalidationSubscriber is implemented by Cache backends that can notify a
+// TieredCache when another replica purges a key (see RedisCache.SubscribeKeyInvalidations).
+// RueidisCache needs no such hook: its CLIENT TRACKING already evicts stale entries from
+// this process's tracked cache, so TieredCache.local would otherwise be the only tier
+// left out of that propagation.
+type keyInvalidationSubscriber interface {
+	SubscribeKeyInvalidations(ctx context.Context, onInvalidate func(key string))
+}
+
+// NewTieredCache wraps backend with a local LRU capped at localSize entries, each
+// expiring after localTTL (which should be <= backend's own TTLs; the local tier is a
+// speed optimization, not a second source of truth for expiry). If backend supports
+// keyInvalidationSubscriber (RedisCache), NewTieredCache subscribes for the life of the
+// process so a key purged on another replica via InvalidateKey is evicted from this
+// process's local tier immediately rather than waiting out localTTL.
+func NewTieredCache(backend Cache, localSize int, localTTL time.Duration) *TieredCache {
+	c := &TieredCache{
+		Cache: backend,
+		local: lru.NewLRU[string, any](localSize, nil, localTTL),
+	}
+	if sub, ok := backend.(keyInvalidationSubscriber); ok {
+		sub.SubscribeKeyInvalidations(context.Background(), func(key string) {
+			c.local.Remove(key)
+		})
+	}
+	return c
+}
+
+// GetExtractedResult serves key from the local tier if present, otherwise fetches it
+// from the backend (coalescing concurrent callers for the same key via singleflight) and
+// populates the local tier before returning.
+func (c *TieredCache) GetExtractedResult(ctx context.Context, key string) (*extractor.ExtractedResult, bool) {
+	if val, ok := c.local.Get(key); ok {
+		result, ok := val.(*extractor.ExtractedResult)
+		return result, ok
+	}
+
+	val, err, _ := c.group.Do("er:"+key, func() (interface{}, error) {
+		result, found := c.Cache.GetExtractedResult(ctx, key)
+		if !found {
+			return nil, nil
+		}
+		return result, nil
+	})
+	if err != nil || val == nil {
+		return nil, false
+	}
+	result := val.(*extractor.ExtractedResult)
+	c.local.Add(key, result)
+	return result, true
+}
+
+// GetSearchURLs serves key from the local tier if present, otherwise fetches it from the
+// backend (coalescing concurrent callers via singleflight) and populates the local tier.
+func (c *TieredCache) GetSearchURLs(ctx context.Context, key string) ([]string, bool) {
+	if val, ok := c.local.Get(key); ok {
+		urls, ok := val.([]string)
+		return urls, ok
+	}
+
+	val, err, _ := c.group.Do("su:"+key, func() (interface{}, error) {
+		urls, found := c.Cache.GetSearchURLs(ctx, key)
+		if !found {
+			return nil, nil
+		}
+		return urls, nil
+	})
+	if err != nil || val == nil {
+		return nil, false
+	}
+	urls := val.([]string)
+	c.local.Add(key, urls)
+	return urls, true
+}
+
+// MGetExtractedResults first satisfies as many keys as possible from the local tier,
+// then batches whichever keys remain to a single backend MGetExtractedResults call, then
+// populates the local tier with whatever that call found. Unlike the single-key getters,
+// misses here aren't singleflight-coalesced: the batch itself is already one round trip
+// per caller, and different callers rarely request the exact same batch of keys.
+func (c *TieredCache) MGetExtractedResults(ctx context.Context, keys []string) (map[string]*extractor.ExtractedResult, error) {
+	results := make(map[string]*extractor.ExtractedResult, len(keys))
+	var remaining []string
+	for _, key := range keys {
+		if val, ok := c.local.Get(key); ok {
+			if result, ok := val.(*extractor.ExtractedResult); ok {
+				results[key] = result
+				continue
+			}
+		}
+		remaining = append(remaining, key)
+	}
+	if len(remaining) == 0 {
+		return results, nil
+	}
+
+	fetched, err := c.Cache.MGetExtractedResults(ctx, remaining)
+	if err != nil {
+		return nil, err
+	}
+	for key, result := range fetched {
+		c.local.Add(key, result)
+		results[key] = result
+	}
+	return results, nil
+}
+
+// Set writes through to the local tier and the backend.
+func (c *TieredCache) Set(ctx context.Context, key string, value interface{}, duration time.Duration) {
+	c.local.Add(key, value)
+	c.Cache.Set(ctx, key, value, duration)
+}
+
+// MSet writes through to the local tier and the backend.
+func (c *TieredCache) MSet(ctx context.Context, items map[string]interface{}, duration time.Duration) error {
+	for key, value := range items {
+		c.local.Add(key, value)
+	}
+	return c.Cache.MSet(ctx, items, duration)
+}
+
+// InvalidatePrefix evicts every local-tier entry starting with prefix, then invalidates
+// the backend. The local tier has no native prefix index, so this scans its (bounded)
+// key set; acceptable since it only runs on the admin "invalidate a cache group" path,
+// not the request hot path.
+func (c *TieredCache) InvalidatePrefix(ctx context.Context, prefix string) error {
+	for _, key := range c.local.Keys() {
+		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
+			c.local.Remove(key)
+		}
+	}
+	return c.Cache.InvalidatePrefix(ctx, prefix)
+}
+
+// InvalidateKey evicts key from the local tier, then invalidates it on the backend. When
+// the backend is a RedisCache, InvalidateKey also publishes the purge so every other
+// replica's own local tier (subscribed in NewTieredCache) evicts it too.
+func (c *TieredCache) InvalidateKey(ctx context.Context, key string) error {
+	c.local.Remove(key)
+	return c.Cache.InvalidateKey(ctx, key)
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/andybalholm/brotli"
+
+	"web-search-api-for-llms/internal/extractor"
+	"web-search-api-for-llms/internal/warc"
+)
+
+// ReplayWARC reads every response record out of the WARC file at path (see
+// warc.ReadRecords) and seeds m with its content, keyed the same way a live /extract
+// request would (see api.contentCacheKey with no maxChars cap), so an archived crawl can
+// stand in for the live extractors on an offline rerun without refetching anything.
+// Only plain HTML responses are reconstructed, via WebpageExtractor.ExtractFromContent;
+// a source-specific result (Reddit JSON, a YouTube API response, ...) isn't replayable
+// this way, so replay is meant for the generic webpage corpus WARC archiving targets. A
+// record that fails to parse or decode is logged and skipped rather than aborting the
+// whole replay. cacheGroup classifies a target URI the same way Dispatcher.CacheGroup
+// would, and ttl is the duration to cache each entry for. It returns the number of
+// entries cached.
+func ReplayWARC(ctx context.Context, path string, m *CacheManager, cacheGroup func(string) string, ttl time.Duration) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open WARC file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	records, err := warc.ReadRecords(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read WARC file %s: %w", path, err)
+	}
+
+	webpage := &extractor.WebpageExtractor{}
+	cached := 0
+	for _, record := range records {
+		if record.Type != "response" || record.TargetURI == "" {
+			continue
+		}
+
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(record.Body)), nil)
+		if err != nil {
+			slog.Warn("ReplayWARC: failed to parse response record, skipping", "url", record.TargetURI, "error", err)
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			resp.Body.Close()
+			continue
+		}
+
+		body, err := decodeWARCResponseBody(resp)
+		resp.Body.Close()
+		if err != nil {
+			slog.Warn("ReplayWARC: failed to decode response body, skipping", "url", record.TargetURI, "error", err)
+			continue
+		}
+
+		result := &extractor.ExtractedResult{URL: record.TargetURI}
+		if err := webpage.ExtractFromContent(record.TargetURI, body, nil, result); err != nil {
+			slog.Warn("ReplayWARC: failed to extract content, skipping", "url", record.TargetURI, "error", err)
+			continue
+		}
+		result.ProcessedSuccessfully = true
+
+		ns := m.Namespace(cacheGroup(record.TargetURI))
+		ns.Set(ctx, ns.Key(record.TargetURI), result, ttl)
+		cached++
+	}
+
+	return cached, nil
+}
+
+// decodeWARCResponseBody reads resp.Body, undoing whatever Content-Encoding the archived
+// response carries, mirroring BaseExtractor.DoDecoded's decoding for a live fetch.
+func decodeWARCResponseBody(resp *http.Response) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	switch resp.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	case "deflate":
+		fl := flate.NewReader(resp.Body)
+		defer fl.Close()
+		reader = fl
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	}
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,148 @@
+package cookies
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// chromeProfileDir returns the on-disk profile directory for the given Chromium-family
+// browser and profile name (defaulting to "Default").
+func chromeProfileDir(browser Browser, profile string) (string, error) {
+	if profile == "" {
+		profile = "Default"
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+
+	var base string
+	switch runtime.GOOS {
+	case "darwin":
+		base = filepath.Join(home, "Library", "Application Support", chromeAppDirName(browser))
+	case "windows":
+		base = filepath.Join(os.Getenv("LOCALAPPDATA"), chromeAppDirName(browser), "User Data")
+	default: // linux and other unix-likes
+		base = filepath.Join(home, ".config", chromeAppDirName(browser))
+	}
+
+	return filepath.Join(base, profile), nil
+}
+
+func chromeAppDirName(browser Browser) string {
+	switch browser {
+	case BrowserChromium:
+		return "Chromium"
+	case BrowserBrave:
+		return "BraveSoftware/Brave-Browser"
+	case BrowserEdge:
+		return "Microsoft Edge"
+	default:
+		return "Google/Chrome"
+	}
+}
+
+// readChromiumCookies opens a snapshot of the browser's Cookies SQLite database and
+// decrypts each value using the platform-specific key.
+func readChromiumCookies(browser Browser, profile string) ([]rawCookie, error) {
+	profileDir, err := chromeProfileDir(browser, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	dbPath := filepath.Join(profileDir, "Network", "Cookies")
+	if _, err := os.Stat(dbPath); err != nil {
+		// Chrome versions before ~M96 kept the Cookies DB directly under the profile dir.
+		dbPath = filepath.Join(profileDir, "Cookies")
+	}
+
+	// Chrome keeps a write lock on the database while it is running, so work off a copy.
+	tmpPath, err := copyToTemp(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot cookie database %s: %w", dbPath, err)
+	}
+	defer os.Remove(tmpPath)
+
+	db, err := sql.Open("sqlite", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cookie database: %w", err)
+	}
+	defer db.Close()
+
+	key, err := chromeDecryptionKey(browser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain cookie decryption key: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT host_key, name, encrypted_value, path, is_secure, is_httponly, expires_utc FROM cookies`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cookies table: %w", err)
+	}
+	defer rows.Close()
+
+	var out []rawCookie
+	for rows.Next() {
+		var (
+			host, name, path     string
+			encrypted            []byte
+			isSecure, isHTTPOnly int
+			expiresUTC           int64
+		)
+		if err := rows.Scan(&host, &name, &encrypted, &path, &isSecure, &isHTTPOnly, &expiresUTC); err != nil {
+			return nil, fmt.Errorf("failed to scan cookie row: %w", err)
+		}
+
+		value, err := decryptChromeValue(encrypted, key)
+		if err != nil {
+			// Skip cookies we can't decrypt (e.g. stale key) rather than failing the whole import.
+			continue
+		}
+
+		out = append(out, rawCookie{
+			Domain:   host,
+			Name:     name,
+			Value:    value,
+			Path:     path,
+			Secure:   isSecure != 0,
+			HTTPOnly: isHTTPOnly != 0,
+			Expires:  chromeEpochToTime(expiresUTC),
+		})
+	}
+	return out, rows.Err()
+}
+
+// chromeEpochToTime converts Chrome's "microseconds since 1601-01-01" timestamp format.
+func chromeEpochToTime(v int64) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(v) * time.Microsecond)
+}
+
+func copyToTemp(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp("", "cookies-*.sqlite")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
@@ -0,0 +1,45 @@
+package cookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+)
+
+// chromeNonceLen is the AES-GCM nonce length Chromium uses for "v10"/"v11" values.
+const chromeNonceLen = 12
+
+// decryptChromeValue decrypts a value from Chromium's "encrypted_value" column.
+// Chromium prefixes ciphertext with "v10" or "v11" and encrypts it with AES-256-GCM
+// using the key returned by chromeDecryptionKey; unprefixed values are already
+// plaintext (very old Chrome versions, or a profile with encryption disabled).
+func decryptChromeValue(encrypted []byte, key []byte) (string, error) {
+	if len(encrypted) == 0 {
+		return "", nil
+	}
+	if !bytes.HasPrefix(encrypted, []byte("v10")) && !bytes.HasPrefix(encrypted, []byte("v11")) {
+		return string(encrypted), nil
+	}
+
+	ciphertext := encrypted[3:]
+	if len(ciphertext) < chromeNonceLen {
+		return "", fmt.Errorf("encrypted cookie value too short")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to init AES cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to init AES-GCM: %w", err)
+	}
+
+	nonce, rest := ciphertext[:chromeNonceLen], ciphertext[chromeNonceLen:]
+	plaintext, err := gcm.Open(nil, nonce, rest, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt cookie value: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,124 @@
+package cookies
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// firefoxProfilesDir returns the directory containing all of a user's Firefox profiles.
+func firefoxProfilesDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles"), nil
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "Mozilla", "Firefox", "Profiles"), nil
+	default:
+		return filepath.Join(home, ".mozilla", "firefox"), nil
+	}
+}
+
+// firefoxProfileDir resolves a profile argument to a profile directory. An empty
+// profile picks the most recently modified one, matching Firefox's own default
+// profile selection when only one profile exists.
+func firefoxProfileDir(profile string) (string, error) {
+	profilesDir, err := firefoxProfilesDir()
+	if err != nil {
+		return "", err
+	}
+
+	if profile != "" {
+		return filepath.Join(profilesDir, profile), nil
+	}
+
+	entries, err := os.ReadDir(profilesDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list Firefox profiles in %s: %w", profilesDir, err)
+	}
+
+	var candidates []os.DirEntry
+	for _, e := range entries {
+		if e.IsDir() {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no Firefox profiles found in %s", profilesDir)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		ii, _ := candidates[i].Info()
+		jj, _ := candidates[j].Info()
+		if ii == nil || jj == nil {
+			return false
+		}
+		return ii.ModTime().After(jj.ModTime())
+	})
+
+	return filepath.Join(profilesDir, candidates[0].Name()), nil
+}
+
+// readFirefoxCookies reads moz_cookies from a snapshot of the profile's cookies.sqlite.
+// Unlike Chromium, Firefox stores cookie values in plaintext, so no key material is
+// required.
+func readFirefoxCookies(profile string) ([]rawCookie, error) {
+	profileDir, err := firefoxProfileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	dbPath := filepath.Join(profileDir, "cookies.sqlite")
+
+	// Firefox keeps a write lock on the database while it is running, so work off a copy.
+	tmpPath, err := copyToTemp(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot cookie database %s: %w", dbPath, err)
+	}
+	defer os.Remove(tmpPath)
+
+	db, err := sql.Open("sqlite", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cookie database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT host, name, value, path, isSecure, isHttpOnly, expiry FROM moz_cookies`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query moz_cookies table: %w", err)
+	}
+	defer rows.Close()
+
+	var out []rawCookie
+	for rows.Next() {
+		var (
+			host, name, value, path string
+			isSecure, isHTTPOnly    int
+			expiry                  int64
+		)
+		if err := rows.Scan(&host, &name, &value, &path, &isSecure, &isHTTPOnly, &expiry); err != nil {
+			return nil, fmt.Errorf("failed to scan cookie row: %w", err)
+		}
+
+		out = append(out, rawCookie{
+			Domain:   host,
+			Name:     name,
+			Value:    value,
+			Path:     path,
+			Secure:   isSecure != 0,
+			HTTPOnly: isHTTPOnly != 0,
+			Expires:  time.Unix(expiry, 0),
+		})
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,210 @@
+package cookies
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"golang.org/x/net/publicsuffix"
+)
+
+// PersistentJar is an http.CookieJar that delegates matching to an in-memory
+// net/http/cookiejar.Jar (for the public-suffix-aware domain matching, Max-Age/Expires
+// handling, and per-domain;path;name keying that package already implements correctly)
+// and additionally persists every cookie it sees to disk, so a session an extractor
+// acquires survives a server restart. It's shared across the http.Client-based
+// extractors (Reddit, YouTube, the generic webpage extractor) via TransportFactory;
+// TwitterExtractor's browser session is persisted separately through auth.CredentialStore
+// and installed into the page via proto.NetworkCookieParam, since rod pages don't read
+// from an http.CookieJar.
+type PersistentJar struct {
+	path string
+
+	mu     sync.Mutex
+	jar    *cookiejar.Jar
+	byHost map[string][]*http.Cookie
+}
+
+// NewPersistentJar creates a PersistentJar backed by path, loading any cookies
+// previously saved there. A missing file is not an error: it just means no session has
+// been persisted yet.
+func NewPersistentJar(path string) (*PersistentJar, error) {
+	inner, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	saved, err := loadPersistedCookies(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persisted cookies from %s: %w", path, err)
+	}
+
+	j := &PersistentJar{path: path, jar: inner, byHost: make(map[string][]*http.Cookie)}
+	for host, cks := range saved {
+		inner.SetCookies(&url.URL{Scheme: "https", Host: host, Path: "/"}, cks)
+		j.byHost[host] = cks
+	}
+
+	return j, nil
+}
+
+// SetCookies implements http.CookieJar. In addition to delegating to the underlying
+// cookiejar.Jar, it records the cookies against u's host and persists the jar to disk,
+// so a just-acquired session (e.g. a successful login) isn't lost if the process exits
+// before anything else triggers a save.
+func (j *PersistentJar) SetCookies(u *url.URL, cks []*http.Cookie) {
+	if len(cks) == 0 {
+		return
+	}
+
+	j.mu.Lock()
+	j.jar.SetCookies(u, cks)
+	j.byHost[u.Hostname()] = mergeCookies(j.byHost[u.Hostname()], cks)
+	snapshot := make(map[string][]*http.Cookie, len(j.byHost))
+	for host, v := range j.byHost {
+		snapshot[host] = v
+	}
+	j.mu.Unlock()
+
+	if err := persistCookies(j.path, snapshot); err != nil {
+		slog.Warn("Failed to persist cookie jar", "path", j.path, "error", err)
+	}
+}
+
+// Cookies implements http.CookieJar.
+func (j *PersistentJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// mergeCookies replaces any cookie in existing that shares a name+path with one in
+// updated, appending genuinely new ones, mirroring cookiejar.Jar's own overwrite
+// semantics so the persisted snapshot doesn't accumulate stale duplicates of a cookie
+// whose value later changed.
+func mergeCookies(existing, updated []*http.Cookie) []*http.Cookie {
+	merged := make([]*http.Cookie, 0, len(existing)+len(updated))
+	merged = append(merged, existing...)
+	for _, u := range updated {
+		replaced := false
+		for i, e := range merged {
+			if e.Name == u.Name && e.Path == u.Path {
+				merged[i] = u
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, u)
+		}
+	}
+	return merged
+}
+
+// persistedCookie is the on-disk representation of one cookie, grouped by host in the
+// file (see persistCookies/loadPersistedCookies) the same way PersistentJar.byHost
+// groups them in memory.
+type persistedCookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Path     string    `json:"path"`
+	Domain   string    `json:"domain"`
+	Expires  time.Time `json:"expires"`
+	Secure   bool      `json:"secure"`
+	HTTPOnly bool      `json:"httpOnly"`
+	SameSite int       `json:"sameSite"`
+}
+
+func loadPersistedCookies(path string) (map[string][]*http.Cookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	var raw map[string][]persistedCookie
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	byHost := make(map[string][]*http.Cookie, len(raw))
+	for host, cks := range raw {
+		for _, pc := range cks {
+			byHost[host] = append(byHost[host], &http.Cookie{
+				Name:     pc.Name,
+				Value:    pc.Value,
+				Path:     pc.Path,
+				Domain:   pc.Domain,
+				Expires:  pc.Expires,
+				Secure:   pc.Secure,
+				HttpOnly: pc.HTTPOnly,
+				SameSite: http.SameSite(pc.SameSite),
+			})
+		}
+	}
+	return byHost, nil
+}
+
+// persistCookies atomically writes byHost to path: it writes to a temp file in the same
+// directory, fsyncs it, then renames over the destination, so a concurrent reader (or a
+// crash mid-write) never observes a partially written jar file.
+func persistCookies(path string, byHost map[string][]*http.Cookie) error {
+	raw := make(map[string][]persistedCookie, len(byHost))
+	for host, cks := range byHost {
+		for _, c := range cks {
+			raw[host] = append(raw[host], persistedCookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Path:     c.Path,
+				Domain:   c.Domain,
+				Expires:  c.Expires,
+				Secure:   c.Secure,
+				HTTPOnly: c.HttpOnly,
+				SameSite: int(c.SameSite),
+			})
+		}
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cookie jar: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cookie jar directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for cookie jar: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cookie jar: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to fsync cookie jar: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close cookie jar temp file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to install cookie jar at %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build darwin
+
+package cookies
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"os/exec"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// chromeSafeStorageService maps a Browser to the macOS Keychain service name Chromium
+// registers its AES-encryption password under.
+func chromeSafeStorageService(browser Browser) (service, account string) {
+	switch browser {
+	case BrowserChromium:
+		return "Chromium Safe Storage", "Chromium"
+	case BrowserBrave:
+		return "Brave Safe Storage", "Brave"
+	case BrowserEdge:
+		return "Microsoft Edge Safe Storage", "Microsoft Edge"
+	default:
+		return "Chrome Safe Storage", "Chrome"
+	}
+}
+
+// chromeDecryptionKey derives the AES key Chromium uses to encrypt cookie values on
+// macOS: the "Safe Storage" password from Keychain, stretched with PBKDF2-HMAC-SHA1
+// (salt "saltysalt", 1003 iterations, 16-byte key), per Chromium's os_crypt_mac.cc.
+func chromeDecryptionKey(browser Browser) ([]byte, error) {
+	service, account := chromeSafeStorageService(browser)
+
+	cmd := exec.Command("security", "find-generic-password", "-w", "-s", service, "-a", account)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to read %q from macOS Keychain: %w", service, err)
+	}
+
+	password := bytes.TrimRight(out.Bytes(), "\n")
+	return pbkdf2.Key(password, []byte("saltysalt"), 1003, 16, sha1.New), nil
+}
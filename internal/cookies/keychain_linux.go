@@ -0,0 +1,50 @@
+//go:build linux
+
+package cookies
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"os/exec"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// chromeLinuxFallbackPassword is the well-known constant Chromium falls back to on
+// Linux when no compatible keyring backend (GNOME Keyring / KWallet via libsecret) is
+// available ("BASIC_TEXT" encryption, see Chromium's os_crypt_linux.cc).
+const chromeLinuxFallbackPassword = "peanuts"
+
+func chromeSafeStorageLabel(browser Browser) string {
+	switch browser {
+	case BrowserChromium:
+		return "Chromium Safe Storage"
+	case BrowserBrave:
+		return "Brave Safe Storage"
+	case BrowserEdge:
+		return "Microsoft Edge Safe Storage"
+	default:
+		return "Chrome Safe Storage"
+	}
+}
+
+// chromeDecryptionKey derives the AES key Chromium uses to encrypt cookie values on
+// Linux: the "Safe Storage" password, looked up via libsecret's secret-tool (GNOME
+// Keyring/KWallet), falling back to the fixed "peanuts" password used when no
+// keyring is available. The password is stretched with PBKDF2-HMAC-SHA1
+// (salt "saltysalt", 1 iteration, 16-byte key), per Chromium's os_crypt_linux.cc.
+func chromeDecryptionKey(browser Browser) ([]byte, error) {
+	password := []byte(chromeLinuxFallbackPassword)
+
+	label := chromeSafeStorageLabel(browser)
+	cmd := exec.Command("secret-tool", "lookup", "application", label)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err == nil {
+		if p := bytes.TrimRight(out.Bytes(), "\n"); len(p) > 0 {
+			password = p
+		}
+	}
+
+	return pbkdf2.Key(password, []byte("saltysalt"), 1, 16, sha1.New), nil
+}
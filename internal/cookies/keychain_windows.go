@@ -0,0 +1,66 @@
+//go:build windows
+
+package cookies
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// localStatePath returns the path to the browser's "Local State" file, which stores
+// the DPAPI-protected AES key used to encrypt per-profile cookie values on Windows.
+func localStatePath(browser Browser) string {
+	return filepath.Join(os.Getenv("LOCALAPPDATA"), chromeAppDirName(browser), "User Data", "Local State")
+}
+
+// chromeDecryptionKey derives the AES key Chromium uses to encrypt cookie values on
+// Windows: the "os_crypt.encrypted_key" field in Local State, base64-decoded with its
+// "DPAPI" prefix stripped, then unwrapped with CryptUnprotectData.
+func chromeDecryptionKey(browser Browser) ([]byte, error) {
+	data, err := os.ReadFile(localStatePath(browser))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Local State: %w", err)
+	}
+
+	var localState struct {
+		OSCrypt struct {
+			EncryptedKey string `json:"encrypted_key"`
+		} `json:"os_crypt"`
+	}
+	if err := json.Unmarshal(data, &localState); err != nil {
+		return nil, fmt.Errorf("failed to parse Local State: %w", err)
+	}
+
+	encryptedKey, err := base64.StdEncoding.DecodeString(localState.OSCrypt.EncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted_key: %w", err)
+	}
+
+	encryptedKey = bytes.TrimPrefix(encryptedKey, []byte("DPAPI"))
+
+	return dpapiUnprotect(encryptedKey)
+}
+
+// dpapiUnprotect decrypts data that was encrypted with the Windows Data Protection
+// API, scoped to the current user, via CryptUnprotectData.
+func dpapiUnprotect(data []byte) ([]byte, error) {
+	in := windows.DataBlob{
+		Size: uint32(len(data)),
+		Data: &data[0],
+	}
+	var out windows.DataBlob
+
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, 0, &out); err != nil {
+		return nil, fmt.Errorf("CryptUnprotectData failed: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	return bytes.Clone(unsafe.Slice(out.Data, out.Size)), nil
+}
@@ -1,63 +1,146 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"net/url"
-	"strings"
+	"regexp"
+	"sort"
+	"sync"
 
 	"web-search-api-for-llms/internal/browser"
 	"web-search-api-for-llms/internal/config"
 	"web-search-api-for-llms/internal/logger"
 )
 
+// Default priorities for the built-in extractors. Plugins can register above or
+// below these to take precedence over (or yield to) the built-ins.
+const (
+	PriorityPDF     = 100
+	PriorityDefault = 50
+)
+
+// registeredExtractor pairs an Extractor with the regex used to decide whether it
+// should handle a given URL, plus the priority used to order matching attempts.
+type registeredExtractor struct {
+	Name      string
+	Pattern   *regexp.Regexp
+	Priority  int
+	Extractor Extractor
+}
+
 // Dispatcher is responsible for identifying the type of URL and calling the appropriate extractor.
 type Dispatcher struct {
 	Config             *config.AppConfig
 	BrowserPool        *browser.Pool
-	mainHTTPClient     *http.Client
-	extractors         map[string]Extractor
+	transportFactory   TransportFactory
+	extractors         []registeredExtractor
 	jsWebpageExtractor Extractor
+	webpageExtractor   Extractor
 }
 
-// NewDispatcher creates a new Dispatcher and initializes all concrete extractors.
-func NewDispatcher(appConfig *config.AppConfig, browserPool *browser.Pool, client *http.Client) *Dispatcher {
+// NewDispatcher creates a new Dispatcher and initializes all concrete extractors,
+// asking factory for each extractor's *http.Client at construction time so that
+// per-domain proxy and header overrides (AppConfig.DomainProxies / DomainHeaders) are
+// applied before any requests are made.
+func NewDispatcher(appConfig *config.AppConfig, browserPool *browser.Pool, factory TransportFactory) *Dispatcher {
 	d := &Dispatcher{
-		Config:             appConfig,
-		BrowserPool:        browserPool,
-		mainHTTPClient:     client,
-		extractors:         make(map[string]Extractor),
-		jsWebpageExtractor: NewJSWebpageExtractor(appConfig, browserPool, client),
+		Config:           appConfig,
+		BrowserPool:      browserPool,
+		transportFactory: factory,
 	}
 
-	ytExtractor, err := NewYouTubeExtractor(appConfig, client)
+	if jsExtractor, err := NewJSWebpageExtractor(appConfig, browserPool, factory); err != nil {
+		slog.Warn("Failed to initialize JSWebpageExtractor. JS-rendered webpage extraction will be unavailable.", "error", err)
+	} else {
+		d.jsWebpageExtractor = jsExtractor
+	}
+
+	if webExtractor, err := NewWebpageExtractor(appConfig, factory); err != nil {
+		slog.Warn("Failed to initialize WebpageExtractor. Webpage extraction will be unavailable.", "error", err)
+	} else {
+		d.webpageExtractor = webExtractor
+	}
+
+	if pdfExtractor, err := NewPDFExtractor(appConfig, factory); err != nil {
+		slog.Warn("Failed to initialize PDFExtractor. PDF URLs may not be processed.", "error", err)
+	} else {
+		d.RegisterExtractor("pdf", regexp.MustCompile(`(?i)\.pdf(?:[?#]|$)`), PriorityPDF, pdfExtractor)
+	}
+
+	ytExtractor, err := NewYouTubeExtractor(appConfig, factory)
 	if err != nil {
 		slog.Warn("Failed to initialize YouTubeExtractor. YouTube URLs may not be processed.", "error", err)
 	} else {
-		d.register("youtube.com", ytExtractor)
-		d.register("youtu.be", ytExtractor)
-		d.register("youtube-nocookie.com", ytExtractor)
-		d.register("music.youtube.com", ytExtractor)
-		d.register("gaming.youtube.com", ytExtractor)
-		d.register("tv.youtube.com", ytExtractor)
-		d.register("m.youtube.com", ytExtractor)
-	}
-
-	d.register("reddit.com", NewRedditExtractor(appConfig, client))
-	d.register("redd.it", NewRedditExtractor(appConfig, client))
-	d.register("twitter.com", NewTwitterExtractor(appConfig, browserPool, client))
-	d.register("x.com", NewTwitterExtractor(appConfig, browserPool, client))
-	d.register(".pdf", NewPDFExtractor(appConfig, client))
-	d.register("webpage", NewWebpageExtractor(appConfig, client))
+		d.RegisterExtractor("youtube", regexp.MustCompile(`(?i)://(?:[\w-]+\.)*(?:youtube(?:-nocookie)?\.com|youtu\.be)/`), PriorityDefault, ytExtractor)
+	}
+
+	if redditExtractor, err := NewRedditExtractor(appConfig, factory); err != nil {
+		slog.Warn("Failed to initialize RedditExtractor. Reddit URLs may not be processed.", "error", err)
+	} else {
+		d.RegisterExtractor("reddit", regexp.MustCompile(`(?i)://(?:[\w-]+\.)*(?:reddit\.com|redd\.it)/`), PriorityDefault, redditExtractor)
+	}
+
+	if twitterExtractor, err := NewTwitterContentExtractor(appConfig, browserPool, factory); err != nil {
+		slog.Warn("Failed to initialize Twitter extractor. Twitter/X URLs may not be processed.", "error", err)
+	} else {
+		d.RegisterExtractor("twitter", regexp.MustCompile(`(?i)://(?:[\w-]+\.)*(?:twitter\.com|x\.com)/`), PriorityDefault, twitterExtractor)
+	}
+
+	if appConfig.ExtractorPluginDir != "" {
+		if err := d.LoadPlugins(appConfig.ExtractorPluginDir); err != nil {
+			slog.Warn("Failed to load extractor plugins", "dir", appConfig.ExtractorPluginDir, "error", err)
+		}
+	}
 
 	return d
 }
 
-func (d *Dispatcher) register(domain string, extractor Extractor) {
-	if extractor != nil {
-		d.extractors[domain] = extractor
+// RegisterExtractor adds an extractor to the dispatch table. Extractors are tried in
+// descending priority order (ties broken by registration order) and the first whose
+// Pattern matches the full target URL handles the request. This lets higher-priority
+// registrations (e.g. a site-specific plugin, or the PDF path check) pre-empt more
+// general ones (e.g. a bare domain match) regardless of registration order.
+func (d *Dispatcher) RegisterExtractor(name string, pattern *regexp.Regexp, priority int, e Extractor) {
+	if e == nil || pattern == nil {
+		return
+	}
+	d.extractors = append(d.extractors, registeredExtractor{
+		Name:      name,
+		Pattern:   pattern,
+		Priority:  priority,
+		Extractor: e,
+	})
+	sort.SliceStable(d.extractors, func(i, j int) bool {
+		return d.extractors[i].Priority > d.extractors[j].Priority
+	})
+}
+
+// CacheGroup reports which of config.CacheGroups would handle targetURL, so callers can
+// route a content cache lookup/write to the same per-source-type namespace
+// (config.AppConfig.Caches) the eventual extraction would use, without having to
+// extract first. Falls back to "html" for anything a registered extractor's Pattern
+// doesn't match, since those fall through to the webpage/JS-webpage extractors.
+func (d *Dispatcher) CacheGroup(targetURL string) string {
+	for _, re := range d.extractors {
+		if re.Pattern.MatchString(targetURL) {
+			return re.Name
+		}
+	}
+	return "html"
+}
+
+// TwitterExtractor returns the Dispatcher's registered Twitter/X extractor, if one was
+// initialized successfully, for callers that need capabilities beyond the generic
+// Extractor interface (e.g. TwitterExtractor.Search, used by HandleTwitterStream).
+func (d *Dispatcher) TwitterExtractor() (*TwitterExtractor, bool) {
+	for _, re := range d.extractors {
+		if twitterExtractor, ok := re.Extractor.(*TwitterExtractor); ok {
+			return twitterExtractor, true
+		}
 	}
+	return nil, false
 }
 
 // DispatchAndExtract determines the URL type and calls the appropriate extractor.
@@ -69,41 +152,34 @@ func (d *Dispatcher) DispatchAndExtract(targetURL string, maxChars *int) (*Extra
 	result.Reset()
 	result.URL = targetURL
 
-	err := d.DispatchAndExtractWithContext(targetURL, "", maxChars, result)
+	err := d.DispatchAndExtractWithContext(context.Background(), targetURL, "", maxChars, result)
 	if err != nil {
 		result.ProcessedSuccessfully = false
 		result.Error = err.Error()
+		result.ErrorClass = ClassifyError(err)
 	}
 	return result, err
 }
 
 // DispatchAndExtractWithContext determines the URL type and calls the appropriate extractor with context.
-func (d *Dispatcher) DispatchAndExtractWithContext(targetURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
+// Cancelling ctx propagates to the chosen extractor, aborting in-flight HTTP calls and
+// headless browser pages.
+func (d *Dispatcher) DispatchAndExtractWithContext(ctx context.Context, targetURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("Dispatching URL", "url", targetURL, "endpoint", endpoint)
 
-	parsedURL, err := url.Parse(targetURL)
-	if err != nil {
+	if _, err := url.Parse(targetURL); err != nil {
 		wrappedErr := fmt.Errorf("failed to parse URL %s: %w", targetURL, err)
 		logger.LogError("Error: %v", wrappedErr)
 		result.Error = "Invalid URL format"
+		result.ErrorClass = ErrorClassPermanent
 		result.SourceType = "unknown"
 		return wrappedErr
 	}
 
-	hostname := strings.ToLower(parsedURL.Hostname())
-
-	// Check for PDF first since it's a path check
-	if strings.HasSuffix(strings.ToLower(parsedURL.Path), ".pdf") {
-		if extractor, ok := d.extractors[".pdf"]; ok {
-			slog.Debug("Dispatcher found match for PDF", "url", targetURL)
-			return extractor.Extract(targetURL, endpoint, maxChars, result)
-		}
-	}
-
-	for domain, extractor := range d.extractors {
-		if strings.Contains(hostname, domain) {
-			slog.Debug("Dispatcher found match", "url", targetURL, "domain", domain)
-			return extractor.Extract(targetURL, endpoint, maxChars, result)
+	for _, re := range d.extractors {
+		if re.Pattern.MatchString(targetURL) {
+			slog.Debug("Dispatcher found match", "url", targetURL, "extractor", re.Name, "priority", re.Priority)
+			return re.Extractor.Extract(ctx, targetURL, endpoint, maxChars, result)
 		}
 	}
 
@@ -112,7 +188,7 @@ func (d *Dispatcher) DispatchAndExtractWithContext(targetURL string, endpoint st
 	if endpoint == "/extract" {
 		slog.Debug("Using JS-enabled (headless) extractor", "url", targetURL, "endpoint", endpoint)
 		if d.jsWebpageExtractor != nil {
-			err := d.jsWebpageExtractor.Extract(targetURL, endpoint, maxChars, result)
+			err := d.jsWebpageExtractor.Extract(ctx, targetURL, endpoint, maxChars, result)
 			if err != nil {
 				return fmt.Errorf("js webpage extraction failed: %w", err)
 			}
@@ -123,14 +199,115 @@ func (d *Dispatcher) DispatchAndExtractWithContext(targetURL string, endpoint st
 
 	// Fallback to the standard webpage extractor for /search or when headless is not requested.
 	slog.Debug("Using standard webpage extractor", "url", targetURL, "endpoint", endpoint)
-	if extractor, ok := d.extractors["webpage"]; ok {
-		err := extractor.Extract(targetURL, endpoint, maxChars, result)
+	if d.webpageExtractor != nil {
+		err := d.webpageExtractor.Extract(ctx, targetURL, endpoint, maxChars, result)
 		if err != nil {
 			return fmt.Errorf("webpage extraction failed: %w", err)
 		}
 		return nil
 	}
-	return d.unimplementedOrFailedInitExtractor("webpage", result, d.extractors["webpage"] == nil)
+	return d.unimplementedOrFailedInitExtractor("webpage", result, d.webpageExtractor == nil)
+}
+
+// DispatchAndStream extracts each of urls concurrently and sends a pooled ExtractedResult
+// to out as soon as it completes, in completion order rather than input order; each
+// result's Index identifies its position in urls. DispatchAndStream closes out once every
+// extraction has finished or been abandoned, and returns after that. Cancelling ctx
+// propagates to every in-flight extractor, aborting its HTTP calls or browser pages.
+// Callers must return each result to ExtractedResultPool once they're done with it.
+func (d *Dispatcher) DispatchAndStream(ctx context.Context, urls []string, endpoint string, maxChars *int, out chan<- *ExtractedResult) {
+	defer close(out)
+
+	var wg sync.WaitGroup
+	for i, targetURL := range urls {
+		wg.Add(1)
+		go func(index int, u string) {
+			defer wg.Done()
+
+			result := ExtractedResultPool.Get().(*ExtractedResult)
+			result.Reset()
+			result.URL = u
+			result.Index = &index
+
+			err := d.DispatchAndExtractWithContext(ctx, u, endpoint, maxChars, result)
+			if err != nil {
+				result.ProcessedSuccessfully = false
+				result.Error = err.Error()
+				result.ErrorClass = ClassifyError(err)
+			}
+
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				result.Reset()
+				ExtractedResultPool.Put(result)
+			}
+		}(i, targetURL)
+	}
+
+	wg.Wait()
+}
+
+// ExtractBatch extracts each of urls using at most concurrency extractions in flight at
+// once, returning one ExtractedResult per url in the same order (unlike DispatchAndStream,
+// which delivers results in completion order over a channel). It exists for callers that
+// want a single bounded-concurrency call rather than managing a worker pool themselves — an
+// HTTP handler enforcing a request-scoped timeout, for instance, can pass r.Context() and
+// rely on every in-flight extraction, including headless browser pages, being aborted as
+// soon as ctx is done rather than running to each extractor's own timeout. Callers must
+// return each result to ExtractedResultPool once they're done with it.
+func (d *Dispatcher) ExtractBatch(ctx context.Context, urls []string, endpoint string, maxChars *int, concurrency int) []*ExtractedResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*ExtractedResult, len(urls))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, targetURL := range urls {
+		select {
+		case <-ctx.Done():
+			results[i] = cancelledResult(targetURL, i, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(index int, u string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := ExtractedResultPool.Get().(*ExtractedResult)
+			result.Reset()
+			result.URL = u
+			result.Index = &index
+
+			err := d.DispatchAndExtractWithContext(ctx, u, endpoint, maxChars, result)
+			if err != nil {
+				result.ProcessedSuccessfully = false
+				result.Error = err.Error()
+				result.ErrorClass = ClassifyError(err)
+			}
+			results[index] = result
+		}(i, targetURL)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// cancelledResult builds the ExtractedResult ExtractBatch returns, without ever starting an
+// extraction, for a url whose turn came up after ctx was already done.
+func cancelledResult(targetURL string, index int, err error) *ExtractedResult {
+	result := ExtractedResultPool.Get().(*ExtractedResult)
+	result.Reset()
+	result.URL = targetURL
+	result.Index = &index
+	result.ProcessedSuccessfully = false
+	result.Error = err.Error()
+	result.ErrorClass = ClassifyError(err)
+	return result
 }
 
 func (d *Dispatcher) unimplementedOrFailedInitExtractor(sourceType string, result *ExtractedResult, initFailed bool) error {
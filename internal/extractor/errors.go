@@ -1,9 +1,139 @@
 package extractor
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 // ErrNotPDF is returned when content sniffed is not a valid PDF.
 var ErrNotPDF = errors.New("content is not a valid PDF")
 
 // ErrUnsupportedContentType is returned when the content type is not supported for extraction.
-var ErrUnsupportedContentType = errors.New("unsupported content type")
\ No newline at end of file
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// ErrorClass categorizes an extraction failure so callers (the search pipeline, the
+// worker pool) can decide whether to retry, back off, skip-and-continue, or fail over
+// to the JS extractor, mirroring yt-dlp's ExtractorError/GeoRestrictedError/
+// UnavailableVideoError hierarchy.
+type ErrorClass string
+
+const (
+	// ErrorClassNone means the extraction succeeded, or failed in a way that hasn't
+	// been classified.
+	ErrorClassNone ErrorClass = ""
+	// ErrorClassGeoRestricted means the content is blocked in the server's region.
+	ErrorClassGeoRestricted ErrorClass = "geo_restricted"
+	// ErrorClassRateLimited means the source rate-limited the request; back off and retry.
+	ErrorClassRateLimited ErrorClass = "rate_limited"
+	// ErrorClassLoginRequired means the content is gated behind a login the server
+	// doesn't have a session for.
+	ErrorClassLoginRequired ErrorClass = "login_required"
+	// ErrorClassTransient means the failure is likely temporary and worth retrying.
+	ErrorClassTransient ErrorClass = "transient"
+	// ErrorClassPermanent means the failure will not succeed on retry.
+	ErrorClassPermanent ErrorClass = "permanent"
+	// ErrorClassUnavailable means the content itself no longer exists.
+	ErrorClassUnavailable ErrorClass = "unavailable"
+)
+
+// ErrGeoRestricted indicates the content is blocked in the server's region (HTTP 451,
+// or a site-specific "not available in your country" response).
+var ErrGeoRestricted = errors.New("content is geo-restricted")
+
+// ErrLoginRequired indicates the content is gated behind a login the server doesn't
+// have a session for (HTTP 401/403 on sites that require authentication).
+var ErrLoginRequired = errors.New("content requires login")
+
+// ErrTransient indicates a likely-temporary failure (HTTP 5xx, connection reset) that
+// is worth retrying.
+var ErrTransient = errors.New("transient extraction failure")
+
+// ErrPermanent indicates a failure that will not succeed on retry (e.g. HTTP 404,
+// unsupported content type).
+var ErrPermanent = errors.New("permanent extraction failure")
+
+// ErrUnavailable indicates the content itself no longer exists (deleted video, removed
+// post), distinct from a transient server error.
+var ErrUnavailable = errors.New("content unavailable")
+
+// ErrRateLimited indicates the extractor hit HTTP 429 (or a site-specific rate-limit
+// response) and should back off for RetryAfter before trying again.
+type ErrRateLimited struct {
+	RetryAfter time.Duration
+}
+
+func (e *ErrRateLimited) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// Is lets errors.Is(err, &ErrRateLimited{}) succeed regardless of RetryAfter, so
+// callers can check the class of error without caring about the backoff duration.
+func (e *ErrRateLimited) Is(target error) bool {
+	_, ok := target.(*ErrRateLimited)
+	return ok
+}
+
+// ClassifyHTTPStatus maps an HTTP response status code to the ErrorClass and typed
+// error an extractor should surface for it, per the retry/geo/rate-limit taxonomy
+// above. retryAfter is used for 429 responses and may be zero if the response didn't
+// include a Retry-After header. Returns ErrorClassNone, nil for status codes this
+// taxonomy doesn't recognize as a distinct class (the caller should fall back to a
+// generic error).
+func ClassifyHTTPStatus(statusCode int, retryAfter time.Duration) (ErrorClass, error) {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorClassLoginRequired, fmt.Errorf("%w: HTTP %d", ErrLoginRequired, statusCode)
+	case http.StatusTooManyRequests:
+		return ErrorClassRateLimited, &ErrRateLimited{RetryAfter: retryAfter}
+	case http.StatusUnavailableForLegalReasons:
+		return ErrorClassGeoRestricted, fmt.Errorf("%w: HTTP %d", ErrGeoRestricted, statusCode)
+	case http.StatusNotFound, http.StatusGone:
+		return ErrorClassUnavailable, fmt.Errorf("%w: HTTP %d", ErrUnavailable, statusCode)
+	case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return ErrorClassTransient, fmt.Errorf("%w: HTTP %d", ErrTransient, statusCode)
+	default:
+		return ErrorClassNone, nil
+	}
+}
+
+// retryAfter parses an HTTP response's Retry-After header (seconds, per RFC 9110) for
+// use with ClassifyHTTPStatus. Returns 0 if the header is absent or malformed.
+func retryAfter(resp *http.Response) time.Duration {
+	raw := resp.Header.Get("Retry-After")
+	if raw == "" {
+		return 0
+	}
+	seconds, err := time.ParseDuration(raw + "s")
+	if err != nil {
+		return 0
+	}
+	return seconds
+}
+
+// ClassifyError derives the ErrorClass of an error already returned by an extractor,
+// for callers (the dispatcher, the worker pool) that only have the error and need to
+// populate ExtractedResult.ErrorClass without re-deriving it from an HTTP response.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+	var rateLimited *ErrRateLimited
+	switch {
+	case errors.As(err, &rateLimited):
+		return ErrorClassRateLimited
+	case errors.Is(err, ErrGeoRestricted):
+		return ErrorClassGeoRestricted
+	case errors.Is(err, ErrLoginRequired):
+		return ErrorClassLoginRequired
+	case errors.Is(err, ErrUnavailable):
+		return ErrorClassUnavailable
+	case errors.Is(err, ErrTransient):
+		return ErrorClassTransient
+	case errors.Is(err, ErrPermanent), errors.Is(err, ErrNotPDF), errors.Is(err, ErrUnsupportedContentType):
+		return ErrorClassPermanent
+	default:
+		return ErrorClassNone
+	}
+}
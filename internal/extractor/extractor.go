@@ -1,10 +1,18 @@
 package extractor
 
 import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
 	nethttp "net/http" // Aliased http import
 	"sync"
 
+	"github.com/andybalholm/brotli"
+
 	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/readability"
 )
 
 // ExtractedResult represents the common structure for data extracted from any source.
@@ -15,6 +23,17 @@ type ExtractedResult struct {
 	ProcessedSuccessfully bool        `json:"processed_successfully"`
 	Data                  interface{} `json:"data,omitempty"` // Can be YouTubeData, RedditData, PDFData, WebpageData
 	Error                 string      `json:"error,omitempty"`
+	// ErrorClass categorizes Error (see ErrorClass) so callers can drive backoff,
+	// skip-and-continue, or failover decisions without parsing the error string.
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+	// Index is the position of URL in the originating request. It is only populated by
+	// streaming delivery (DispatchAndStream), where results arrive in completion order
+	// rather than input order; batch callers leave it nil.
+	Index *int `json:"index,omitempty"`
+	// NextPageToken is a source-specific pagination cursor (e.g. Reddit's listing "after"
+	// token) a caller can feed back into a follow-up request to fetch the next page. Only
+	// populated by extractors whose source exposes pagination; others leave it empty.
+	NextPageToken string `json:"next_page_token,omitempty"`
 }
 
 // ExtractedResultPool is a pool for reusing ExtractedResult objects to reduce allocations.
@@ -31,6 +50,8 @@ func (er *ExtractedResult) Reset() {
 	er.ProcessedSuccessfully = false
 	er.Data = nil
 	er.Error = ""
+	er.ErrorClass = ErrorClassNone
+	er.Index = nil
 }
 
 // Specific data structures for each source type
@@ -41,6 +62,33 @@ type YouTubeData struct {
 	ChannelName string        `json:"channel_name"`
 	Comments    []interface{} `json:"comments"`
 	Transcript  string        `json:"transcript"`
+	// TranscriptSegments is Transcript broken into timestamped captions, populated
+	// whenever the chosen transcript method returns per-caption timing (see
+	// YouTubeExtractor.extractTranscript); empty if only flat text was available.
+	TranscriptSegments []TranscriptSegment `json:"transcript_segments,omitempty"`
+	// CacheStatus is "HIT" if Title/ChannelName came from YouTubeExtractor's on-disk
+	// sub-resource cache (see internal/extractor/kvcache.go) rather than a fresh fetch,
+	// "MISS" otherwise, and omitted entirely when Config.YouTubeCacheDir leaves that cache
+	// disabled. Comments and Transcript are cached independently (different TTLs) and
+	// aren't reflected here.
+	CacheStatus string `json:"cache_status,omitempty"`
+	// Duration is the video's length in seconds, parsed from contentDetails.duration's
+	// ISO-8601 format (see parseISO8601Duration). 0 for a livestream or if unavailable.
+	Duration int64 `json:"duration_seconds,omitempty"`
+	// ViewCount and LikeCount come from statistics.viewCount/likeCount. LikeCount is 0 if
+	// the uploader has hidden it, indistinguishable from a genuinely unliked video.
+	ViewCount int64 `json:"view_count,omitempty"`
+	LikeCount int64 `json:"like_count,omitempty"`
+	// PublishedAt is snippet.publishedAt, an RFC3339 timestamp.
+	PublishedAt string `json:"published_at,omitempty"`
+	// Category is snippet.categoryId resolved through the static youtubeCategories table.
+	Category string `json:"category,omitempty"`
+	// Tags is snippet.tags, the uploader-supplied keyword list.
+	Tags []string `json:"tags,omitempty"`
+	// Chapters is parsed from the video's description using YouTube's own
+	// "<timestamp> <title>" chapter-marker convention (see parseChapters); nil if the
+	// description doesn't have at least two matching lines.
+	Chapters []VideoChapter `json:"chapters,omitempty"`
 }
 
 // YouTubePlaylistData represents extracted data from YouTube playlists
@@ -52,10 +100,10 @@ type YouTubePlaylistData struct {
 
 // RedditData represents extracted data from Reddit posts
 type RedditData struct {
-	PostTitle string        `json:"post_title"`
-	PostBody  string        `json:"post_body"`
-	Score     int           `json:"score"`
-	Author    string        `json:"author"`
+	PostTitle string          `json:"post_title"`
+	PostBody  string          `json:"post_body"`
+	Score     int             `json:"score"`
+	Author    string          `json:"author"`
 	Comments  []RedditComment `json:"comments,omitempty"`
 	Posts     []RedditPost    `json:"posts,omitempty"`
 }
@@ -67,8 +115,27 @@ type PDFData struct {
 
 // WebpageData represents extracted data from general web pages
 type WebpageData struct {
-	TextContent string `json:"text_content"`
+	TextContent string                 `json:"text_content"`
+	Title       string                 `json:"title,omitempty"`
+	Structured  *WebpageStructuredData `json:"structured,omitempty"`
+	// Article is the readability-cleaned article (see internal/readability), populated
+	// by JSWebpageExtractor when the request's readability.Mode isn't ModeRaw.
+	Article *readability.Article `json:"article,omitempty"`
+}
+
+// WebpageStructuredData holds metadata mined from a page's JSON-LD (schema.org) blocks
+// and OpenGraph/Twitter-card meta tags, in the spirit of yt-dlp's generic extractor.
+// Fields are populated on a best-effort basis; a site may supply only a subset.
+type WebpageStructuredData struct {
 	Title       string `json:"title,omitempty"`
+	Author      string `json:"author,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"`
+	Description string `json:"description,omitempty"`
+	ArticleBody string `json:"article_body,omitempty"`
+	VideoURL    string `json:"video_url,omitempty"`
+	// Type is the schema.org @type (e.g. "NewsArticle", "VideoObject", "Recipe") or,
+	// failing that, the OpenGraph og:type.
+	Type string `json:"type,omitempty"`
 }
 
 // TwitterData represents extracted data from Twitter/X posts
@@ -77,24 +144,74 @@ type TwitterData struct {
 	TweetAuthor   string           `json:"tweet_author"`
 	Comments      []TwitterComment `json:"comments"`
 	TotalComments int              `json:"total_comments"`
+	// Media holds the tweet's photo/video/animated_gif attachments, if any, with the
+	// best-bitrate variant already selected for video and animated_gif.
+	Media []TwitterMedia `json:"media,omitempty"`
+	// QuotedTweet is the tweet this one quotes, recursively decoded the same way, or nil if
+	// this tweet isn't a quote tweet.
+	QuotedTweet *TwitterData `json:"quoted_tweet,omitempty"`
+	// Entities holds the expanded form of the zero-width references (t.co links,
+	// @mentions, #hashtags) Twitter embeds in the tweet, for callers that want them
+	// structured rather than inlined into TweetContent.
+	Entities TwitterEntities `json:"entities"`
+}
+
+// TwitterMedia describes a single photo, video, or animated GIF attached to a tweet.
+type TwitterMedia struct {
+	// Type is "photo", "video", or "animated_gif".
+	Type string `json:"type"`
+	// URL is the best-quality photo URL, or the highest-bitrate MP4 variant URL for video
+	// and animated_gif attachments.
+	URL string `json:"url"`
+}
+
+// TwitterEntities holds the expanded, directly usable form of the references Twitter's API
+// embeds in a tweet as t.co links and structured annotations.
+type TwitterEntities struct {
+	URLs         []TwitterURLEntity `json:"urls,omitempty"`
+	UserMentions []TwitterMention   `json:"user_mentions,omitempty"`
+	Hashtags     []string           `json:"hashtags,omitempty"`
+}
+
+// TwitterURLEntity is a single t.co link expanded to its real destination.
+type TwitterURLEntity struct {
+	URL         string `json:"url"`
+	ExpandedURL string `json:"expanded_url"`
+	DisplayURL  string `json:"display_url"`
+}
+
+// TwitterMention is a single @mention referenced in a tweet's text.
+type TwitterMention struct {
+	Name       string `json:"name"`
+	ScreenName string `json:"screen_name"`
 }
 
 // TwitterComment represents a comment/reply on a Twitter/X post
 type TwitterComment struct {
-	Author    string `json:"author"`
-	Username  string `json:"username"`
-	Content   string `json:"content"`
-	Timestamp string `json:"timestamp"`
-	Likes     string `json:"likes"`
-	Replies   string `json:"replies"`
-	Retweets  string `json:"retweets"`
+	// ID and InReplyToStatusID are the comment's own tweet id and the id of the tweet it
+	// replies to. They're used to reconstruct Thread below and aren't otherwise meaningful
+	// to a RAG consumer, so both are omitted from the JSON when empty.
+	ID                string `json:"id,omitempty"`
+	InReplyToStatusID string `json:"in_reply_to_status_id,omitempty"`
+	Author            string `json:"author"`
+	Username          string `json:"username"`
+	Content           string `json:"content"`
+	Timestamp         string `json:"timestamp"`
+	Likes             string `json:"likes"`
+	Replies           string `json:"replies"`
+	Retweets          string `json:"retweets"`
+	// Thread holds this comment's own replies, nested to TwitterMaxThreadDepth levels, so
+	// that callers can walk the actual conversation graph instead of a flat list.
+	Thread []TwitterComment `json:"thread,omitempty"`
 }
 
 // ContentExtractor defines the interface for content extractors.
 // This interface is kept small and focused on a single responsibility.
 type ContentExtractor interface {
-	// Extract processes a URL and returns extracted content or an error
-	Extract(url string, endpoint string, maxChars *int) (*ExtractedResult, error)
+	// Extract processes a URL, populating the pooled result in place. Cancelling ctx
+	// (e.g. a client disconnecting from a streaming endpoint) should abort any
+	// in-flight HTTP calls or headless browser pages as soon as possible.
+	Extract(ctx context.Context, url string, endpoint string, maxChars *int, result *ExtractedResult) error
 }
 
 // URLClassifier defines the interface for URL classification
@@ -127,12 +244,56 @@ type BaseExtractor struct {
 	HTTPClient *nethttp.Client
 }
 
-// NewBaseExtractor creates a common base for extractors
-func NewBaseExtractor(cfg *config.AppConfig, client *nethttp.Client) BaseExtractor {
-	return BaseExtractor{
-		Config:     cfg,
-		HTTPClient: client,
+// NewBaseExtractor creates a common base for extractors, resolving an *http.Client for
+// domain from factory (proxy and header overrides, per AppConfig.DomainProxies /
+// AppConfig.DomainHeaders). Pass "" for domain to get the factory's Default client, for
+// extractors (like WebpageExtractor) that see arbitrary hostnames only at request time.
+func NewBaseExtractor(cfg *config.AppConfig, factory TransportFactory, domain string) (BaseExtractor, error) {
+	if domain == "" {
+		return BaseExtractor{Config: cfg, HTTPClient: factory.Default()}, nil
+	}
+	client, err := factory.ClientFor(domain)
+	if err != nil {
+		return BaseExtractor{}, fmt.Errorf("failed to build HTTP client for domain %q: %w", domain, err)
+	}
+	return BaseExtractor{Config: cfg, HTTPClient: client}, nil
+}
+
+// DoDecoded performs req (after setting an Accept-Encoding header advertising gzip,
+// deflate, and br) and, on success, wraps the response body in the io.Reader that
+// undoes whatever Content-Encoding the server chose, so callers always read decoded
+// bytes. Callers must still close the returned response's Body; wrapping it does not
+// change that.
+func (be *BaseExtractor) DoDecoded(req *nethttp.Request) (*nethttp.Response, error) {
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+
+	resp, err := be.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
+
+	switch resp.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		resp.Body = readCloser{Reader: gz, Closer: resp.Body}
+	case "deflate":
+		resp.Body = readCloser{Reader: flate.NewReader(resp.Body), Closer: resp.Body}
+	case "br":
+		resp.Body = readCloser{Reader: brotli.NewReader(resp.Body), Closer: resp.Body}
+	}
+
+	return resp, nil
+}
+
+// readCloser pairs a decoding Reader with the underlying response body's Closer, so
+// closing it releases the original connection even though reads go through the decoder.
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
 // GetConfig implements the Configurable interface
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"strings"
 	"time"
 
@@ -13,6 +12,8 @@ import (
 
 	"web-search-api-for-llms/internal/browser"
 	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/readability"
+	"web-search-api-for-llms/internal/warc"
 )
 
 // JSWebpageExtractor implements the Extractor interface for general web pages that require JavaScript rendering.
@@ -22,19 +23,23 @@ type JSWebpageExtractor struct {
 }
 
 // NewJSWebpageExtractor creates a new JSWebpageExtractor.
-func NewJSWebpageExtractor(appConfig *config.AppConfig, browserPool *browser.Pool, client *http.Client) *JSWebpageExtractor {
+func NewJSWebpageExtractor(appConfig *config.AppConfig, browserPool *browser.Pool, factory TransportFactory) (*JSWebpageExtractor, error) {
+	base, err := NewBaseExtractor(appConfig, factory, "")
+	if err != nil {
+		return nil, err
+	}
 	return &JSWebpageExtractor{
-		BaseExtractor: NewBaseExtractor(appConfig, client),
+		BaseExtractor: base,
 		BrowserPool:   browserPool,
-	}
+	}, nil
 }
 
 // Extract uses a headless browser (chromedp) to get the visible text from a URL.
-func (e *JSWebpageExtractor) Extract(url string, endpoint string, maxChars *int, result *ExtractedResult) error {
+func (e *JSWebpageExtractor) Extract(parentCtx context.Context, url string, endpoint string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("JSWebpageExtractor: Starting extraction", "url", url)
 	result.SourceType = "webpage_js"
 
-	ctx, cancel := context.WithTimeout(context.Background(), e.Config.JSExtractionTimeout)
+	ctx, cancel := context.WithTimeout(parentCtx, e.Config.JSExtractionTimeout)
 	defer cancel()
 
 	browserInstance := e.BrowserPool.Get()
@@ -46,20 +51,39 @@ func (e *JSWebpageExtractor) Extract(url string, endpoint string, maxChars *int,
 	}
 	defer page.MustClose()
 
+	// If the caller requested a WARC archive, capture every Document/XHR/Fetch response
+	// this page makes by routing it through our own HTTP client (which the hijack
+	// response is then fulfilled from) instead of letting Chrome fetch it directly, so
+	// the archivingRoundTripper can record a resource record for it. Otherwise (the
+	// common case) Chrome fetches these requests itself, for speed and compatibility.
+	_, archiving := warc.FromContext(ctx)
+
 	// Intercept and block non-essential requests
 	router := page.HijackRequests()
 	defer router.Stop()
 
-	router.MustAdd("*", func(ctx *rod.Hijack) {
+	router.MustAdd("*", func(hijack *rod.Hijack) {
 		// Allow only document and data-fetching requests
-		switch ctx.Request.Type() {
+		switch hijack.Request.Type() {
 		case proto.NetworkResourceTypeDocument,
 			proto.NetworkResourceTypeXHR,
 			proto.NetworkResourceTypeFetch:
-			ctx.ContinueRequest(&proto.FetchContinueRequest{})
+			if archiving {
+				// e.HTTPClient's transport includes archivingRoundTripper, which writes the
+				// request/response WARC records itself once SetContext threads warcWriter
+				// through; LoadResponse then fulfills the hijacked request from that
+				// response so Chrome still renders it normally.
+				hijack.Request.SetContext(ctx)
+				if err := hijack.LoadResponse(e.HTTPClient, true); err != nil {
+					slog.Warn("JSWebpageExtractor: failed to load response for archiving, failing request", "url", hijack.Request.URL().String(), "error", err)
+					hijack.Response.Fail(proto.NetworkErrorReasonFailed)
+				}
+				return
+			}
+			hijack.ContinueRequest(&proto.FetchContinueRequest{})
 		default:
 			// Block everything else: images, css, fonts, media, etc.
-			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			hijack.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
 		}
 	})
 	go router.Run()
@@ -101,18 +125,53 @@ func (e *JSWebpageExtractor) Extract(url string, endpoint string, maxChars *int,
 	}
 	textContent := eval.Value.Str()
 
-
 	slog.Info("JSWebpageExtractor: Finished scraping", "url", url, "title", title, "text_length", len(textContent))
 
-	// Truncate if necessary
-	if maxChars != nil && len(textContent) > *maxChars {
-		textContent = textContent[:*maxChars]
+	// Run the readability pass if the caller asked for anything beyond the raw
+	// innerText. It must never fail the whole extraction: on any error, log a warning
+	// and fall back to the raw text path.
+	var article *readability.Article
+	mode := readability.FromContext(ctx)
+	if mode != readability.ModeRaw {
+		outerHTMLEval, err := page.Context(ctx).Eval(`() => document.documentElement.outerHTML`)
+		if err != nil {
+			slog.Warn("JSWebpageExtractor: failed to get outerHTML for readability pass, falling back to raw text", "url", url, "error", err)
+		} else if parsed, err := readability.Parse(outerHTMLEval.Value.Str(), url); err != nil {
+			slog.Warn("JSWebpageExtractor: readability parse failed, falling back to raw text", "url", url, "error", err)
+		} else {
+			article = parsed
+		}
+	}
+
+	// In "readability" mode the cleaned article becomes the primary text; in "raw" and
+	// "both" modes the primary text stays the raw innerText (mode "both" also carries
+	// the article alongside it).
+	primaryText := textContent
+	if mode == readability.ModeReadability && article != nil {
+		primaryText = article.TextContent
+	}
+
+	// Truncate whichever text field is chosen (plus the article's own fields, so "both"
+	// mode doesn't ship an untruncated article alongside a truncated raw text).
+	if maxChars != nil {
+		if len(primaryText) > *maxChars {
+			primaryText = primaryText[:*maxChars]
+		}
+		if article != nil {
+			if len(article.TextContent) > *maxChars {
+				article.TextContent = article.TextContent[:*maxChars]
+			}
+			if len(article.Content) > *maxChars {
+				article.Content = article.Content[:*maxChars]
+			}
+		}
 	}
 
 	result.ProcessedSuccessfully = true
 	result.Data = WebpageData{
-		TextContent: strings.TrimSpace(textContent),
+		TextContent: strings.TrimSpace(primaryText),
 		Title:       title,
+		Article:     article,
 	}
 
 	return nil
@@ -0,0 +1,119 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// kvCache is a small on-disk, content-addressed store that YouTubeExtractor uses to
+// memoize per-video sub-resources (metadata, comments, transcripts) across requests and
+// process restarts, keyed by an arbitrary "kind" (e.g. "meta", "comments", "transcript")
+// plus a caller-supplied key (usually the video/playlist ID, optionally suffixed with a
+// language or page token). This lives in the extractor package rather than reusing
+// internal/cache.CacheManager because internal/cache already imports this package for
+// ExtractedResult, so the dependency can't run the other way without a cycle; the on-disk
+// layout otherwise mirrors internal/cache.FileCache (gzip JSON plus a ".meta" sidecar
+// recording the write time).
+type kvCache struct {
+	dir string
+}
+
+// newKVCache creates dir (and its kind subdirectories are created lazily on first write)
+// and returns a kvCache rooted at dir. An empty dir disables caching entirely; callers
+// should check for a nil *kvCache rather than constructing one in that case.
+func newKVCache(dir string) (*kvCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("kv cache requires a non-empty dir")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create kv cache directory %s: %w", dir, err)
+	}
+	return &kvCache{dir: dir}, nil
+}
+
+func (c *kvCache) paths(kind, key string) (dataPath, metaPath string) {
+	sum := sha1.Sum([]byte(key))
+	hexSum := hex.EncodeToString(sum[:])
+	shard := filepath.Join(c.dir, kind, hexSum[:2])
+	return filepath.Join(shard, hexSum+".json.gz"), filepath.Join(shard, hexSum+".meta")
+}
+
+// expired reports whether the entry whose sidecar lives at metaPath has outlived ttl,
+// treating a missing or unreadable sidecar as expired. ttl <= 0 means the entry never
+// expires.
+func expiredKVEntry(metaPath string, ttl time.Duration) bool {
+	if ttl <= 0 {
+		return false
+	}
+	raw, err := os.ReadFile(metaPath)
+	if err != nil {
+		return true
+	}
+	writtenAt, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return true
+	}
+	return time.Since(writtenAt) > ttl
+}
+
+// get decodes a previously-set value of kind/key into out, reporting false on a miss,
+// expired entry, or decode failure (a corrupt entry is treated as a miss rather than an
+// error so callers just fall through to re-fetching).
+func (c *kvCache) get(kind, key string, ttl time.Duration, out interface{}) bool {
+	dataPath, metaPath := c.paths(kind, key)
+	if expiredKVEntry(metaPath, ttl) {
+		return false
+	}
+	f, err := os.Open(dataPath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return false
+	}
+	defer gz.Close()
+	if err := json.NewDecoder(gz).Decode(out); err != nil {
+		return false
+	}
+	return true
+}
+
+// set gzip-compresses value as JSON and writes it under kind/key, alongside a meta
+// sidecar recording the write time so a later get can enforce its ttl. Failures are
+// logged and otherwise swallowed: a caching layer must never fail the request it's
+// memoizing for.
+func (c *kvCache) set(kind, key string, value interface{}) {
+	dataPath, metaPath := c.paths(kind, key)
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		slog.Warn("kvCache: failed to create shard directory", "path", filepath.Dir(dataPath), "error", err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	if err := json.NewEncoder(gz).Encode(value); err != nil {
+		slog.Warn("kvCache: failed to encode entry", "kind", kind, "key", key, "error", err)
+		return
+	}
+	if err := gz.Close(); err != nil {
+		slog.Warn("kvCache: failed to flush gzip writer", "kind", kind, "key", key, "error", err)
+		return
+	}
+	if err := os.WriteFile(dataPath, buf.Bytes(), 0644); err != nil {
+		slog.Warn("kvCache: failed to write entry", "path", dataPath, "error", err)
+		return
+	}
+	if err := os.WriteFile(metaPath, []byte(time.Now().UTC().Format(time.RFC3339Nano)), 0644); err != nil {
+		slog.Warn("kvCache: failed to write meta sidecar", "path", metaPath, "error", err)
+	}
+}
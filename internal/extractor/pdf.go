@@ -2,13 +2,20 @@ package extractor
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/dslipak/pdf"
+
 	"web-search-api-for-llms/internal/config"
 	"web-search-api-for-llms/internal/useragent"
 )
@@ -19,25 +26,29 @@ type PDFExtractor struct {
 }
 
 // NewPDFExtractor creates a new PDFExtractor.
-func NewPDFExtractor(appConfig *config.AppConfig, client *http.Client) *PDFExtractor {
-	return &PDFExtractor{
-		BaseExtractor: NewBaseExtractor(appConfig, client),
+func NewPDFExtractor(appConfig *config.AppConfig, factory TransportFactory) (*PDFExtractor, error) {
+	base, err := NewBaseExtractor(appConfig, factory, "")
+	if err != nil {
+		return nil, err
 	}
+	return &PDFExtractor{
+		BaseExtractor: base,
+	}, nil
 }
 
 // Extract downloads a PDF from a URL and extracts its text content using a native Go library.
-func (e *PDFExtractor) Extract(url string, endpoint string, maxChars *int, result *ExtractedResult) error {
+func (e *PDFExtractor) Extract(ctx context.Context, url string, endpoint string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("PDFExtractor: Starting extraction", "url", url)
 	result.SourceType = "pdf"
 
 	// 1. Download the content
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request for %s: %w", url, err)
 	}
 	req.Header.Set("User-Agent", useragent.Random())
 
-	resp, err := e.HTTPClient.Do(req)
+	resp, err := e.DoDecoded(req)
 	if err != nil {
 		return fmt.Errorf("failed to download content from %s: %w", url, err)
 	}
@@ -48,17 +59,26 @@ func (e *PDFExtractor) Extract(url string, endpoint string, maxChars *int, resul
 	}()
 
 	if resp.StatusCode != http.StatusOK {
+		if _, classifiedErr := ClassifyHTTPStatus(resp.StatusCode, retryAfter(resp)); classifiedErr != nil {
+			return classifiedErr
+		}
 		return fmt.Errorf("download failed for %s with status %s", url, resp.Status)
 	}
 
-	// Add this check
+	// resp.ContentLength (if any) is the compressed transfer size, not the size after
+	// DoDecoded's gzip/deflate/br decoding, so the 20 MB cap is enforced against the
+	// decoded bytes actually read instead.
 	const maxPDFSize = 20 * 1024 * 1024 // 20 MB
-	if resp.ContentLength > maxPDFSize {
-		return fmt.Errorf("PDF file size (%d bytes) exceeds the limit of %d bytes", resp.ContentLength, maxPDFSize)
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPDFSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read content from %s: %w", url, err)
+	}
+	if len(data) > maxPDFSize {
+		return fmt.Errorf("PDF file size exceeds the limit of %d bytes", maxPDFSize)
 	}
 
 	// 2. Process the response body as a stream
-	textContent, err := e.extractTextFromPDF(resp.Body)
+	textContent, err := e.extractTextFromPDF(ctx, bytes.NewReader(data))
 	if err != nil {
 		// Check if the error is due to non-PDF content
 		if err == ErrNotPDF {
@@ -67,6 +87,17 @@ func (e *PDFExtractor) Extract(url string, endpoint string, maxChars *int, resul
 		return fmt.Errorf("pdf stream processing failed for %s: %w", url, err)
 	}
 
+	// 2b. Fall back to OCR for scanned/image-only PDFs, where the text layer is empty
+	// or too sparse to be useful.
+	if e.Config.PDFOcrEnabled && len(strings.TrimSpace(textContent)) < e.Config.PDFOcrMinChars {
+		ocrText, ocrErr := e.extractTextViaOCR(ctx, data, url)
+		if ocrErr != nil {
+			slog.Warn("PDFExtractor: OCR fallback failed", "url", url, "error", ocrErr)
+		} else if len(strings.TrimSpace(ocrText)) > len(strings.TrimSpace(textContent)) {
+			textContent = ocrText
+		}
+	}
+
 	// 3. Truncate content if necessary
 	if maxChars != nil && len(textContent) > *maxChars {
 		textContent = textContent[:*maxChars]
@@ -82,8 +113,10 @@ func (e *PDFExtractor) Extract(url string, endpoint string, maxChars *int, resul
 	return nil
 }
 
-// extractTextFromPDF extracts text from PDF content using the pdftotext CLI tool.
-func (e *PDFExtractor) extractTextFromPDF(reader io.Reader) (string, error) {
+// extractTextFromPDF extracts text from PDF content, using pdftotext, the native Go
+// decoder, or both depending on e.Config.PDFExtractorBackend (see extractTextFromPDFCLI
+// and extractTextFromPDFNative).
+func (e *PDFExtractor) extractTextFromPDF(ctx context.Context, reader io.Reader) (string, error) {
 	// First, check the file type to ensure we're dealing with a PDF.
 	header := make([]byte, 512)
 	n, err := io.ReadFull(reader, header)
@@ -91,19 +124,39 @@ func (e *PDFExtractor) extractTextFromPDF(reader io.Reader) (string, error) {
 		return "", fmt.Errorf("failed to read header: %w", err)
 	}
 
-	// Combine the header with the rest of the reader for the CLI tool.
+	// Combine the header with the rest of the reader for whichever backend runs.
 	combinedReader := io.MultiReader(bytes.NewReader(header[:n]), reader)
 
 	if e.detectFileType(header[:n]) != "pdf" {
 		return "", ErrNotPDF
 	}
 
-	return e.extractTextFromPDFCLI(combinedReader)
+	switch e.Config.PDFExtractorBackend {
+	case config.PDFExtractorNative:
+		return e.extractTextFromPDFNative(combinedReader)
+	case config.PDFExtractorCLI:
+		return e.extractTextFromPDFCLI(ctx, combinedReader)
+	default: // config.PDFExtractorAuto
+		data, err := io.ReadAll(combinedReader)
+		if err != nil {
+			return "", fmt.Errorf("failed to buffer PDF content: %w", err)
+		}
+		text, err := e.extractTextFromPDFCLI(ctx, bytes.NewReader(data))
+		if err != nil {
+			if _, lookErr := exec.LookPath("pdftotext"); lookErr != nil {
+				slog.Warn("PDFExtractor: pdftotext not found, falling back to native decoder", "error", err)
+			} else {
+				slog.Warn("PDFExtractor: pdftotext failed, falling back to native decoder", "error", err)
+			}
+			return e.extractTextFromPDFNative(bytes.NewReader(data))
+		}
+		return text, nil
+	}
 }
 
 // extractTextFromPDFCLI calls the `pdftotext` command-line tool.
-func (e *PDFExtractor) extractTextFromPDFCLI(reader io.Reader) (string, error) {
-	cmd := exec.Command("pdftotext", "-", "-") // Read from stdin, write to stdout
+func (e *PDFExtractor) extractTextFromPDFCLI(ctx context.Context, reader io.Reader) (string, error) {
+	cmd := exec.CommandContext(ctx, "pdftotext", "-", "-") // Read from stdin, write to stdout
 	cmd.Stdin = reader
 
 	var out bytes.Buffer
@@ -118,6 +171,115 @@ func (e *PDFExtractor) extractTextFromPDFCLI(reader io.Reader) (string, error) {
 	return out.String(), nil
 }
 
+// extractTextFromPDFNative decodes reader with a pure-Go PDF parser instead of shelling
+// out to pdftotext, for containers and platforms without poppler installed. The library
+// needs random access, so reader is buffered into memory first; callers already cap PDF
+// downloads at maxPDFSize, so this is bounded.
+func (e *PDFExtractor) extractTextFromPDFNative(reader io.Reader) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to buffer PDF content: %w", err)
+	}
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("native PDF decoder failed: %w", err)
+	}
+
+	textReader, err := r.GetPlainText()
+	if err != nil {
+		return "", fmt.Errorf("native PDF decoder failed to extract text: %w", err)
+	}
+
+	var out bytes.Buffer
+	if _, err := out.ReadFrom(textReader); err != nil {
+		return "", fmt.Errorf("native PDF decoder failed to read text: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// extractTextViaOCR rasterizes pdfData page-by-page with pdftoppm and runs tesseract on
+// each page image, for scans and image-only PDFs that have no usable text layer. It is
+// only invoked when PDFOcrEnabled is set and the text already extracted falls below
+// PDFOcrMinChars. Rasterization and OCR for each page share a single PDFOcrPageTimeout
+// deadline; a page that misses it is skipped (logged, not fatal) so one slow page
+// doesn't sink the whole document. Pages beyond PDFOcrMaxPages are not processed.
+func (e *PDFExtractor) extractTextViaOCR(ctx context.Context, pdfData []byte, url string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "pdf-ocr-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create OCR temp dir: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			slog.Warn("PDFExtractor: failed to clean up OCR temp dir", "dir", tmpDir, "error", err)
+		}
+	}()
+
+	pdfPath := filepath.Join(tmpDir, "input.pdf")
+	if err := os.WriteFile(pdfPath, pdfData, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write PDF for OCR: %w", err)
+	}
+
+	r, err := pdf.NewReader(bytes.NewReader(pdfData), int64(len(pdfData)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF for OCR page count: %w", err)
+	}
+
+	numPages := r.NumPage()
+	if numPages > e.Config.PDFOcrMaxPages {
+		slog.Warn("PDFExtractor: capping OCR pages", "url", url, "totalPages", numPages, "maxPages", e.Config.PDFOcrMaxPages)
+		numPages = e.Config.PDFOcrMaxPages
+	}
+
+	var out bytes.Buffer
+	for page := 1; page <= numPages; page++ {
+		pageCtx, cancel := context.WithTimeout(ctx, e.Config.PDFOcrPageTimeout)
+		text, err := e.ocrPage(pageCtx, tmpDir, pdfPath, page)
+		cancel()
+		if err != nil {
+			slog.Warn("PDFExtractor: OCR skipped page", "url", url, "page", page, "error", err)
+			continue
+		}
+		if out.Len() > 0 {
+			out.WriteString("\n\n")
+		}
+		out.WriteString(text)
+	}
+
+	return out.String(), nil
+}
+
+// ocrPage rasterizes a single page of pdfPath to a PNG with pdftoppm and runs tesseract
+// on it, returning the recognized text.
+func (e *PDFExtractor) ocrPage(ctx context.Context, tmpDir, pdfPath string, page int) (string, error) {
+	imgPrefix := filepath.Join(tmpDir, "page-"+strconv.Itoa(page))
+
+	rasterizeCmd := exec.CommandContext(ctx, "pdftoppm", "-png", "-r", "150", "-f", strconv.Itoa(page), "-l", strconv.Itoa(page), pdfPath, imgPrefix)
+	var rasterizeStderr bytes.Buffer
+	rasterizeCmd.Stderr = &rasterizeStderr
+	if err := rasterizeCmd.Run(); err != nil {
+		return "", fmt.Errorf("pdftoppm failed: %s, err: %w", rasterizeStderr.String(), err)
+	}
+
+	matches, err := filepath.Glob(imgPrefix + "*.png")
+	if err != nil || len(matches) == 0 {
+		return "", fmt.Errorf("pdftoppm did not produce a page image")
+	}
+	sort.Strings(matches)
+	imgPath := matches[0]
+
+	var tesseractOut, tesseractStderr bytes.Buffer
+	tesseractCmd := exec.CommandContext(ctx, "tesseract", imgPath, "stdout")
+	tesseractCmd.Stdout = &tesseractOut
+	tesseractCmd.Stderr = &tesseractStderr
+	if err := tesseractCmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract failed: %s, err: %w", tesseractStderr.String(), err)
+	}
+
+	return tesseractOut.String(), nil
+}
+
 // detectFileType examines file header to determine actual file type
 func (e *PDFExtractor) detectFileType(data []byte) string {
 	headerStr := string(data)
@@ -0,0 +1,60 @@
+//go:build linux || darwin
+
+package extractor
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"plugin"
+	"regexp"
+	"strings"
+)
+
+// PluginExtractor is the symbol a Go plugin must export (as a package-level variable
+// named "PluginExtractor") to be picked up by LoadPlugins.
+type PluginExtractor struct {
+	Name     string
+	Pattern  *regexp.Regexp
+	Priority int
+	Extractor Extractor
+}
+
+// LoadPlugins opens every *.so file in dir and registers the extractor each one
+// exports, so deployments can add site-specific extractors without recompiling the
+// server. Plugins are expected to export a package-level variable named
+// "PluginExtractor" of type *extractor.PluginExtractor.
+func (d *Dispatcher) LoadPlugins(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read extractor plugin directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".so") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open extractor plugin %s: %w", path, err)
+		}
+
+		sym, err := p.Lookup("PluginExtractor")
+		if err != nil {
+			return fmt.Errorf("plugin %s does not export PluginExtractor: %w", path, err)
+		}
+
+		pe, ok := sym.(*PluginExtractor)
+		if !ok {
+			return fmt.Errorf("plugin %s: PluginExtractor has unexpected type %T", path, sym)
+		}
+
+		d.RegisterExtractor(pe.Name, pe.Pattern, pe.Priority, pe.Extractor)
+		slog.Info("Loaded extractor plugin", "path", path, "name", pe.Name, "priority", pe.Priority)
+	}
+
+	return nil
+}
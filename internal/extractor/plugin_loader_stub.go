@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package extractor
+
+import "fmt"
+
+// LoadPlugins is unavailable on this platform because Go's plugin package only
+// supports linux and darwin.
+func (d *Dispatcher) LoadPlugins(dir string) error {
+	return fmt.Errorf("extractor plugins are not supported on this platform")
+}
@@ -1,14 +1,17 @@
 package extractor
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"web-search-api-for-llms/internal/config"
 	"web-search-api-for-llms/internal/logger"
@@ -18,24 +21,81 @@ import (
 // RedditExtractor implements the Extractor interface for Reddit URLs.
 type RedditExtractor struct {
 	BaseExtractor
-	accessToken string
-	tokenExpiry time.Time
-	tokenMutex  sync.Mutex // Added to protect token access
+	// APIClient is authenticated against oauth.reddit.com via redditTransport; it's nil
+	// when no Reddit API credentials are configured. BaseExtractor.HTTPClient stays
+	// unauthenticated (built without a tokenSource) for the .json fallback, so that path
+	// can never leak a bearer token.
+	APIClient   *http.Client
+	tokenSource *redditTokenSource
+	// rateLimiter is shared across fetchViaAPI, fetchSubredditPosts, fetchUserPosts, and
+	// fetchViaJSON so they all honor the same x-ratelimit-* budget and retry schedule.
+	rateLimiter *redditRateLimiter
 }
 
 // NewRedditExtractor creates a new RedditExtractor.
-func NewRedditExtractor(appConfig *config.AppConfig, client *http.Client) *RedditExtractor {
-	return &RedditExtractor{
-		BaseExtractor: NewBaseExtractor(appConfig, client),
+func NewRedditExtractor(appConfig *config.AppConfig, factory TransportFactory) (*RedditExtractor, error) {
+	base, err := NewBaseExtractor(appConfig, factory, "reddit.com")
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := appConfig.RedditUserAgent
+	if userAgent == "" {
+		userAgent = useragent.Random()
+	}
+	base.HTTPClient = withRedditTransport(base.HTTPClient, userAgent, nil)
+
+	extractor := &RedditExtractor{
+		BaseExtractor: base,
+		rateLimiter:   newRedditRateLimiter(appConfig.RedditRateLimitBuffer),
+	}
+
+	if appConfig.HasRedditConfig() {
+		extractor.tokenSource = newRedditTokenSourceFromConfig(appConfig, base.HTTPClient)
+
+		apiClient, err := factory.ClientFor("reddit.com")
+		if err != nil {
+			return nil, fmt.Errorf("failed to build reddit API client: %w", err)
+		}
+		extractor.APIClient = withRedditTransport(apiClient, userAgent, extractor.tokenSource)
+	}
+
+	return extractor, nil
+}
+
+// invalidateAccessToken discards the cached OAuth token so the next request fetches a
+// fresh one, used after a 401/403 suggests the current token was revoked or expired
+// early. A no-op when no Reddit API credentials are configured.
+func (e *RedditExtractor) invalidateAccessToken() {
+	if e.tokenSource != nil {
+		e.tokenSource.invalidate()
 	}
 }
 
+// redditAnonClientKey identifies the shared, unauthenticated .json endpoints under
+// redditRateLimiter, which Reddit rate-limits by IP rather than by OAuth client.
+const redditAnonClientKey = "anon"
+
+// errorForStatus classifies a non-200 Reddit response into a typed ErrorClass error
+// (see ClassifyHTTPStatus), falling back to a generic status-code error for codes the
+// taxonomy doesn't recognize as a distinct class.
+func errorForStatus(resp *http.Response, fallback string) error {
+	if _, err := ClassifyHTTPStatus(resp.StatusCode, retryAfter(resp)); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s with status: %d", fallback, resp.StatusCode)
+}
+
 // Reddit API response structures
 type RedditAPIResponse struct {
 	Data struct {
 		Children []struct {
 			Data json.RawMessage `json:"data"`
 		} `json:"children"`
+		// After is Reddit's listing-pagination cursor; fetchSubredditPosts and
+		// fetchUserPosts surface it on ExtractedResult.NextPageToken so callers can pass
+		// it back as RedditURLInfo.After to fetch the next page.
+		After string `json:"after"`
 	} `json:"data"`
 }
 
@@ -82,78 +142,22 @@ func (r *RedditReplies) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// RedditComment represents a Reddit comment, which can be a regular comment or a "more" object.
+// RedditComment represents a Reddit comment, which can be a regular comment or a "more"
+// object. Count, Children, ParentID, and Depth are only populated on a "more" stub:
+// Reddit collapses Count replies under ParentID into a list of Children comment ids
+// rather than inlining them, for expandMoreChildren to fetch separately.
 type RedditComment struct {
-	Kind    string        `json:"kind"`
-	Body    string        `json:"body"`
-	Author  string        `json:"author"`
-	Score   int           `json:"score"`
-	Replies RedditReplies `json:"replies"`
-}
-
-// OAuth token response
-type RedditTokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
-}
-
-// getAccessToken obtains an OAuth access token for Reddit API
-func (e *RedditExtractor) getAccessToken() error {
-	e.tokenMutex.Lock()
-	defer e.tokenMutex.Unlock()
-
-	if e.Config.RedditClientID == "" || e.Config.RedditClientSecret == "" {
-		return fmt.Errorf("reddit API credentials not configured")
-	}
-
-	// Check if we have a valid token
-	if e.accessToken != "" && time.Now().Before(e.tokenExpiry) {
-		return nil
-	}
-
-	// Request new token
-	data := url.Values{}
-	data.Set("grant_type", "client_credentials")
-
-	req, err := http.NewRequest("POST", "https://www.reddit.com/api/v1/access_token", strings.NewReader(data.Encode()))
-	if err != nil {
-		return fmt.Errorf("failed to create token request: %v", err)
-	}
-
-	req.SetBasicAuth(e.Config.RedditClientID, e.Config.RedditClientSecret)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	userAgent := e.Config.RedditUserAgent
-	if userAgent == "" {
-		userAgent = useragent.Random()
-	}
-	req.Header.Set("User-Agent", userAgent)
-
-	resp, err := e.HTTPClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to get access token: %v", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			slog.Warn("RedditExtractor: Failed to close response body", "error", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("token request failed with status: %d", resp.StatusCode)
-	}
-
-	var tokenResp RedditTokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return fmt.Errorf("failed to decode token response: %v", err)
-	}
-
-	e.accessToken = tokenResp.AccessToken
-	e.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn-60) * time.Second) // Refresh 1 minute early
-
-	slog.Info("RedditExtractor: Successfully obtained access token")
-	return nil
+	Kind      string        `json:"kind"`
+	ID        string        `json:"id,omitempty"`
+	Body      string        `json:"body"`
+	Author    string        `json:"author"`
+	Score     int           `json:"score"`
+	Permalink string        `json:"permalink,omitempty"`
+	Replies   RedditReplies `json:"replies"`
+	Count     int           `json:"count,omitempty"`
+	Children  []string      `json:"children,omitempty"`
+	ParentID  string        `json:"parent_id,omitempty"`
+	Depth     int           `json:"depth,omitempty"`
 }
 
 // RedditURLType represents the type of Reddit URL
@@ -167,6 +171,17 @@ const (
 	RedditSearchURL
 )
 
+// redditListingSorts are the subreddit listing feeds Reddit exposes as a URL segment
+// (/r/<sub>/<sort>), as opposed to a query parameter.
+var redditListingSorts = map[string]bool{
+	"hot": true, "new": true, "top": true, "rising": true, "controversial": true,
+}
+
+// redditUserListings are the listing segments Reddit exposes under /user/<u>/<listing>.
+var redditUserListings = map[string]bool{
+	"submitted": true, "comments": true, "overview": true,
+}
+
 // RedditURLInfo contains parsed information about a Reddit URL
 type RedditURLInfo struct {
 	Type      RedditURLType
@@ -175,6 +190,20 @@ type RedditURLInfo struct {
 	CommentID string
 	Username  string
 	Query     string
+	// Sort is the listing feed (hot/new/top/rising/controversial, or "best" for the
+	// front-page feed at Subreddit == "") for a RedditSubredditURL, the user listing
+	// (submitted/comments/overview) for a RedditUserURL, or the "sort" query parameter
+	// (relevance/new/top/comments) for a RedditSearchURL.
+	Sort string
+	// TimeFilter is the "t" query parameter (hour/day/week/month/year/all), honored by
+	// both listing feeds and search.
+	TimeFilter string
+	// After is Reddit's listing-pagination cursor ("after" query parameter); passing it
+	// back in a subsequent request continues from where the previous page left off.
+	After string
+	// Limit is the "limit" query parameter, capped at 100 (Reddit's own max) by the
+	// fetchers that use it.
+	Limit int
 }
 
 // parseRedditURL parses a Reddit URL and returns detailed information about its type and components
@@ -185,12 +214,35 @@ func (e *RedditExtractor) parseRedditURL(redditURL string) (*RedditURLInfo, erro
 	}
 
 	pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	query := parsedURL.Query()
+	after := query.Get("after")
+	timeFilter := query.Get("t")
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		limit, _ = strconv.Atoi(raw)
+	}
+
+	if pathParts[0] == "search" {
+		return &RedditURLInfo{
+			Type:       RedditSearchURL,
+			Query:      query.Get("q"),
+			Sort:       query.Get("sort"),
+			TimeFilter: timeFilter,
+		}, nil
+	}
+
+	// The front page (https://www.reddit.com/) has no /r/ or /u/ prefix at all; treat it
+	// as the Subreddit == "" / Sort == "best" feed, which fetchSubredditPosts maps to
+	// reddit.com/best.json.
+	if len(pathParts) == 1 && pathParts[0] == "" {
+		return &RedditURLInfo{Type: RedditSubredditURL, Sort: "best", After: after, TimeFilter: timeFilter, Limit: limit}, nil
+	}
 
 	if len(pathParts) < 2 {
 		return nil, fmt.Errorf("invalid Reddit URL format: URL is too short")
 	}
 
-	info := &RedditURLInfo{}
+	info := &RedditURLInfo{After: after, TimeFilter: timeFilter, Limit: limit}
 
 	// Handle different Reddit URL formats
 	switch pathParts[0] {
@@ -200,10 +252,7 @@ func (e *RedditExtractor) parseRedditURL(redditURL string) (*RedditURLInfo, erro
 		}
 		info.Subreddit = pathParts[1]
 
-		if len(pathParts) == 2 {
-			// /r/subreddit/
-			info.Type = RedditSubredditURL
-		} else if len(pathParts) >= 4 && pathParts[2] == "comments" {
+		if len(pathParts) >= 4 && pathParts[2] == "comments" {
 			// /r/subreddit/comments/postid/title/
 			info.Type = RedditPostURL
 			info.PostID = pathParts[3]
@@ -216,7 +265,19 @@ func (e *RedditExtractor) parseRedditURL(redditURL string) (*RedditURLInfo, erro
 		} else if len(pathParts) >= 3 && pathParts[2] == "search" {
 			// /r/subreddit/search/
 			info.Type = RedditSearchURL
-			info.Query = parsedURL.Query().Get("q")
+			info.Query = query.Get("q")
+			info.Sort = query.Get("sort")
+		} else if len(pathParts) >= 3 && redditListingSorts[pathParts[2]] {
+			// /r/subreddit/{hot,new,top,rising,controversial}/
+			info.Type = RedditSubredditURL
+			info.Sort = pathParts[2]
+		} else if len(pathParts) == 2 {
+			// /r/subreddit/, with the pseudo-subreddits /r/popular and /r/all defaulting
+			// to their "hot" front-page feed, matching what reddit.com itself serves.
+			info.Type = RedditSubredditURL
+			if info.Subreddit == "popular" || info.Subreddit == "all" {
+				info.Sort = "hot"
+			}
 		} else {
 			return nil, fmt.Errorf("unsupported Reddit URL format: %s", redditURL)
 		}
@@ -227,6 +288,9 @@ func (e *RedditExtractor) parseRedditURL(redditURL string) (*RedditURLInfo, erro
 		}
 		info.Type = RedditUserURL
 		info.Username = pathParts[1]
+		if len(pathParts) >= 3 && redditUserListings[pathParts[2]] {
+			info.Sort = pathParts[2]
+		}
 
 	default:
 		return nil, fmt.Errorf("unsupported Reddit URL format: must start with /r/, /u/, or /user/")
@@ -236,27 +300,16 @@ func (e *RedditExtractor) parseRedditURL(redditURL string) (*RedditURLInfo, erro
 }
 
 // fetchViaAPI attempts to fetch Reddit data using the official API with concurrent processing
-func (e *RedditExtractor) fetchViaAPI(subreddit, postID string, result *ExtractedResult) error {
-	if err := e.getAccessToken(); err != nil {
-		return fmt.Errorf("failed to get access token: %w", err)
-	}
-
+func (e *RedditExtractor) fetchViaAPI(ctx context.Context, subreddit, postID string, result *ExtractedResult) error {
 	// Fetch post data
 	postURL := fmt.Sprintf("https://oauth.reddit.com/r/%s/comments/%s", subreddit, postID)
 
-	req, err := http.NewRequest("GET", postURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", postURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create API request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+e.accessToken)
-	userAgent := e.Config.RedditUserAgent
-	if userAgent == "" {
-		userAgent = useragent.Random()
-	}
-	req.Header.Set("User-Agent", userAgent)
-
-	resp, err := e.HTTPClient.Do(req)
+	resp, err := e.rateLimiter.do(ctx, e.APIClient, req, e.Config.RedditClientID, e.invalidateAccessToken)
 	if err != nil {
 		return fmt.Errorf("failed to make API request: %w", err)
 	}
@@ -267,7 +320,7 @@ func (e *RedditExtractor) fetchViaAPI(subreddit, postID string, result *Extracte
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+		return errorForStatus(resp, "API request failed")
 	}
 
 	var apiResponse []RedditAPIResponse
@@ -307,7 +360,7 @@ func (e *RedditExtractor) fetchViaAPI(subreddit, postID string, result *Extracte
 		defer wg.Done()
 		var commentsData []RedditComment
 		if len(apiResponse) > 1 {
-			commentsData = e.extractCommentsFromAPI(apiResponse[1])
+			commentsData = e.extractCommentsFromAPI(ctx, apiResponse[1], postID)
 		}
 		resultsChan <- processResult{comments: commentsData}
 	}()
@@ -349,9 +402,12 @@ func (e *RedditExtractor) fetchViaAPI(subreddit, postID string, result *Extracte
 	return nil
 }
 
-// flattenRepliesIterative iteratively extracts and flattens comment replies.
-func (e *RedditExtractor) flattenRepliesIterative(initialChildren []struct{ RedditComment }) []RedditComment {
+// flattenRepliesIterative iteratively extracts and flattens comment replies. "more"
+// stubs are returned separately (not dropped) so the caller can expand them via
+// expandMoreChildren.
+func (e *RedditExtractor) flattenRepliesIterative(initialChildren []struct{ RedditComment }) ([]RedditComment, []RedditComment) {
 	var comments []RedditComment
+	var moreStubs []RedditComment
 	stack := make([]struct{ RedditComment }, len(initialChildren))
 	copy(stack, initialChildren)
 
@@ -361,7 +417,11 @@ func (e *RedditExtractor) flattenRepliesIterative(initialChildren []struct{ Redd
 		stack = stack[:len(stack)-1]
 
 		comment := child.RedditComment
-		if comment.Kind == "more" || comment.Body == "" || comment.Body == "[deleted]" || comment.Body == "[removed]" {
+		if comment.Kind == "more" {
+			moreStubs = append(moreStubs, comment)
+			continue
+		}
+		if comment.Body == "" || comment.Body == "[deleted]" || comment.Body == "[removed]" {
 			continue
 		}
 
@@ -375,12 +435,16 @@ func (e *RedditExtractor) flattenRepliesIterative(initialChildren []struct{ Redd
 			stack = append(stack, replies[i])
 		}
 	}
-	return comments
+	return comments, moreStubs
 }
 
-// extractCommentsFromAPI recursively extracts comments from Reddit API response
-func (e *RedditExtractor) extractCommentsFromAPI(commentsResp RedditAPIResponse) []RedditComment {
+// extractCommentsFromAPI recursively extracts comments from Reddit API response. "more"
+// stubs (Reddit's way of collapsing large threads into a child-id list instead of
+// inlining them) are expanded via expandMoreChildren when OAuth credentials are
+// configured; otherwise they're dropped, same as before this stub-expansion existed.
+func (e *RedditExtractor) extractCommentsFromAPI(ctx context.Context, commentsResp RedditAPIResponse, postID string) []RedditComment {
 	var comments []RedditComment
+	var moreStubs []RedditComment
 
 	for _, child := range commentsResp.Data.Children {
 		var comment RedditComment
@@ -389,8 +453,12 @@ func (e *RedditExtractor) extractCommentsFromAPI(commentsResp RedditAPIResponse)
 			continue
 		}
 
-		// Skip "more" objects, empty, deleted, or removed comments
-		if comment.Kind == "more" || comment.Body == "" || comment.Body == "[deleted]" || comment.Body == "[removed]" {
+		if comment.Kind == "more" {
+			moreStubs = append(moreStubs, comment)
+			continue
+		}
+		// Skip empty, deleted, or removed comments
+		if comment.Body == "" || comment.Body == "[deleted]" || comment.Body == "[removed]" {
 			continue
 		}
 
@@ -402,37 +470,350 @@ func (e *RedditExtractor) extractCommentsFromAPI(commentsResp RedditAPIResponse)
 
 		// Recursively extract and flatten replies
 		if len(replies) > 0 {
-			comments = append(comments, e.flattenRepliesIterative(replies)...)
+			nested, nestedMore := e.flattenRepliesIterative(replies)
+			comments = append(comments, nested...)
+			moreStubs = append(moreStubs, nestedMore...)
+		}
+	}
+
+	comments = append(comments, e.expandMoreChildren(ctx, postID, moreStubs)...)
+	comments = e.selectComments(comments, postID)
+
+	slog.Debug("RedditExtractor: Extracted comments", "count", len(comments))
+	return comments
+}
+
+// selectComments applies e.Config.RedditExtractMode to a fully flattened comment list:
+// ModeFlat keeps the first RedditMaxComments in encounter order (the extractor's
+// long-standing default); ModeTopN instead keeps the RedditMaxComments
+// highest-scoring comments, regardless of where they sit in the tree; ModeThreaded
+// re-nests the (already capped) comments under their ParentID, via buildRedditCommentTree,
+// so callers can attribute a reply to its parent instead of reading a flat list.
+func (e *RedditExtractor) selectComments(comments []RedditComment, postID string) []RedditComment {
+	maxComments := e.Config.RedditMaxComments
+	if maxComments <= 0 {
+		maxComments = 50
+	}
+
+	switch e.Config.RedditExtractMode {
+	case config.RedditModeTopN:
+		sort.SliceStable(comments, func(i, j int) bool { return comments[i].Score > comments[j].Score })
+		if len(comments) > maxComments {
+			comments = comments[:maxComments]
+		}
+		return comments
+
+	case config.RedditModeThreaded:
+		if len(comments) > maxComments {
+			comments = comments[:maxComments]
+		}
+		maxDepth := e.Config.RedditMaxDepth
+		if maxDepth <= 0 {
+			maxDepth = 1
+		}
+		return buildRedditCommentTree(comments, postID, maxDepth)
+
+	default: // config.RedditModeFlat
+		if len(comments) > maxComments {
+			comments = comments[:maxComments]
+		}
+		return comments
+	}
+}
+
+// buildRedditCommentTree nests comments under their ParentID (stripped of Reddit's "t1_"
+// comment-fullname prefix), populating each node's Replies.Data.Children up to maxDepth
+// levels deep. A node at the depth cap gets every remaining descendant attached flat via
+// flattenRedditDescendants instead of dropped, so ModeThreaded never silently loses a
+// reply the way a hard depth cutoff would.
+func buildRedditCommentTree(comments []RedditComment, postID string, maxDepth int) []RedditComment {
+	if maxDepth <= 0 {
+		maxDepth = 1
+	}
+	postFullname := "t3_" + postID
+
+	byParent := make(map[string][]RedditComment, len(comments))
+	var roots []RedditComment
+	for _, c := range comments {
+		parent := strings.TrimPrefix(c.ParentID, "t1_")
+		if c.ParentID == "" || c.ParentID == postFullname {
+			roots = append(roots, c)
+		} else {
+			byParent[parent] = append(byParent[parent], c)
+		}
+	}
+
+	var attach func(node *RedditComment, depth int)
+	attach = func(node *RedditComment, depth int) {
+		children := byParent[node.ID]
+		if len(children) == 0 {
+			return
+		}
+		if depth >= maxDepth {
+			node.Replies.Data.Children = flattenRedditDescendants(byParent, node.ID)
+			return
+		}
+		for i := range children {
+			attach(&children[i], depth+1)
+		}
+		node.Replies.Data.Children = wrapRedditChildren(children)
+	}
+	for i := range roots {
+		attach(&roots[i], 1)
+	}
+	return roots
+}
+
+// flattenRedditDescendants collects every descendant of id, regardless of nesting depth,
+// as a single flat slice of wrapped children; used once buildRedditCommentTree's depth cap
+// is reached so deeper replies are still present in the output.
+func flattenRedditDescendants(byParent map[string][]RedditComment, id string) []struct{ RedditComment } {
+	var all []RedditComment
+	queue := append([]RedditComment{}, byParent[id]...)
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		all = append(all, c)
+		queue = append(queue, byParent[c.ID]...)
+	}
+	return wrapRedditChildren(all)
+}
+
+// truncateRedditData applies maxChars to a RedditData's PostBody and Comments.
+// ModeThreaded prunes whole subtrees from the comment tree once the character budget is
+// spent (via pruneRedditCommentTree), so a kept comment's Body is never cut mid-string;
+// every other mode keeps the extractor's original behavior of truncating comment bodies
+// in encounter order once the budget runs out.
+func truncateRedditData(data RedditData, maxChars int, mode config.RedditExtractMode) RedditData {
+	data.PostBody = truncateText(data.PostBody, maxChars)
+	remaining := maxChars - len(data.PostBody)
+
+	if mode == config.RedditModeThreaded {
+		data.Comments = pruneRedditCommentTree(data.Comments, &remaining)
+		return data
+	}
+
+	if remaining <= 0 {
+		data.Comments = []RedditComment{}
+		return data
+	}
+	var truncated []RedditComment
+	for _, comment := range data.Comments {
+		if remaining <= 0 {
+			break
+		}
+		if len(comment.Body) > remaining {
+			comment.Body = comment.Body[:remaining]
 		}
+		truncated = append(truncated, comment)
+		remaining -= len(comment.Body)
+	}
+	data.Comments = truncated
+	return data
+}
 
-		// Limit to 50 comments for performance
-		if len(comments) >= 50 {
-			slog.Debug("RedditExtractor: Reached comment limit of 50, stopping extraction")
+// pruneRedditCommentTree keeps whole comments, and their full subtrees, while *budget
+// remains: it spends a comment's Body length from the budget before recursing into its
+// replies, and drops (rather than truncates) the first comment at a level that would
+// exceed what's left, along with everything after it at that level.
+func pruneRedditCommentTree(comments []RedditComment, budget *int) []RedditComment {
+	var kept []RedditComment
+	for _, comment := range comments {
+		if *budget <= 0 || len(comment.Body) > *budget {
 			break
 		}
+		*budget -= len(comment.Body)
+		children := unwrapRedditChildren(comment.Replies.Data.Children)
+		comment.Replies.Data.Children = wrapRedditChildren(pruneRedditCommentTree(children, budget))
+		kept = append(kept, comment)
 	}
+	return kept
+}
 
-	slog.Debug("RedditExtractor: Extracted comments", "count", len(comments))
+// unwrapRedditChildren is wrapRedditChildren's inverse, for re-walking a tree that's
+// already been nested by buildRedditCommentTree.
+func unwrapRedditChildren(wrapped []struct{ RedditComment }) []RedditComment {
+	comments := make([]RedditComment, len(wrapped))
+	for i, w := range wrapped {
+		comments[i] = w.RedditComment
+	}
 	return comments
 }
 
-// fetchSubredditPosts fetches recent posts from a subreddit
-func (e *RedditExtractor) fetchSubredditPosts(subreddit string, result *ExtractedResult) error {
-	// Use .json endpoint for subreddit
-	jsonURL := fmt.Sprintf("https://www.reddit.com/r/%s/.json?limit=10", subreddit)
+// wrapRedditChildren adapts a []RedditComment to the []struct{ RedditComment } shape
+// RedditReplies.Data.Children decodes into, so tree-building code can populate it the
+// same way json.Unmarshal would.
+func wrapRedditChildren(comments []RedditComment) []struct{ RedditComment } {
+	wrapped := make([]struct{ RedditComment }, len(comments))
+	for i, c := range comments {
+		wrapped[i] = struct{ RedditComment }{RedditComment: c}
+	}
+	return wrapped
+}
+
+// expandMoreChildren fetches the comments a "more" stub's Children ids point to via
+// postMoreChildren, breadth-first: each round sends up to 100 ids per POST request
+// (Reddit's own per-request limit) and re-queues any "more" stubs the response itself
+// contains, continuing until the queue drains or RedditMaxMoreRequests requests have
+// been sent, whichever comes first. With no OAuth credentials configured (or a token
+// failure), this drops the stubs, matching the extractor's long-standing anonymous-caller
+// behavior.
+func (e *RedditExtractor) expandMoreChildren(ctx context.Context, postID string, stubs []RedditComment) []RedditComment {
+	if len(stubs) == 0 {
+		return nil
+	}
+	if e.APIClient == nil {
+		slog.Debug("RedditExtractor: Reddit API credentials not configured, dropping more-children stubs", "count", len(stubs))
+		return nil
+	}
 
-	req, err := http.NewRequest("GET", jsonURL, nil)
+	maxRequests := e.Config.RedditMaxMoreRequests
+	if maxRequests <= 0 {
+		maxRequests = 5
+	}
+
+	var queue []string
+	for _, stub := range stubs {
+		queue = append(queue, stub.Children...)
+	}
+
+	var expanded []RedditComment
+	requestsSent := 0
+	for len(queue) > 0 && requestsSent < maxRequests {
+		batch := queue
+		if len(batch) > 100 {
+			batch = batch[:100]
+		}
+		queue = queue[len(batch):]
+
+		things, err := e.postMoreChildren(ctx, postID, batch)
+		requestsSent++
+		if err != nil {
+			slog.Warn("RedditExtractor: more-children request failed", "error", err)
+			continue
+		}
+
+		for _, comment := range things {
+			if comment.Kind == "more" {
+				queue = append(queue, comment.Children...)
+				continue
+			}
+			if comment.Body == "" || comment.Body == "[deleted]" || comment.Body == "[removed]" {
+				continue
+			}
+			expanded = append(expanded, comment)
+		}
+	}
+
+	if len(queue) > 0 {
+		slog.Debug("RedditExtractor: Stopped expanding more-children at request cap, some replies remain collapsed",
+			"remaining", len(queue), "max_requests", maxRequests)
+	}
+
+	return expanded
+}
+
+// redditMoreChildrenResponse is the envelope morechildren's api_type=json wraps its
+// expanded comment list in.
+type redditMoreChildrenResponse struct {
+	JSON struct {
+		Data struct {
+			Things []struct {
+				Kind string          `json:"kind"`
+				Data json.RawMessage `json:"data"`
+			} `json:"things"`
+		} `json:"data"`
+	} `json:"json"`
+}
+
+// postMoreChildren fetches one batch (<= 100 ids) of a "more" stub's children via
+// oauth.reddit.com/api/morechildren, sorted by confidence to match Reddit's default
+// comment ordering.
+func (e *RedditExtractor) postMoreChildren(ctx context.Context, postID string, ids []string) ([]RedditComment, error) {
+	form := url.Values{}
+	form.Set("api_type", "json")
+	form.Set("link_kind_id", "t3_"+postID)
+	form.Set("children", strings.Join(ids, ","))
+	form.Set("sort", "confidence")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://oauth.reddit.com/api/morechildren", strings.NewReader(form.Encode()))
 	if err != nil {
-		return fmt.Errorf("failed to create subreddit request: %w", err)
+		return nil, fmt.Errorf("failed to create more-children request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	userAgent := e.Config.RedditUserAgent
-	if userAgent == "" {
-		userAgent = useragent.Random()
+	resp, err := e.rateLimiter.do(ctx, e.APIClient, req, e.Config.RedditClientID, e.invalidateAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make more-children request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("RedditExtractor: Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorForStatus(resp, "more-children request failed")
+	}
+
+	var decoded redditMoreChildrenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode more-children response: %w", err)
+	}
+
+	comments := make([]RedditComment, 0, len(decoded.JSON.Data.Things))
+	for _, thing := range decoded.JSON.Data.Things {
+		var comment RedditComment
+		if err := json.Unmarshal(thing.Data, &comment); err != nil {
+			slog.Warn("RedditExtractor: Failed to unmarshal more-children comment", "error", err)
+			continue
+		}
+		comment.Kind = thing.Kind
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// redditListingURL builds a Reddit listing .json URL for subreddit (empty for the
+// front-page /best feed) and sort (hot/new/top/rising/controversial/best, or "" for
+// Reddit's default), carrying timeFilter/after/limit as query parameters. limit is capped
+// at 100, Reddit's own max, and defaults to 10 to match this extractor's prior behavior.
+func redditListingURL(subreddit, sort, timeFilter, after string, limit int) string {
+	if limit <= 0 {
+		limit = 10
+	} else if limit > 100 {
+		limit = 100
+	}
+	params := url.Values{"limit": {strconv.Itoa(limit)}}
+	if timeFilter != "" {
+		params.Set("t", timeFilter)
+	}
+	if after != "" {
+		params.Set("after", after)
+	}
+
+	if subreddit == "" {
+		return fmt.Sprintf("https://www.reddit.com/best.json?%s", params.Encode())
+	}
+	if sort == "" {
+		return fmt.Sprintf("https://www.reddit.com/r/%s/.json?%s", subreddit, params.Encode())
+	}
+	return fmt.Sprintf("https://www.reddit.com/r/%s/%s.json?%s", subreddit, sort, params.Encode())
+}
+
+// fetchSubredditPosts fetches posts from a subreddit listing feed (hot/new/top/rising/
+// controversial, or Reddit's default when sort == ""), or the front-page /best feed when
+// subreddit == "". The "after" token from the response is surfaced on
+// result.NextPageToken so callers can page through the listing.
+func (e *RedditExtractor) fetchSubredditPosts(ctx context.Context, subreddit, sort, timeFilter, after string, limit int, result *ExtractedResult) error {
+	jsonURL := redditListingURL(subreddit, sort, timeFilter, after, limit)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", jsonURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create subreddit request: %w", err)
 	}
-	req.Header.Set("User-Agent", userAgent)
 
-	resp, err := e.HTTPClient.Do(req)
+	resp, err := e.rateLimiter.do(ctx, e.HTTPClient, req, redditAnonClientKey, nil)
 	if err != nil {
 		return fmt.Errorf("failed to make subreddit request: %w", err)
 	}
@@ -443,7 +824,7 @@ func (e *RedditExtractor) fetchSubredditPosts(subreddit string, result *Extracte
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("subreddit request failed with status: %d", resp.StatusCode)
+		return errorForStatus(resp, "subreddit request failed")
 	}
 
 	var jsonResponse RedditAPIResponse
@@ -465,35 +846,53 @@ func (e *RedditExtractor) fetchSubredditPosts(subreddit string, result *Extracte
 		posts = append(posts, post)
 	}
 
+	label := fmt.Sprintf("r/%s", subreddit)
+	if subreddit == "" {
+		label = "Reddit front page"
+	}
 	result.ProcessedSuccessfully = true
 	result.Data = RedditData{
-		PostTitle: fmt.Sprintf("r/%s - Recent Posts", subreddit),
-		PostBody:  fmt.Sprintf("Recent posts from r/%s", subreddit),
+		PostTitle: fmt.Sprintf("%s - Recent Posts", label),
+		PostBody:  fmt.Sprintf("Recent posts from %s", label),
 		Score:     0,
 		Author:    "subreddit",
 		Posts:     posts,
 	}
+	result.NextPageToken = jsonResponse.Data.After
 
 	return nil
 }
 
-// fetchUserPosts fetches recent posts from a user profile
-func (e *RedditExtractor) fetchUserPosts(username string, result *ExtractedResult) error {
-	// Use .json endpoint for user posts
-	jsonURL := fmt.Sprintf("https://www.reddit.com/user/%s/.json?limit=10", username)
+// fetchUserPosts fetches posts from a user profile, optionally restricted to listing
+// (submitted/comments/overview, or Reddit's default when listing == ""). The "after"
+// token from the response is surfaced on result.NextPageToken so callers can page through
+// the listing.
+func (e *RedditExtractor) fetchUserPosts(ctx context.Context, username, listing, timeFilter, after string, limit int, result *ExtractedResult) error {
+	if limit <= 0 {
+		limit = 10
+	} else if limit > 100 {
+		limit = 100
+	}
+	params := url.Values{"limit": {strconv.Itoa(limit)}}
+	if timeFilter != "" {
+		params.Set("t", timeFilter)
+	}
+	if after != "" {
+		params.Set("after", after)
+	}
+	var jsonURL string
+	if listing == "" {
+		jsonURL = fmt.Sprintf("https://www.reddit.com/user/%s/.json?%s", username, params.Encode())
+	} else {
+		jsonURL = fmt.Sprintf("https://www.reddit.com/user/%s/%s.json?%s", username, listing, params.Encode())
+	}
 
-	req, err := http.NewRequest("GET", jsonURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", jsonURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create user request: %w", err)
 	}
 
-	userAgent := e.Config.RedditUserAgent
-	if userAgent == "" {
-		userAgent = useragent.Random()
-	}
-	req.Header.Set("User-Agent", userAgent)
-
-	resp, err := e.HTTPClient.Do(req)
+	resp, err := e.rateLimiter.do(ctx, e.HTTPClient, req, redditAnonClientKey, nil)
 	if err != nil {
 		return fmt.Errorf("failed to make user request: %w", err)
 	}
@@ -504,7 +903,7 @@ func (e *RedditExtractor) fetchUserPosts(username string, result *ExtractedResul
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("user request failed with status: %d", resp.StatusCode)
+		return errorForStatus(resp, "user request failed")
 	}
 
 	var jsonResponse RedditAPIResponse
@@ -534,30 +933,121 @@ func (e *RedditExtractor) fetchUserPosts(username string, result *ExtractedResul
 		Author:    username,
 		Posts:     posts,
 	}
+	result.NextPageToken = jsonResponse.Data.After
+
+	return nil
+}
+
+// fetchSearchResults runs a Reddit search, using the authenticated oauth.reddit.com API
+// when credentials are configured and the anonymous www.reddit.com/search.json endpoint
+// otherwise. subreddit restricts the search to r/{subreddit} (via restrict_sr=on); an
+// empty subreddit searches all of Reddit. sort and t pass through the search's "sort"
+// (relevance/new/top/comments) and "t" (hour/day/week/month/year/all) parameters
+// unchanged, leaving Reddit's own defaults in effect when either is empty.
+func (e *RedditExtractor) fetchSearchResults(ctx context.Context, subreddit, query, sort, t string, maxChars *int, result *ExtractedResult) error {
+	params := url.Values{}
+	params.Set("q", query)
+	if sort != "" {
+		params.Set("sort", sort)
+	}
+	if t != "" {
+		params.Set("t", t)
+	}
+	if subreddit != "" {
+		params.Set("restrict_sr", "on")
+	}
+
+	var searchURL, clientKey string
+	client := e.HTTPClient
+	var onUnauthorized func()
+	if e.APIClient != nil {
+		if subreddit != "" {
+			searchURL = fmt.Sprintf("https://oauth.reddit.com/r/%s/search?%s", subreddit, params.Encode())
+		} else {
+			searchURL = fmt.Sprintf("https://oauth.reddit.com/search?%s", params.Encode())
+		}
+		clientKey = e.Config.RedditClientID
+		client = e.APIClient
+		onUnauthorized = e.invalidateAccessToken
+	} else {
+		if subreddit != "" {
+			searchURL = fmt.Sprintf("https://www.reddit.com/r/%s/search.json?%s", subreddit, params.Encode())
+		} else {
+			searchURL = fmt.Sprintf("https://www.reddit.com/search.json?%s", params.Encode())
+		}
+		clientKey = redditAnonClientKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create search request: %w", err)
+	}
+
+	resp, err := e.rateLimiter.do(ctx, client, req, clientKey, onUnauthorized)
+	if err != nil {
+		return fmt.Errorf("failed to make search request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("RedditExtractor: Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorForStatus(resp, "search request failed")
+	}
+
+	var jsonResponse RedditAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return fmt.Errorf("failed to decode search JSON response: %w", err)
+	}
+
+	if len(jsonResponse.Data.Children) == 0 {
+		return fmt.Errorf("no posts found for search query %q", query)
+	}
+
+	var posts []RedditPost
+	for _, child := range jsonResponse.Data.Children {
+		var post RedditPost
+		if err := json.Unmarshal(child.Data, &post); err != nil {
+			continue
+		}
+		posts = append(posts, post)
+	}
+
+	if maxChars != nil && len(posts) > 0 {
+		perPost := *maxChars / len(posts)
+		for i := range posts {
+			posts[i].Title = truncateText(posts[i].Title, perPost)
+			posts[i].Selftext = truncateText(posts[i].Selftext, perPost)
+		}
+	}
+
+	result.ProcessedSuccessfully = true
+	result.Data = RedditData{
+		PostTitle: fmt.Sprintf("Search results for %q", query),
+		PostBody:  fmt.Sprintf("%d results found", len(posts)),
+		Author:    "search",
+		Posts:     posts,
+	}
 
 	return nil
 }
 
 // fetchViaJSON attempts to fetch Reddit data using the .json fallback method
-func (e *RedditExtractor) fetchViaJSON(redditURL string, maxChars *int, result *ExtractedResult) error {
+func (e *RedditExtractor) fetchViaJSON(ctx context.Context, redditURL string, maxChars *int, result *ExtractedResult) error {
 	// Add .json to the URL if not already present
 	jsonURL := redditURL
 	if !strings.HasSuffix(redditURL, ".json") {
 		jsonURL = redditURL + ".json"
 	}
 
-	req, err := http.NewRequest("GET", jsonURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", jsonURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create JSON request: %w", err)
 	}
 
-	userAgent := e.Config.RedditUserAgent
-	if userAgent == "" {
-		userAgent = useragent.Random()
-	}
-	req.Header.Set("User-Agent", userAgent)
-
-	resp, err := e.HTTPClient.Do(req)
+	resp, err := e.rateLimiter.do(ctx, e.HTTPClient, req, redditAnonClientKey, nil)
 	if err != nil {
 		return fmt.Errorf("failed to make JSON request: %w", err)
 	}
@@ -568,7 +1058,7 @@ func (e *RedditExtractor) fetchViaJSON(redditURL string, maxChars *int, result *
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("JSON request failed with status: %d", resp.StatusCode)
+		return errorForStatus(resp, "JSON request failed")
 	}
 
 	var jsonResponse []RedditAPIResponse
@@ -589,7 +1079,7 @@ func (e *RedditExtractor) fetchViaJSON(redditURL string, maxChars *int, result *
 	// Extract comments data
 	var commentsData []RedditComment
 	if len(jsonResponse) > 1 {
-		commentsData = e.extractCommentsFromAPI(jsonResponse[1])
+		commentsData = e.extractCommentsFromAPI(ctx, jsonResponse[1], post.ID)
 	}
 
 	result.ProcessedSuccessfully = true
@@ -603,28 +1093,7 @@ func (e *RedditExtractor) fetchViaJSON(redditURL string, maxChars *int, result *
 
 	if maxChars != nil {
 		if data, ok := result.Data.(RedditData); ok {
-			data.PostBody = truncateText(data.PostBody, *maxChars)
-
-			// Truncate comments as well
-			remainingChars := *maxChars - len(data.PostBody)
-			if remainingChars > 0 {
-				var truncatedComments []RedditComment
-				for _, comment := range data.Comments {
-					if remainingChars <= 0 {
-						break
-					}
-					if len(comment.Body) > remainingChars {
-						comment.Body = comment.Body[:remainingChars]
-					}
-					truncatedComments = append(truncatedComments, comment)
-					remainingChars -= len(comment.Body)
-				}
-				data.Comments = truncatedComments
-			} else {
-				data.Comments = []RedditComment{}
-			}
-
-			result.Data = data
+			result.Data = truncateRedditData(data, *maxChars, e.Config.RedditExtractMode)
 		}
 	}
 
@@ -632,7 +1101,7 @@ func (e *RedditExtractor) fetchViaJSON(redditURL string, maxChars *int, result *
 }
 
 // Extract attempts to fetch Reddit data using API first, then falls back to JSON method
-func (e *RedditExtractor) Extract(redditURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
+func (e *RedditExtractor) Extract(ctx context.Context, redditURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("RedditExtractor: Starting extraction", "url", redditURL)
 	result.SourceType = "reddit"
 
@@ -648,21 +1117,22 @@ func (e *RedditExtractor) Extract(redditURL string, endpoint string, maxChars *i
 	switch urlInfo.Type {
 	case RedditPostURL, RedditCommentURL:
 		// Handle individual posts (comments are treated as posts with additional context)
-		return e.extractPost(redditURL, urlInfo, maxChars, result)
+		return e.extractPost(ctx, redditURL, urlInfo, maxChars, result)
 
 	case RedditSubredditURL:
 		// Handle subreddit feeds
 		slog.Debug("RedditExtractor: Extracting subreddit posts", "subreddit", urlInfo.Subreddit)
-		return e.fetchSubredditPosts(urlInfo.Subreddit, result)
+		return e.fetchSubredditPosts(ctx, urlInfo.Subreddit, urlInfo.Sort, urlInfo.TimeFilter, urlInfo.After, urlInfo.Limit, result)
 
 	case RedditUserURL:
 		// Handle user profiles
 		slog.Debug("RedditExtractor: Extracting user posts", "user", urlInfo.Username)
-		return e.fetchUserPosts(urlInfo.Username, result)
+		return e.fetchUserPosts(ctx, urlInfo.Username, urlInfo.Sort, urlInfo.TimeFilter, urlInfo.After, urlInfo.Limit, result)
 
 	case RedditSearchURL:
-		// Handle search results (not implemented yet)
-		return fmt.Errorf("reddit search URLs are not yet supported")
+		// Handle search results
+		slog.Debug("RedditExtractor: Extracting search results", "subreddit", urlInfo.Subreddit, "query", urlInfo.Query)
+		return e.fetchSearchResults(ctx, urlInfo.Subreddit, urlInfo.Query, urlInfo.Sort, urlInfo.TimeFilter, maxChars, result)
 
 	default:
 		return fmt.Errorf("unsupported Reddit URL type")
@@ -670,23 +1140,24 @@ func (e *RedditExtractor) Extract(redditURL string, endpoint string, maxChars *i
 }
 
 // extractPost handles individual Reddit posts
-func (e *RedditExtractor) extractPost(redditURL string, urlInfo *RedditURLInfo, maxChars *int, result *ExtractedResult) error {
+func (e *RedditExtractor) extractPost(ctx context.Context, redditURL string, urlInfo *RedditURLInfo, maxChars *int, result *ExtractedResult) error {
 	// First, try using the Reddit API
-	if e.Config.RedditClientID != "" && e.Config.RedditClientSecret != "" {
+	if e.APIClient != nil {
 		slog.Debug("RedditExtractor: Attempting to use Reddit API", "url", redditURL)
-		err := e.fetchViaAPI(urlInfo.Subreddit, urlInfo.PostID, result)
+		err := e.fetchViaAPI(ctx, urlInfo.Subreddit, urlInfo.PostID, result)
 		if err == nil {
 			slog.Info("RedditExtractor: Successfully extracted data via API", "url", redditURL)
 			if maxChars != nil {
 				if data, ok := result.Data.(RedditData); ok {
-					if len(data.PostBody) > *maxChars {
-						data.PostBody = data.PostBody[:*maxChars]
-						result.Data = data
-					}
+					result.Data = truncateRedditData(data, *maxChars, e.Config.RedditExtractMode)
 				}
 			}
 			return nil
 		}
+		if errors.Is(err, ErrUnavailable) {
+			logger.LogError("RedditExtractor: API method reports %s is gone, skipping JSON fallback: %v", redditURL, err)
+			return err
+		}
 		logger.LogError("RedditExtractor: API method failed for %s: %v. Falling back to JSON method", redditURL, err)
 	} else {
 		slog.Info("RedditExtractor: Reddit API credentials not configured, using JSON fallback", "url", redditURL)
@@ -694,7 +1165,7 @@ func (e *RedditExtractor) extractPost(redditURL string, urlInfo *RedditURLInfo,
 
 	// Fallback to JSON method
 	slog.Debug("RedditExtractor: Attempting to use JSON method", "url", redditURL)
-	err := e.fetchViaJSON(redditURL, maxChars, result)
+	err := e.fetchViaJSON(ctx, redditURL, maxChars, result)
 	if err != nil {
 		return fmt.Errorf("both API and JSON methods failed: %w", err)
 	}
@@ -0,0 +1,120 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"web-search-api-for-llms/internal/config"
+)
+
+// redditOAuthHost is the only host redditTransport attaches a bearer token to; every
+// other host (www.reddit.com's .json endpoints, the token endpoint itself) is reached
+// unauthenticated.
+const redditOAuthHost = "oauth.reddit.com"
+
+// redditTokenSource wraps the oauth2.TokenSource clientcredentials.Config builds so
+// invalidateAccessToken can swap in a fresh one after a 401/403 suggests Reddit revoked
+// or expired the current token early. The underlying oauth2.ReuseTokenSource already
+// serializes concurrent Token() calls, so this fixes the race the old hand-rolled
+// accessToken/tokenExpiry/tokenMutex trio had between "is it expired" and "fetch a new
+// one" without introducing a new one of its own.
+type redditTokenSource struct {
+	mu     sync.Mutex
+	cfg    *clientcredentials.Config
+	ctx    context.Context
+	source oauth2.TokenSource
+}
+
+// newRedditTokenSource builds a redditTokenSource that fetches tokens via tokenClient,
+// which must not itself be wrapped in redditTransport (that would recurse back into
+// Token() while fetching a token).
+func newRedditTokenSource(cfg *clientcredentials.Config, tokenClient *http.Client) *redditTokenSource {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, tokenClient)
+	return &redditTokenSource{cfg: cfg, ctx: ctx, source: cfg.TokenSource(ctx)}
+}
+
+// Token implements oauth2.TokenSource.
+func (s *redditTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	source := s.source
+	s.mu.Unlock()
+	return source.Token()
+}
+
+// invalidate discards the cached token source so the next Token() call fetches a fresh
+// token rather than trusting one Reddit has already rejected.
+func (s *redditTokenSource) invalidate() {
+	s.mu.Lock()
+	s.source = s.cfg.TokenSource(s.ctx)
+	s.mu.Unlock()
+}
+
+// redditTransport is the RoundTripper every RedditExtractor HTTP client is built with.
+// It always sets the configured User-Agent (Reddit throttles the default Go one almost
+// immediately), attaches an OAuth bearer token for requests to oauth.reddit.com when
+// tokenSource is non-nil, and logs each round trip's outcome via the package's usual
+// slog conventions. tokenSource is nil on the client built for the unauthenticated
+// .json fallback, so that client can never attach a bearer token even if a caller
+// mistakenly pointed it at oauth.reddit.com.
+type redditTransport struct {
+	next        http.RoundTripper
+	tokenSource oauth2.TokenSource
+	userAgent   string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *redditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.Header.Set("User-Agent", t.userAgent)
+
+	if t.tokenSource != nil && cloned.URL.Host == redditOAuthHost {
+		token, err := t.tokenSource.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain reddit access token: %w", err)
+		}
+		token.SetAuthHeader(cloned)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(cloned)
+	latency := time.Since(start)
+	attempt, _ := cloned.Context().Value(redditAttemptContextKey{}).(int)
+
+	if err != nil {
+		slog.Debug("RedditExtractor: request failed", "host", cloned.URL.Host, "retries", attempt, "latency_ms", latency.Milliseconds(), "error", err)
+		return resp, err
+	}
+	slog.Debug("RedditExtractor: request completed", "host", cloned.URL.Host, "status", resp.StatusCode, "retries", attempt, "latency_ms", latency.Milliseconds())
+	return resp, nil
+}
+
+// redditAttemptContextKey carries redditRateLimiter.do's current attempt number onto
+// the request context so redditTransport can log it alongside status and latency.
+type redditAttemptContextKey struct{}
+
+// withRedditTransport wraps client's Transport in a redditTransport, leaving client's
+// other settings (Timeout, etc.) untouched.
+func withRedditTransport(client *http.Client, userAgent string, tokenSource oauth2.TokenSource) *http.Client {
+	wrapped := *client
+	wrapped.Transport = &redditTransport{next: client.Transport, tokenSource: tokenSource, userAgent: userAgent}
+	return &wrapped
+}
+
+// newRedditTokenSourceFromConfig builds the redditTokenSource for appConfig's Reddit
+// client credentials, fetching tokens via tokenClient (the unauthenticated client, so
+// token fetches don't recurse through redditTransport's own auth logic).
+func newRedditTokenSourceFromConfig(appConfig *config.AppConfig, tokenClient *http.Client) *redditTokenSource {
+	return newRedditTokenSource(&clientcredentials.Config{
+		ClientID:     appConfig.RedditClientID,
+		ClientSecret: appConfig.RedditClientSecret,
+		TokenURL:     "https://www.reddit.com/api/v1/access_token",
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}, tokenClient)
+}
@@ -0,0 +1,197 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// redditRetryBackoffSchedule is how long redditRateLimiter.do waits before each retry of
+// a 429 or 5xx response, plus up to 50% jitter; the length of this schedule is also the
+// max retry count.
+var redditRetryBackoffSchedule = []time.Duration{
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+// redditRateLimitState is the last x-ratelimit-* values Reddit sent for one client
+// identity (an OAuth client ID, or "anon" for the unauthenticated .json endpoints, which
+// Reddit rate-limits separately by IP).
+type redditRateLimitState struct {
+	remaining float64
+	used      float64
+	resetAt   time.Time
+}
+
+// redditRateLimiter tracks Reddit's x-ratelimit-remaining/-used/-reset headers per
+// client identity and makes every caller of fetchViaAPI, fetchSubredditPosts,
+// fetchUserPosts, and fetchViaJSON share one view of the remaining budget, so a burst of
+// requests against the same subreddit backs off together instead of each one discovering
+// the 429 independently.
+type redditRateLimiter struct {
+	mu     sync.Mutex
+	states map[string]redditRateLimitState
+	// buffer is how much headroom to keep: once remaining drops below it, waitIfNeeded
+	// sleeps until the window resets rather than spending the rest of the budget.
+	buffer float64
+}
+
+// newRedditRateLimiter builds a redditRateLimiter that keeps buffer requests of headroom
+// (config.AppConfig.RedditRateLimitBuffer); buffer <= 0 falls back to 50.
+func newRedditRateLimiter(buffer int) *redditRateLimiter {
+	if buffer <= 0 {
+		buffer = 50
+	}
+	return &redditRateLimiter{
+		states: make(map[string]redditRateLimitState),
+		buffer: float64(buffer),
+	}
+}
+
+// recordHeaders updates clientKey's state from resp's x-ratelimit-* headers, if present.
+func (rl *redditRateLimiter) recordHeaders(clientKey string, resp *http.Response) {
+	remaining, hasRemaining := parseRateLimitHeader(resp.Header.Get("x-ratelimit-remaining"))
+	used, hasUsed := parseRateLimitHeader(resp.Header.Get("x-ratelimit-used"))
+	resetSeconds, hasReset := parseRateLimitHeader(resp.Header.Get("x-ratelimit-reset"))
+	if !hasRemaining && !hasUsed && !hasReset {
+		return
+	}
+
+	rl.mu.Lock()
+	state := rl.states[clientKey]
+	if hasRemaining {
+		state.remaining = remaining
+	}
+	if hasUsed {
+		state.used = used
+	}
+	if hasReset {
+		state.resetAt = time.Now().Add(time.Duration(resetSeconds) * time.Second)
+	}
+	rl.states[clientKey] = state
+	rl.mu.Unlock()
+
+	slog.Debug("RedditExtractor: rate limit state updated",
+		"client", clientKey,
+		"reddit.ratelimit.remaining", state.remaining,
+		"reddit.ratelimit.used", state.used)
+}
+
+// waitIfNeeded blocks until clientKey's remaining budget is no longer below rl.buffer,
+// sleeping until the last-seen reset time or until ctx is cancelled, whichever comes
+// first. A client Reddit has never reported headers for returns immediately: there's
+// nothing to wait on yet.
+func (rl *redditRateLimiter) waitIfNeeded(ctx context.Context, clientKey string) error {
+	rl.mu.Lock()
+	state, ok := rl.states[clientKey]
+	rl.mu.Unlock()
+	if !ok || state.remaining >= rl.buffer || state.resetAt.IsZero() {
+		return nil
+	}
+
+	sleep := time.Until(state.resetAt)
+	if sleep <= 0 {
+		return nil
+	}
+
+	slog.Info("RedditExtractor: approaching rate limit, waiting for reset",
+		"client", clientKey,
+		"reddit.ratelimit.remaining", state.remaining,
+		"reddit.ratelimit.sleep_ms", sleep.Milliseconds())
+	select {
+	case <-time.After(sleep):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseRateLimitHeader parses one of Reddit's x-ratelimit-* headers (all decimal, e.g.
+// "99.0" for remaining/used or "600" for reset-in-seconds). Returns ok == false for an
+// absent or malformed header.
+func parseRateLimitHeader(raw string) (float64, bool) {
+	if raw == "" {
+		return 0, false
+	}
+	val, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false
+	}
+	return val, true
+}
+
+// do waits for clientKey's rate-limit budget via waitIfNeeded, then issues req,
+// retrying a 429 or 5xx response per redditRetryBackoffSchedule (with up to 50% jitter)
+// up to len(redditRetryBackoffSchedule) times. A 401/403 calls onUnauthorized (so the
+// caller can invalidate a cached OAuth token) and returns immediately without retrying,
+// since retrying with the same stale token would just fail again. Every response that
+// carries rate-limit headers updates clientKey's state regardless of status code. On
+// success (including a non-retryable error status like 404, which the caller maps to its
+// own typed error), the caller owns resp and must close its body.
+func (rl *redditRateLimiter) do(ctx context.Context, client *http.Client, req *http.Request, clientKey string, onUnauthorized func()) (*http.Response, error) {
+	if err := rl.waitIfNeeded(ctx, clientKey); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req.WithContext(context.WithValue(req.Context(), redditAttemptContextKey{}, attempt)))
+		if err != nil {
+			lastErr = err
+		} else {
+			rl.recordHeaders(clientKey, resp)
+			switch resp.StatusCode {
+			case http.StatusUnauthorized, http.StatusForbidden:
+				if onUnauthorized != nil {
+					onUnauthorized()
+				}
+				lastErr = errorForStatus(resp, "reddit request failed")
+				_ = resp.Body.Close()
+				return nil, lastErr
+			case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				lastErr = errorForStatus(resp, "reddit request failed")
+				_ = resp.Body.Close()
+			default:
+				return resp, nil
+			}
+		}
+
+		if attempt >= len(redditRetryBackoffSchedule) {
+			return nil, lastErr
+		}
+		delay := jitterDuration(redditRetryBackoffSchedule[attempt])
+		slog.Warn("RedditExtractor: retrying after rate limit or server error",
+			"client", clientKey, "reddit.retries", attempt+1, "delay", delay, "error", lastErr)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// The previous attempt's client.Do drained req.Body to EOF, so a retry needs a
+		// fresh body or it sends an empty one (with the original, now-wrong,
+		// Content-Length). NewRequestWithContext sets GetBody for the common body types
+		// (e.g. *strings.Reader, *bytes.Reader), including postMoreChildren's form body.
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body for retry: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
+
+// jitterDuration returns d plus up to 50% extra, so many goroutines retrying at once
+// don't all wake up and re-request in the same instant.
+func jitterDuration(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
@@ -0,0 +1,219 @@
+package extractor
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// minReadableTextLength is the threshold below which WebpageExtractor prefers
+// structured-data ArticleBody over whatever the readability pass scraped, since a
+// result that thin is usually boilerplate (cookie banners, paywalls) rather than
+// real content.
+const minReadableTextLength = 200
+
+// jsonLDNode is the subset of schema.org fields the structured-data fallback cares
+// about. Sites vary in which of these they populate and under which @type, so every
+// field is optional.
+type jsonLDNode struct {
+	Type          json.RawMessage `json:"@type"`
+	Graph         []jsonLDNode    `json:"@graph"`
+	Headline      string          `json:"headline"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	DatePublished string          `json:"datePublished"`
+	ArticleBody   string          `json:"articleBody"`
+	ContentURL    string          `json:"contentUrl"`
+	EmbedURL      string          `json:"embedUrl"`
+	Author        json.RawMessage `json:"author"`
+}
+
+// parseJSONLDAuthor handles schema.org's two common shapes for "author": a plain
+// string, or a Person/Organization object with a "name".
+func parseJSONLDAuthor(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return name
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return obj.Name
+	}
+	var list []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &list); err == nil && len(list) > 0 {
+		return list[0].Name
+	}
+	return ""
+}
+
+// parseJSONLDType handles "@type" being either a single string or an array of
+// strings (schema.org allows a node to declare multiple types).
+func parseJSONLDType(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil && len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+// parseJSONLD extracts structured data from the text content of every
+// <script type="application/ld+json"> block on a page, handling both a bare node and
+// the "@graph" array form sites use to bundle several nodes in one block. Later
+// blocks/nodes override earlier ones field-by-field, so a page with several JSON-LD
+// islands still yields one merged result.
+func parseJSONLD(blocks []string) WebpageStructuredData {
+	var merged WebpageStructuredData
+
+	var visit func(node jsonLDNode)
+	visit = func(node jsonLDNode) {
+		if len(node.Graph) > 0 {
+			for _, child := range node.Graph {
+				visit(child)
+			}
+			return
+		}
+
+		if t := parseJSONLDType(node.Type); t != "" {
+			merged.Type = t
+		}
+		if node.Headline != "" {
+			merged.Title = node.Headline
+		} else if node.Name != "" && merged.Title == "" {
+			merged.Title = node.Name
+		}
+		if author := parseJSONLDAuthor(node.Author); author != "" {
+			merged.Author = author
+		}
+		if node.DatePublished != "" {
+			merged.PublishedAt = node.DatePublished
+		}
+		if node.Description != "" {
+			merged.Description = node.Description
+		}
+		if node.ArticleBody != "" {
+			merged.ArticleBody = node.ArticleBody
+		}
+		if node.ContentURL != "" {
+			merged.VideoURL = node.ContentURL
+		} else if node.EmbedURL != "" {
+			merged.VideoURL = node.EmbedURL
+		}
+	}
+
+	for _, block := range blocks {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+
+		// A single script tag can itself contain a JSON array of nodes.
+		var nodes []jsonLDNode
+		if err := json.Unmarshal([]byte(block), &nodes); err == nil {
+			for _, node := range nodes {
+				visit(node)
+			}
+			continue
+		}
+
+		var node jsonLDNode
+		if err := json.Unmarshal([]byte(block), &node); err == nil {
+			visit(node)
+		}
+	}
+
+	return merged
+}
+
+// parseOpenGraph reads OpenGraph and Twitter-card meta tags as a fallback for fields
+// JSON-LD didn't supply, since many sites populate one but not the other.
+func parseOpenGraph(doc *goquery.Document) WebpageStructuredData {
+	var og WebpageStructuredData
+
+	doc.Find("meta").Each(func(_ int, s *goquery.Selection) {
+		key, _ := s.Attr("property")
+		if key == "" {
+			key, _ = s.Attr("name")
+		}
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+		if key == "" || content == "" {
+			return
+		}
+
+		switch key {
+		case "og:title", "twitter:title":
+			if og.Title == "" {
+				og.Title = content
+			}
+		case "og:description", "twitter:description":
+			if og.Description == "" {
+				og.Description = content
+			}
+		case "article:author", "og:article:author":
+			if og.Author == "" {
+				og.Author = content
+			}
+		case "article:published_time", "og:article:published_time":
+			if og.PublishedAt == "" {
+				og.PublishedAt = content
+			}
+		case "og:video", "og:video:url", "twitter:player":
+			if og.VideoURL == "" {
+				og.VideoURL = content
+			}
+		case "og:type":
+			if og.Type == "" {
+				og.Type = content
+			}
+		}
+	})
+
+	return og
+}
+
+// mergeStructured combines JSON-LD and OpenGraph/Twitter-card data, preferring JSON-LD
+// (schema.org's structure is more specific) and falling back to OG/Twitter fields it
+// didn't supply.
+func mergeStructured(jsonLD, og WebpageStructuredData) WebpageStructuredData {
+	merged := jsonLD
+	if merged.Title == "" {
+		merged.Title = og.Title
+	}
+	if merged.Author == "" {
+		merged.Author = og.Author
+	}
+	if merged.PublishedAt == "" {
+		merged.PublishedAt = og.PublishedAt
+	}
+	if merged.Description == "" {
+		merged.Description = og.Description
+	}
+	if merged.VideoURL == "" {
+		merged.VideoURL = og.VideoURL
+	}
+	if merged.Type == "" {
+		merged.Type = og.Type
+	}
+	return merged
+}
+
+// extractStructuredData mines a parsed page for JSON-LD and OpenGraph/Twitter-card
+// structured data, in the spirit of yt-dlp's generic extractor. jsonLDBlocks is the
+// raw text content of every <script type="application/ld+json"> tag on the page.
+func extractStructuredData(doc *goquery.Document, jsonLDBlocks []string) WebpageStructuredData {
+	return mergeStructured(parseJSONLD(jsonLDBlocks), parseOpenGraph(doc))
+}
@@ -0,0 +1,355 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transcript is what a TranscriptProvider returns: flat text plus, when the provider
+// exposes per-caption timing, the segments it was built from.
+type Transcript struct {
+	Text     string
+	Segments []TranscriptSegment
+}
+
+// TranscriptProvider is a single upstream extractTranscript can fetch a video's
+// transcript from. Built-in providers are registered by name into
+// transcriptProviderFactories (see RegisterTranscriptProvider); extractTranscript looks
+// them up by the tokens in Config.TranscriptOrder instead of a hardcoded switch.
+type TranscriptProvider interface {
+	// Name is the Config.TranscriptOrder token that selects this provider, e.g. "ytapi".
+	Name() string
+	// Fetch retrieves videoID's transcript, in lang (a BCP-47 code, "" for the provider's
+	// default) if the provider supports language selection.
+	Fetch(ctx context.Context, videoID, videoURL, lang string) (Transcript, error)
+}
+
+// TranscriptProviderFactory builds a TranscriptProvider bound to a specific
+// YouTubeExtractor, so it can reuse that extractor's HTTPClient (and thus its
+// domain-specific proxy/header overrides) and Config the same way every other
+// e.fetchXxx method already does.
+type TranscriptProviderFactory func(e *YouTubeExtractor) TranscriptProvider
+
+var transcriptProviderFactories = map[string]TranscriptProviderFactory{}
+
+// RegisterTranscriptProvider adds a named factory to the registry that every
+// NewYouTubeExtractor call builds providers from. Called from this file's init() for the
+// built-ins; an out-of-tree extension can call it too, as long as it runs before
+// NewYouTubeExtractor.
+func RegisterTranscriptProvider(name string, factory TranscriptProviderFactory) {
+	transcriptProviderFactories[name] = factory
+}
+
+func init() {
+	RegisterTranscriptProvider("ytapi", func(e *YouTubeExtractor) TranscriptProvider { return ytapiTranscriptProvider{e} })
+	RegisterTranscriptProvider("youtube_api", func(e *YouTubeExtractor) TranscriptProvider { return ytapiTranscriptProvider{e} })
+	RegisterTranscriptProvider("youtubeapi", func(e *YouTubeExtractor) TranscriptProvider { return ytapiTranscriptProvider{e} })
+	RegisterTranscriptProvider("tactiq", func(e *YouTubeExtractor) TranscriptProvider { return tactiqTranscriptProvider{e} })
+	RegisterTranscriptProvider("html", func(e *YouTubeExtractor) TranscriptProvider { return htmlTranscriptProvider{e} })
+	RegisterTranscriptProvider("ytdlp", func(e *YouTubeExtractor) TranscriptProvider { return ytdlpTranscriptProvider{e} })
+}
+
+// transcriptCircuitBreaker trips after consecutive failures and, while open, has
+// extractTranscript skip the provider outright instead of spending retries and a
+// timeout on an upstream that's already down.
+type transcriptCircuitBreaker struct {
+	mu                  sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+}
+
+// allow reports whether the breaker is closed (or its cooldown has elapsed).
+func (cb *transcriptCircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return time.Now().After(cb.openUntil)
+}
+
+func (cb *transcriptCircuitBreaker) recordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.consecutiveFailures = 0
+	cb.openUntil = time.Time{}
+}
+
+func (cb *transcriptCircuitBreaker) recordFailure(threshold int, cooldown time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.consecutiveFailures++
+	if cb.consecutiveFailures >= threshold {
+		cb.openUntil = time.Now().Add(cooldown)
+	}
+}
+
+// fetchTranscriptWithRetry calls provider.Fetch, retrying up to e.transcriptMaxRetries
+// times with exponential backoff (starting at e.transcriptRetryBackoff) on failure.
+func (e *YouTubeExtractor) fetchTranscriptWithRetry(ctx context.Context, provider TranscriptProvider, videoID, videoURL, lang string) (Transcript, error) {
+	delay := e.transcriptRetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= e.transcriptMaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return Transcript{}, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		result, err := provider.Fetch(ctx, videoID, videoURL, lang)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+		slog.Warn("YouTubeExtractor: transcript provider attempt failed", "provider", provider.Name(), "attempt", attempt, "video_id", videoID, "error", err)
+	}
+	return Transcript{}, lastErr
+}
+
+// ytapiTranscriptProvider wraps the transcript microservice call in the TranscriptProvider
+// interface.
+type ytapiTranscriptProvider struct{ e *YouTubeExtractor }
+
+func (p ytapiTranscriptProvider) Name() string { return "ytapi" }
+
+func (p ytapiTranscriptProvider) Fetch(ctx context.Context, videoID, videoURL, lang string) (Transcript, error) {
+	text, segments, err := p.e.extractTranscriptWithYTAPI(ctx, videoID, TranscriptOptions{Language: lang})
+	if err != nil {
+		return Transcript{}, err
+	}
+	return Transcript{Text: text, Segments: segments}, nil
+}
+
+// tactiqTranscriptProvider wraps the Tactiq reverse-engineered endpoint in the
+// TranscriptProvider interface.
+type tactiqTranscriptProvider struct{ e *YouTubeExtractor }
+
+func (p tactiqTranscriptProvider) Name() string { return "tactiq" }
+
+func (p tactiqTranscriptProvider) Fetch(ctx context.Context, videoID, videoURL, lang string) (Transcript, error) {
+	text, segments, err := p.e.extractTranscriptWithTactiq(ctx, videoURL, TranscriptOptions{Language: lang})
+	if err != nil {
+		return Transcript{}, err
+	}
+	return Transcript{Text: text, Segments: segments}, nil
+}
+
+// htmlTranscriptProvider scrapes the `timedtext` caption URL out of the watch page's
+// ytInitialPlayerResponse (the same captionTracks list yt-dlp reads), then fetches and
+// parses that URL's XML caption track directly, needing neither an API key nor a
+// third-party service.
+type htmlTranscriptProvider struct{ e *YouTubeExtractor }
+
+func (p htmlTranscriptProvider) Name() string { return "html" }
+
+func (p htmlTranscriptProvider) Fetch(ctx context.Context, videoID, videoURL, lang string) (Transcript, error) {
+	watchURL := videoURL
+	if watchURL == "" {
+		watchURL = "https://www.youtube.com/watch?v=" + videoID
+	}
+
+	pageHTML, err := p.e.fetchWatchPageHTML(ctx, watchURL)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("html transcript: %w", err)
+	}
+
+	raw, err := extractJSONVar(pageHTML, ytInitialPlayerResponseRe)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("html transcript: ytInitialPlayerResponse: %w", err)
+	}
+
+	var playerResponse struct {
+		Captions struct {
+			PlayerCaptionsTracklistRenderer struct {
+				CaptionTracks []struct {
+					BaseURL      string `json:"baseUrl"`
+					LanguageCode string `json:"languageCode"`
+				} `json:"captionTracks"`
+			} `json:"playerCaptionsTracklistRenderer"`
+		} `json:"captions"`
+	}
+	if err := json.Unmarshal(raw, &playerResponse); err != nil {
+		return Transcript{}, fmt.Errorf("html transcript: decode player response: %w", err)
+	}
+
+	tracks := playerResponse.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks
+	if len(tracks) == 0 {
+		return Transcript{}, fmt.Errorf("html transcript: no caption tracks available")
+	}
+
+	timedTextURL := tracks[0].BaseURL
+	for _, track := range tracks {
+		if lang != "" && track.LanguageCode == lang {
+			timedTextURL = track.BaseURL
+			break
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, timedTextURL, nil)
+	if err != nil {
+		return Transcript{}, err
+	}
+	resp, err := p.e.DoDecoded(req)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("html transcript: fetch timedtext: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Transcript{}, fmt.Errorf("html transcript: timedtext bad status code: %d", resp.StatusCode)
+	}
+
+	var timedText struct {
+		Texts []struct {
+			Start float64 `xml:"start,attr"`
+			Dur   float64 `xml:"dur,attr"`
+			Text  string  `xml:",chardata"`
+		} `xml:"text"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&timedText); err != nil {
+		return Transcript{}, fmt.Errorf("html transcript: decode timedtext xml: %w", err)
+	}
+
+	segments := make([]TranscriptSegment, 0, len(timedText.Texts))
+	for _, t := range timedText.Texts {
+		text := html.UnescapeString(t.Text)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, TranscriptSegment{Start: t.Start, Duration: t.Dur, Text: text})
+	}
+
+	transcript := joinSegments(segments)
+	if transcript == "" {
+		return Transcript{}, fmt.Errorf("html transcript: empty transcript")
+	}
+	return Transcript{Text: transcript, Segments: segments}, nil
+}
+
+// ytdlpTranscriptProvider shells out to a yt-dlp binary to download a video's subtitles,
+// for deployments willing to trade a subprocess spawn per video for yt-dlp's broader
+// format/language support. Gated by Config.YTDLPTranscriptEnabled since it requires
+// yt-dlp to be installed.
+type ytdlpTranscriptProvider struct{ e *YouTubeExtractor }
+
+func (p ytdlpTranscriptProvider) Name() string { return "ytdlp" }
+
+func (p ytdlpTranscriptProvider) Fetch(ctx context.Context, videoID, videoURL, lang string) (Transcript, error) {
+	if p.e.Config == nil || !p.e.Config.YTDLPTranscriptEnabled {
+		return Transcript{}, fmt.Errorf("ytdlp transcript provider is disabled")
+	}
+	watchURL := videoURL
+	if watchURL == "" {
+		watchURL = "https://www.youtube.com/watch?v=" + videoID
+	}
+	if lang == "" {
+		lang = "en"
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ytdlp-transcript-*")
+	if err != nil {
+		return Transcript{}, fmt.Errorf("ytdlp transcript: temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	outputTemplate := filepath.Join(tmpDir, "%(id)s.%(ext)s")
+	cmd := exec.CommandContext(ctx, p.e.Config.YTDLPPath,
+		"--skip-download",
+		"--write-sub", "--write-auto-sub",
+		"--sub-lang", lang,
+		"--sub-format", "vtt",
+		"-o", outputTemplate,
+		watchURL,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return Transcript{}, fmt.Errorf("ytdlp transcript: %w\n%s", err, output)
+	}
+
+	vttPath := filepath.Join(tmpDir, videoID+"."+lang+".vtt")
+	vttBytes, err := os.ReadFile(vttPath)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("ytdlp transcript: read subtitle file: %w", err)
+	}
+
+	segments := parseVTTSegments(vttBytes)
+	transcript := joinSegments(segments)
+	if transcript == "" {
+		return Transcript{}, fmt.Errorf("ytdlp transcript: empty transcript")
+	}
+	return Transcript{Text: transcript, Segments: segments}, nil
+}
+
+// parseVTTSegments extracts caption segments from a WebVTT file's "start --> end" cue
+// timestamps (HH:MM:SS.mmm) and the text line(s) that follow, the format yt-dlp writes
+// subtitles in.
+func parseVTTSegments(vtt []byte) []TranscriptSegment {
+	var segments []TranscriptSegment
+	lines := strings.Split(string(vtt), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		start, end, ok := parseVTTCueTiming(line)
+		if !ok {
+			continue
+		}
+		var textLines []string
+		for j := i + 1; j < len(lines) && strings.TrimSpace(lines[j]) != ""; j++ {
+			textLines = append(textLines, strings.TrimSpace(lines[j]))
+		}
+		text := strings.TrimSpace(strings.Join(textLines, " "))
+		if text == "" {
+			continue
+		}
+		segments = append(segments, TranscriptSegment{Start: start, Duration: end - start, Text: text})
+	}
+	return segments
+}
+
+// parseVTTCueTiming parses a WebVTT cue timing line ("00:00:01.000 --> 00:00:03.500
+// align:start") into start/end seconds. Returns ok=false for any other line.
+func parseVTTCueTiming(line string) (start, end float64, ok bool) {
+	parts := strings.SplitN(line, "-->", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	start, startErr := parseVTTTimestamp(strings.TrimSpace(parts[0]))
+	endField := strings.Fields(strings.TrimSpace(parts[1]))
+	if len(endField) == 0 {
+		return 0, 0, false
+	}
+	end, endErr := parseVTTTimestamp(endField[0])
+	if startErr != nil || endErr != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// parseVTTTimestamp parses a WebVTT timestamp ("HH:MM:SS.mmm" or "MM:SS.mmm") into
+// seconds.
+func parseVTTTimestamp(ts string) (float64, error) {
+	fields := strings.Split(ts, ":")
+	var h, m int
+	var s float64
+	var err error
+	switch len(fields) {
+	case 3:
+		_, err = fmt.Sscanf(ts, "%d:%d:%f", &h, &m, &s)
+	case 2:
+		_, err = fmt.Sscanf(ts, "%d:%f", &m, &s)
+	default:
+		return 0, fmt.Errorf("invalid VTT timestamp: %q", ts)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return float64(h*3600+m*60) + s, nil
+}
@@ -3,10 +3,10 @@ package extractor
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"regexp"
 	"strings"
 	"sync"
@@ -16,10 +16,56 @@ import (
 	"github.com/go-rod/rod/lib/proto"
 	jsoniter "github.com/json-iterator/go"
 
+	"web-search-api-for-llms/internal/auth"
 	"web-search-api-for-llms/internal/browser"
 	"web-search-api-for-llms/internal/config"
 )
 
+const (
+	twitterGuestActivateURL = "https://api.twitter.com/1.1/guest/activate.json"
+
+	// twitterBearerToken is the public, read-only bearer token twitter.com's own web
+	// client embeds in every logged-out page load. It's widely documented by other
+	// unofficial API clients and only grants access to public data.
+	twitterBearerToken = "AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
+
+	// GraphQL query IDs twitter.com's web client currently uses for these operations.
+	// Twitter rotates these from time to time; if guest extraction starts failing with
+	// 404s, these are the first thing to refresh against a current twitter.com page load.
+	twitterQueryIDTweetDetail      = "zXaXQgfyR4GxE21uwYQSyA"
+	twitterQueryIDUserByScreenName = "G3KGOASz96M-Qu0nwmGXNg"
+	twitterQueryIDUserTweets       = "HuTx74BxAnezK1gWvYY7zg"
+	twitterQueryIDSearchTimeline   = "gkjsKepM6gl_HmFWoWKfgg"
+
+	// twitterMaxTweetDetailPages bounds the cursor-pagination loop in fetchTweetDetailGuest
+	// regardless of TwitterMaxComments, so a misbehaving or unexpectedly chatty response
+	// can't spin the loop forever.
+	twitterMaxTweetDetailPages = 25
+)
+
+// twitterGraphQLFeatures are the feature-flag toggles twitter.com's web client sends
+// alongside TweetDetail/UserTweets requests. The API rejects requests missing flags it
+// expects, so this is kept roughly in sync with a current twitter.com page load.
+var twitterGraphQLFeatures = map[string]interface{}{
+	"responsive_web_graphql_exclude_directive_enabled":                       true,
+	"verified_phone_label_enabled":                                           false,
+	"responsive_web_graphql_timeline_navigation_enabled":                     true,
+	"responsive_web_graphql_skip_user_profile_image_extensions_enabled":      false,
+	"tweetypie_unmention_optimization_enabled":                               true,
+	"vibe_api_enabled":                                                       true,
+	"responsive_web_edit_tweet_api_enabled":                                  true,
+	"graphql_is_translatable_rweb_tweet_is_translatable_enabled":             true,
+	"view_counts_everywhere_api_enabled":                                     true,
+	"longform_notetweets_consumption_enabled":                                true,
+	"tweet_awards_web_tipping_enabled":                                       false,
+	"freedom_of_speech_not_reach_fetch_enabled":                              true,
+	"standardized_nudges_misinfo":                                            true,
+	"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+	"longform_notetweets_rich_text_read_enabled":                             true,
+	"longform_notetweets_inline_media_enabled":                               true,
+	"responsive_web_enhance_cards_enabled":                                   false,
+}
+
 // TweetDetailResponse defines the structure for the entire JSON response from the Twitter API.
 type TweetDetailResponse struct {
 	Data struct {
@@ -66,6 +112,10 @@ type TweetResult struct {
 		} `json:"user_results"`
 	} `json:"core"`
 	Legacy TweetLegacy `json:"legacy"`
+	// QuotedStatusResult is set when this tweet quotes another one.
+	QuotedStatusResult *struct {
+		Result TweetResult `json:"result"`
+	} `json:"quoted_status_result"`
 }
 
 // UserResult holds information about a Twitter user.
@@ -79,11 +129,53 @@ type UserResult struct {
 
 // TweetLegacy contains the textual content and metadata of a tweet.
 type TweetLegacy struct {
-	FullText      string `json:"full_text"`
-	CreatedAt     string `json:"created_at"`
-	FavoriteCount int    `json:"favorite_count"`
-	ReplyCount    int    `json:"reply_count"`
-	RetweetCount  int    `json:"retweet_count"`
+	FullText             string              `json:"full_text"`
+	CreatedAt            string              `json:"created_at"`
+	FavoriteCount        int                 `json:"favorite_count"`
+	ReplyCount           int                 `json:"reply_count"`
+	RetweetCount         int                 `json:"retweet_count"`
+	InReplyToStatusIDStr string              `json:"in_reply_to_status_id_str"`
+	Entities             tweetLegacyEntities `json:"entities"`
+	ExtendedEntities     tweetLegacyEntities `json:"extended_entities"`
+	// RetweetedStatusResult is set when this tweet is a plain retweet, in which case
+	// FullText is just a truncated "RT @user: ..." and the real content lives here.
+	RetweetedStatusResult *struct {
+		Result TweetResult `json:"result"`
+	} `json:"retweeted_status_result"`
+}
+
+// tweetLegacyEntities mirrors the shape shared by legacy.entities and
+// legacy.extended_entities in the TweetDetail API response. extended_entities carries the
+// same Media array as entities but with the full set of video/gif variant bitrates, so it's
+// preferred over entities.Media when present (see mediaFromLegacy).
+type tweetLegacyEntities struct {
+	URLs []struct {
+		URL         string `json:"url"`
+		ExpandedURL string `json:"expanded_url"`
+		DisplayURL  string `json:"display_url"`
+	} `json:"urls"`
+	UserMentions []struct {
+		Name       string `json:"name"`
+		ScreenName string `json:"screen_name"`
+	} `json:"user_mentions"`
+	Hashtags []struct {
+		Text string `json:"text"`
+	} `json:"hashtags"`
+	Media []tweetLegacyMedia `json:"media"`
+}
+
+// tweetLegacyMedia is a single entry in legacy.entities.media / legacy.extended_entities.media.
+type tweetLegacyMedia struct {
+	Type      string `json:"type"` // "photo", "video", or "animated_gif"
+	ShortURL  string `json:"url"`
+	MediaURL  string `json:"media_url_https"`
+	VideoInfo struct {
+		Variants []struct {
+			Bitrate     int    `json:"bitrate"`
+			ContentType string `json:"content_type"`
+			URL         string `json:"url"`
+		} `json:"variants"`
+	} `json:"video_info"`
 }
 
 // TwitterProfileResult holds the formatted result for a profile URL extraction.
@@ -103,31 +195,88 @@ type TwitterExtractor struct {
 	BaseExtractor
 	BrowserPool *browser.Pool
 	Config      *config.AppConfig
-	cookieMutex sync.RWMutex // Mutex for cookie file access
+
+	// Credentials persists session cookies per account across extractions (see
+	// internal/auth), replacing the old single twitter_cookies.json file.
+	Credentials *auth.CredentialStore
+	// ChallengeSolver clears the "unusual login" email-verification and 2FA screens
+	// loginToTwitter hits during automated login; selected by Config.TwitterChallengeMode.
+	ChallengeSolver auth.ChallengeSolver
+	// Accounts rotates session-auth extractions across the accounts configured under
+	// Config.TwitterAccounts instead of every goroutine sharing one session.
+	Accounts *AccountPool
+
+	// Guest token used by the unauthenticated GraphQL client (see getGuestToken).
+	guestToken       string
+	guestTokenExpiry time.Time
+	guestTokenMutex  sync.Mutex
 }
 
 // NewTwitterExtractor creates a new TwitterExtractor
-func NewTwitterExtractor(appConfig *config.AppConfig, browserPool *browser.Pool, client *http.Client) *TwitterExtractor {
+func NewTwitterExtractor(appConfig *config.AppConfig, browserPool *browser.Pool, factory TransportFactory) (*TwitterExtractor, error) {
+	base, err := NewBaseExtractor(appConfig, factory, "x.com")
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := auth.NewCredentialStore(appConfig.TwitterCredentialsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize twitter credential store: %w", err)
+	}
+
 	return &TwitterExtractor{
-		BaseExtractor: NewBaseExtractor(appConfig, client),
-		BrowserPool:   browserPool,
-		Config:        appConfig,
+		BaseExtractor:   base,
+		BrowserPool:     browserPool,
+		Config:          appConfig,
+		Credentials:     credentials,
+		ChallengeSolver: newTwitterChallengeSolver(appConfig),
+		Accounts:        NewAccountPool(browserPool, appConfig),
+	}, nil
+}
+
+// newTwitterChallengeSolver builds the auth.ChallengeSolver selected by
+// appConfig.TwitterChallengeMode.
+func newTwitterChallengeSolver(appConfig *config.AppConfig) auth.ChallengeSolver {
+	switch appConfig.TwitterChallengeMode {
+	case "file":
+		return auth.FileChallengeSolver{Dir: appConfig.TwitterChallengeDir}
+	case "http":
+		return auth.HTTPChallengeSolver{URL: appConfig.TwitterChallengeCallbackURL}
+	default:
+		return auth.EnvChallengeSolver{Prefix: "TWITTER_CHALLENGE"}
 	}
 }
 
 // Extract fetches Twitter/X post content and comments
 
-func (e *TwitterExtractor) Extract(targetURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
+func (e *TwitterExtractor) Extract(parentCtx context.Context, targetURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("TwitterExtractor: Starting extraction", "url", targetURL)
 	result.SourceType = "twitter"
 
-	// Create a timeout context for the entire extraction
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	// Create a timeout context for the entire extraction, derived from the caller's
+	// context so that a client disconnect aborts the in-flight browser pages too. The
+	// guest-token GraphQL path is much faster than this in practice, but a single
+	// timeout still bounds the slower session fallback.
+	ctx, cancel := context.WithTimeout(parentCtx, 2*time.Minute)
 	defer cancel()
 
-	// Check if we have Twitter credentials
-	if e.Config.TwitterUsername == "" || e.Config.TwitterPassword == "" {
-		return fmt.Errorf("twitter credentials not configured")
+	authMode := e.Config.TwitterAuthMode
+	if authMode == "" {
+		authMode = "guest"
+	}
+
+	if query, ok := twitterSearchQueryFromURL(targetURL); ok {
+		if endpoint != "/extract" {
+			return fmt.Errorf("twitter search extraction is only available on the /extract endpoint")
+		}
+		searchResult, err := e.Search(ctx, query, "", "", 0)
+		if err != nil {
+			return fmt.Errorf("search extraction failed: %w", err)
+		}
+		result.Data = searchResult
+		result.ProcessedSuccessfully = true
+		slog.Info("TwitterExtractor: Successfully extracted search results", "query", query, "count", len(searchResult.Tweets))
+		return nil
 	}
 
 	if isProfileURL(targetURL) {
@@ -135,7 +284,7 @@ func (e *TwitterExtractor) Extract(targetURL string, endpoint string, maxChars *
 		if endpoint != "/extract" {
 			return fmt.Errorf("twitter profile URL extraction is only available on the /extract endpoint")
 		}
-		return e.extractFromProfileURL(ctx, targetURL, maxChars, result)
+		return e.extractFromProfileURL(ctx, targetURL, maxChars, result, authMode)
 	}
 
 	// Handle single tweet URL (existing logic)
@@ -146,8 +295,7 @@ func (e *TwitterExtractor) Extract(targetURL string, endpoint string, maxChars *
 
 	slog.Debug("TwitterExtractor: Extracted Tweet ID", "tweet_id", tweetID, "url", targetURL)
 
-	// Extract tweet data using browser automation with context
-	tweetData, err := e.extractTweetDataWithContext(ctx, tweetID, targetURL)
+	tweetData, err := e.fetchTweetData(ctx, tweetID, targetURL, authMode)
 	if err != nil {
 		return fmt.Errorf("extraction failed: %w", err)
 	}
@@ -186,6 +334,212 @@ func (e *TwitterExtractor) Extract(targetURL string, endpoint string, maxChars *
 	return nil
 }
 
+// fetchTweetData resolves a single tweet according to authMode: "guest" uses the
+// unauthenticated GraphQL client, "session" always drives a logged-in browser, and
+// "auto" tries guest first and only pays for a browser session if that fails.
+func (e *TwitterExtractor) fetchTweetData(ctx context.Context, tweetID, tweetURL, authMode string) (*TwitterData, error) {
+	switch authMode {
+	case "session":
+		if e.Config.TwitterUsername == "" || e.Config.TwitterPassword == "" {
+			return nil, fmt.Errorf("twitter credentials not configured for session auth mode")
+		}
+		return e.extractTweetDataWithContext(ctx, tweetID, tweetURL)
+	case "auto":
+		data, err := e.fetchTweetDetailGuest(ctx, tweetID)
+		if err == nil {
+			return data, nil
+		}
+		slog.Warn("TwitterExtractor: guest-token extraction failed, falling back to session auth", "tweet_id", tweetID, "error", err)
+		if e.Config.TwitterUsername == "" || e.Config.TwitterPassword == "" {
+			return nil, fmt.Errorf("guest extraction failed and no session credentials configured: %w", err)
+		}
+		return e.extractTweetDataWithContext(ctx, tweetID, tweetURL)
+	default: // "guest"
+		return e.fetchTweetDetailGuest(ctx, tweetID)
+	}
+}
+
+// getGuestToken obtains (and caches) a guest token from Twitter's activation endpoint.
+// Guest tokens are valid for a few hours in practice; getGuestToken refreshes well
+// before that to avoid racing an in-flight request against expiry.
+func (e *TwitterExtractor) getGuestToken(ctx context.Context) (string, error) {
+	e.guestTokenMutex.Lock()
+	defer e.guestTokenMutex.Unlock()
+
+	if e.guestToken != "" && time.Now().Before(e.guestTokenExpiry) {
+		return e.guestToken, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, twitterGuestActivateURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create guest token request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+twitterBearerToken)
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to activate guest token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errorForStatus(resp, "guest token activation failed")
+	}
+
+	var tokenResp struct {
+		GuestToken string `json:"guest_token"`
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode guest token response: %w", err)
+	}
+	if tokenResp.GuestToken == "" {
+		return "", fmt.Errorf("guest token response did not contain a token")
+	}
+
+	e.guestToken = tokenResp.GuestToken
+	e.guestTokenExpiry = time.Now().Add(2 * time.Hour)
+	return e.guestToken, nil
+}
+
+// graphQLGet issues a GET against one of Twitter's GraphQL endpoints using a guest
+// token, and returns the raw response body for the caller to unmarshal into whichever
+// shape that operation returns.
+func (e *TwitterExtractor) graphQLGet(ctx context.Context, guestToken, apiURL string, variables, features map[string]interface{}) ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL variables: %w", err)
+	}
+	featuresJSON, err := json.Marshal(features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL features: %w", err)
+	}
+
+	q := url.Values{}
+	q.Set("variables", string(variablesJSON))
+	q.Set("features", string(featuresJSON))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+twitterBearerToken)
+	req.Header.Set("x-guest-token", guestToken)
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("graphql request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graphql response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorForStatus(resp, fmt.Sprintf("graphql request to %s failed", apiURL))
+	}
+
+	return body, nil
+}
+
+// fetchTweetDetailGuest fetches a tweet and its full reply tree via the TweetDetail
+// GraphQL operation, authenticated with a guest token rather than a logged-in session.
+// Unlike the session/browser path (which only ever observes the single TweetDetail response
+// the page itself loads), this re-issues TweetDetail with the `cursor` variable populated
+// from the previous page's cursor entry until no cursor is returned, TwitterMaxComments is
+// reached, or twitterMaxTweetDetailPages is hit. This is dramatically faster than driving a
+// headless browser and works for any public tweet without Twitter credentials.
+func (e *TwitterExtractor) fetchTweetDetailGuest(ctx context.Context, tweetID string) (*TwitterData, error) {
+	guestToken, err := e.getGuestToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain guest token: %w", err)
+	}
+
+	var tweetContent, tweetAuthor string
+	var tweetMedia []TwitterMedia
+	var tweetEntities TwitterEntities
+	var tweetQuotedTweet *TwitterData
+	var comments []TwitterComment
+	cursor := ""
+
+	for page := 0; page < twitterMaxTweetDetailPages; page++ {
+		variables := map[string]interface{}{
+			"focalTweetId":                          tweetID,
+			"with_rux_injections":                   false,
+			"includePromotedContent":                false,
+			"withCommunity":                         true,
+			"withQuickPromoteEligibilityTweetFields": false,
+			"withBirdwatchNotes":                     false,
+			"withVoice":                              true,
+			"withV2Timeline":                         true,
+		}
+		if cursor != "" {
+			variables["cursor"] = cursor
+		}
+
+		apiURL := fmt.Sprintf("https://twitter.com/i/api/graphql/%s/TweetDetail", twitterQueryIDTweetDetail)
+		body, err := e.graphQLGet(ctx, guestToken, apiURL, variables, twitterGraphQLFeatures)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			slog.Warn("TwitterExtractor: paginated TweetDetail request failed, returning thread collected so far", "tweet_id", tweetID, "page", page, "error", err)
+			break
+		}
+
+		var detailResp TweetDetailResponse
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		if err := json.Unmarshal(body, &detailResp); err != nil {
+			if page == 0 {
+				return nil, fmt.Errorf("failed to parse TweetDetail response: %w", err)
+			}
+			slog.Warn("TwitterExtractor: failed to parse a paginated TweetDetail response, returning thread collected so far", "tweet_id", tweetID, "page", page, "error", err)
+			break
+		}
+
+		pageContent, pageAuthor, pageMedia, pageEntities, pageQuotedTweet, pageComments, nextCursor := parseTweetDetailPage(&detailResp)
+		if pageContent != "" {
+			tweetContent = pageContent
+			tweetAuthor = pageAuthor
+			tweetMedia = pageMedia
+			tweetEntities = pageEntities
+			tweetQuotedTweet = pageQuotedTweet
+		}
+		comments = append(comments, pageComments...)
+
+		if nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+
+		if e.Config.TwitterMaxComments > 0 && len(comments) >= e.Config.TwitterMaxComments {
+			break
+		}
+	}
+
+	if tweetContent == "" {
+		return nil, fmt.Errorf("could not find main tweet content in the API response")
+	}
+
+	if e.Config.TwitterMaxComments > 0 && len(comments) > e.Config.TwitterMaxComments {
+		comments = comments[:e.Config.TwitterMaxComments]
+	}
+
+	return &TwitterData{
+		TweetContent:  tweetContent,
+		TweetAuthor:   tweetAuthor,
+		Comments:      buildCommentTree(comments, tweetID, e.Config.TwitterMaxThreadDepth),
+		TotalComments: len(comments),
+		Media:         tweetMedia,
+		QuotedTweet:   tweetQuotedTweet,
+		Entities:      tweetEntities,
+	}, nil
+}
+
 // extractTweetID extracts the tweet ID from various Twitter/X URL formats
 func extractTweetID(tweetURL string) string {
 	// Handle URLs without protocol
@@ -273,11 +627,15 @@ func (e *TwitterExtractor) extractTweetDataWithContext(ctx context.Context, twee
 	default:
 	}
 
-	// Get browser from pool
-	browser := e.BrowserPool.Get()
-	defer e.BrowserPool.Return(browser)
+	// Check out a browser together with the account it's authenticated as, rotating
+	// across Accounts instead of every caller sharing one session.
+	session, err := e.Accounts.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire twitter account: %w", err)
+	}
+	defer e.Accounts.Release(session)
 
-	page, err := browser.Page(proto.TargetCreateTarget{URL: ""})
+	page, err := session.Browser.Page(proto.TargetCreateTarget{URL: ""})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
@@ -289,43 +647,49 @@ func (e *TwitterExtractor) extractTweetDataWithContext(ctx context.Context, twee
 		UserAgent: userAgent,
 	})
 
-	// Try to load saved cookies
-	cookiesFile := "twitter_cookies.json"
-	if e.loadCookies(page, cookiesFile) {
-		slog.Info("TwitterExtractor: Loaded saved session cookies")
+	// Bind the page to ctx so every blocking rod call underneath (navigation, element
+	// waits, MustEval) returns as soon as ctx is done instead of running to its own
+	// internal timeout regardless of the caller's deadline.
+	page = page.Context(ctx)
+
+	// Try to load a previously saved session for this account
+	username := session.Username
+	if e.loadCookies(page, username) {
+		slog.Info("TwitterExtractor: Loaded saved session cookies", "username", username)
 		// Test if we're still logged in by navigating to the home page with a timeout
 		slog.Debug("TwitterExtractor: Navigating to x.com/home to check session status")
-		err := page.Timeout(5 * time.Second).Navigate("https://x.com/home")
+		err := page.Timeout(e.Config.TwitterNavigationTimeout).Navigate("https://x.com/home")
 		if err != nil {
 			slog.Warn("TwitterExtractor: Failed to navigate to home page to check session, assuming session is expired and logging in.", "error", err)
-			if loginErr := e.loginToTwitter(page); loginErr != nil {
+			if loginErr := e.loginToTwitter(ctx, page, session.Username, session.Password); loginErr != nil {
 				return nil, fmt.Errorf("login failed: %w", loginErr)
 			}
-			if saveErr := e.saveCookies(page, cookiesFile); saveErr != nil {
+			if saveErr := e.saveCookies(page, username); saveErr != nil {
 				slog.Warn("TwitterExtractor: Failed to save cookies", "error", saveErr)
 			}
 		} else {
-			page.MustWaitNavigation()
-
-			currentURL := page.MustInfo().URL
+			currentURL, infoErr := pageURLAfterNavigation(page)
+			if infoErr != nil {
+				return nil, infoErr
+			}
 			if strings.Contains(currentURL, "/home") {
 				slog.Info("TwitterExtractor: Session is still valid, skipping login")
 			} else {
 				slog.Info("TwitterExtractor: Session expired, logging in")
-				if loginErr := e.loginToTwitter(page); loginErr != nil {
+				if loginErr := e.loginToTwitter(ctx, page, session.Username, session.Password); loginErr != nil {
 					return nil, fmt.Errorf("login failed: %w", loginErr)
 				}
-				if saveErr := e.saveCookies(page, cookiesFile); saveErr != nil {
+				if saveErr := e.saveCookies(page, username); saveErr != nil {
 					slog.Warn("TwitterExtractor: Failed to save cookies", "error", saveErr)
 				}
 			}
 		}
 	} else {
-		slog.Info("TwitterExtractor: No saved session found, logging in")
-		if err := e.loginToTwitter(page); err != nil {
+		slog.Info("TwitterExtractor: No saved session found, logging in", "username", username)
+		if err := e.loginToTwitter(ctx, page, session.Username, session.Password); err != nil {
 			return nil, fmt.Errorf("login failed: %w", err)
 		}
-		if err := e.saveCookies(page, cookiesFile); err != nil {
+		if err := e.saveCookies(page, username); err != nil {
 			slog.Warn("TwitterExtractor: Failed to save cookies", "error", err)
 		}
 	}
@@ -349,6 +713,8 @@ func (e *TwitterExtractor) extractTweetDataWithContext(ctx context.Context, twee
 			return
 		}
 
+		e.Accounts.RecordResponse(username, ctx.Response.Payload().ResponseCode, ctx.Response.Headers())
+
 		var apiResponse TweetDetailResponse
 		json := jsoniter.ConfigCompatibleWithStandardLibrary
 		if err := json.Unmarshal(ctx.Response.Payload().Body, &apiResponse); err != nil {
@@ -366,75 +732,122 @@ func (e *TwitterExtractor) extractTweetDataWithContext(ctx context.Context, twee
 		return nil, fmt.Errorf("failed to navigate to tweet: %w", err)
 	}
 
-	// Wait for the API response or timeout
+	// Wait for the API response, a configurable timeout, or ctx cancellation/deadline,
+	// whichever comes first.
 	select {
 	case apiResponse := <-apiResponseChan:
 		slog.Info("TwitterExtractor: Successfully captured TweetDetail API response")
 		return e.parseTweetDetailResponse(apiResponse)
 	case err := <-errChan:
 		return nil, err
-	case <-time.After(15 * time.Second):
+	case <-ctx.Done():
+		return nil, fmt.Errorf("context done while waiting for TweetDetail API response: %w", ctx.Err())
+	case <-time.After(e.Config.TwitterCommentCollectionTimeout):
 		return nil, fmt.Errorf("timed out waiting for TweetDetail API response")
 	}
 }
 
-// loginToTwitter handles the login process
-func (e *TwitterExtractor) loginToTwitter(page *rod.Page) error {
-	// Navigate to Twitter login
-	page.MustNavigate("https://x.com/i/flow/login")
-	page.MustElement(`input[autocomplete="username"]`).MustWaitVisible()
-
-	// Enter username
-	usernameField := page.MustElement(`input[autocomplete="username"]`)
-	usernameField.MustSelectAllText().MustInput(e.Config.TwitterUsername)
-
-	// Click Next button
-	slog.Debug("TwitterExtractor: Clicking Next button")
-	clickResult := page.MustEval(`
-		() => {
-			const buttons = Array.from(document.querySelectorAll('div[role="button"], button'));
-			const nextButton = buttons.find(btn => btn.textContent.trim() === 'Next');
-			if (nextButton) {
-				nextButton.click();
-				return true;
-			}
-			return false;
+// recoverToError runs f and converts any panic it raises (notably rod's Must* family,
+// which panics on error — including when a panic comes from the page's bound context
+// being cancelled or hitting its deadline) into a returned error, so a cancelled request
+// fails that one extraction instead of taking down the goroutine running it.
+func recoverToError(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("browser operation failed: %v", r)
 		}
-	`)
+	}()
+	f()
+	return nil
+}
 
-	if !clickResult.Bool() {
-		return fmt.Errorf("could not find or click Next button")
-	}
+// pageURLAfterNavigation waits for the in-flight navigation page.Navigate started to
+// finish and returns the resulting URL, recovering from the panic rod's Must* helpers
+// raise on error (including cancellation of page's bound context) instead of letting it
+// escape as a crash.
+func pageURLAfterNavigation(page *rod.Page) (string, error) {
+	var url string
+	err := recoverToError(func() {
+		page.MustWaitNavigation()
+		url = page.MustInfo().URL
+	})
+	return url, err
+}
 
-	page.MustElement(`input[name="password"]`).MustWaitVisible()
+// loginToTwitter handles the login process for the given account. page must already be
+// bound to ctx (see page.Context), so every step here aborts as soon as ctx is done
+// instead of running to rod's own default timeouts.
+func (e *TwitterExtractor) loginToTwitter(ctx context.Context, page *rod.Page, username, password string) error {
+	var currentURL string
+	err := recoverToError(func() {
+		// Navigate to Twitter login
+		page.MustNavigate("https://x.com/i/flow/login")
+		page.MustElement(`input[autocomplete="username"]`).MustWaitVisible()
+
+		// Enter username
+		usernameField := page.MustElement(`input[autocomplete="username"]`)
+		usernameField.MustSelectAllText().MustInput(username)
+
+		// Click Next button
+		slog.Debug("TwitterExtractor: Clicking Next button")
+		clickResult := page.MustEval(`
+			() => {
+				const buttons = Array.from(document.querySelectorAll('div[role="button"], button'));
+				const nextButton = buttons.find(btn => btn.textContent.trim() === 'Next');
+				if (nextButton) {
+					nextButton.click();
+					return true;
+				}
+				return false;
+			}
+		`)
 
-	// Enter password
-	slog.Debug("TwitterExtractor: Entering password")
-	passwordField := page.MustElement(`input[name="password"]`)
-	passwordField.MustSelectAllText().MustInput(e.Config.TwitterPassword)
+		if !clickResult.Bool() {
+			panic("could not find or click Next button")
+		}
 
-	// Click Log in button
-	slog.Debug("TwitterExtractor: Clicking Log in button")
-	loginResult := page.MustEval(`
-		() => {
-			const buttons = Array.from(document.querySelectorAll('div[role="button"], button'));
-			const loginButton = buttons.find(btn => btn.textContent.trim() === 'Log in');
-			if (loginButton) {
-				loginButton.click();
-				return true;
+		page.MustElement(`input[name="password"]`).MustWaitVisible()
+
+		// Enter password
+		slog.Debug("TwitterExtractor: Entering password")
+		passwordField := page.MustElement(`input[name="password"]`)
+		passwordField.MustSelectAllText().MustInput(password)
+
+		// Click Log in button
+		slog.Debug("TwitterExtractor: Clicking Log in button")
+		loginResult := page.MustEval(`
+			() => {
+				const buttons = Array.from(document.querySelectorAll('div[role="button"], button'));
+				const loginButton = buttons.find(btn => btn.textContent.trim() === 'Log in');
+				if (loginButton) {
+					loginButton.click();
+					return true;
+				}
+				return false;
 			}
-			return false;
+		`)
+
+		if !loginResult.Bool() {
+			panic("could not find or click Log in button")
 		}
-	`)
 
-	if !loginResult.Bool() {
-		return fmt.Errorf("could not find or click Log in button")
+		page.MustWaitNavigation()
+		currentURL = page.MustInfo().URL
+	})
+	if err != nil {
+		return err
 	}
 
-	page.MustWaitNavigation()
+	if err := e.resolveLoginChallenges(ctx, page, username); err != nil {
+		return fmt.Errorf("login challenge not resolved: %w", err)
+	}
 
-	// Check if login was successful
-	currentURL := page.MustInfo().URL
+	// resolveLoginChallenges may have navigated further (past a challenge screen), so
+	// re-read the URL it left the page on rather than trusting currentURL from before.
+	err = recoverToError(func() { currentURL = page.MustInfo().URL })
+	if err != nil {
+		return err
+	}
 	slog.Info("TwitterExtractor: Login successful", "url", currentURL)
 
 	if strings.Contains(currentURL, "/home") || strings.Contains(currentURL, "/i/status") {
@@ -448,139 +861,369 @@ func (e *TwitterExtractor) loginToTwitter(page *rod.Page) error {
 	return nil
 }
 
-// parseTweetDetailResponse parses the API response and extracts tweet data.
+// twitterChallengeMaxRounds bounds resolveLoginChallenges's loop so a challenge screen
+// that the solver can never clear (e.g. a misconfigured callback) fails the login
+// instead of looping forever.
+const twitterChallengeMaxRounds = 3
+
+// resolveLoginChallenges clears Twitter's "unusual login" email/phone-verification
+// screen and 2FA code prompt, if either follows the password step. Both screens use the
+// same generic text-input element, so the kind is inferred from the prompt copy next to
+// it. It's a no-op if neither screen appears within the short wait below.
+func (e *TwitterExtractor) resolveLoginChallenges(ctx context.Context, page *rod.Page, username string) error {
+	for round := 0; round < twitterChallengeMaxRounds; round++ {
+		input, err := page.Timeout(e.Config.TwitterLoginChallengeTimeout).Element(`input[data-testid="ocfEnterTextTextInput"]`)
+		if err != nil {
+			return nil
+		}
+
+		var promptText string
+		var kind auth.ChallengeKind
+		if err := recoverToError(func() { promptText = page.MustEval(`() => document.body.innerText`).Str() }); err != nil {
+			return err
+		}
+		kind = auth.ChallengeEmailVerification
+		if strings.Contains(strings.ToLower(promptText), "verification code") || strings.Contains(strings.ToLower(promptText), "authentication code") {
+			kind = auth.ChallengeTOTP
+		}
+
+		slog.Info("TwitterExtractor: Login challenge detected", "kind", kind, "round", round)
+
+		if e.ChallengeSolver == nil {
+			return fmt.Errorf("no challenge solver configured to resolve %s challenge", kind)
+		}
+
+		response, err := e.ChallengeSolver.Solve(ctx, username, kind)
+		if err != nil {
+			return fmt.Errorf("failed to solve %s challenge: %w", kind, err)
+		}
+
+		err = recoverToError(func() {
+			input.MustSelectAllText().MustInput(response)
+
+			submitted := page.MustEval(`
+				() => {
+					const buttons = Array.from(document.querySelectorAll('div[role="button"], button'));
+					const next = buttons.find(btn => ['Next', 'Confirm', 'Log in'].includes(btn.textContent.trim()));
+					if (next) {
+						next.click();
+						return true;
+					}
+					return false;
+				}
+			`)
+			if !submitted.Bool() {
+				panic(fmt.Sprintf("could not find a submit button for the %s challenge", kind))
+			}
+
+			page.MustWaitNavigation()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("gave up resolving login challenges after %d rounds", twitterChallengeMaxRounds)
+}
+
+// parseTweetDetailResponse parses a single TweetDetail API response and extracts tweet
+// data. It's used by the session/browser path (extractTweetDataWithContext), which only
+// ever observes one TweetDetail response via request hijacking, so the reply tree it builds
+// is whatever that one page contained; the guest-token path instead paginates across
+// multiple pages itself via parseTweetDetailPage (see fetchTweetDetailGuest).
 func (e *TwitterExtractor) parseTweetDetailResponse(apiResponse *TweetDetailResponse) (*TwitterData, error) {
-	tweetData := &TwitterData{
-		Comments: []TwitterComment{},
+	var focalTweetID string
+	for _, instruction := range apiResponse.Data.ThreadedConversationWithInjectionsV2.Instructions {
+		for _, entry := range instruction.Entries {
+			if strings.HasPrefix(entry.EntryID, "tweet-") {
+				focalTweetID = entry.Content.ItemContent.TweetResults.Result.RestID
+			}
+		}
 	}
 
+	tweetContent, tweetAuthor, media, entities, quotedTweet, comments, _ := parseTweetDetailPage(apiResponse)
+	if tweetContent == "" {
+		return nil, fmt.Errorf("could not find main tweet content in the API response")
+	}
+
+	return &TwitterData{
+		TweetContent:  tweetContent,
+		TweetAuthor:   tweetAuthor,
+		Comments:      buildCommentTree(comments, focalTweetID, e.Config.TwitterMaxThreadDepth),
+		TotalComments: len(comments),
+		Media:         media,
+		QuotedTweet:   quotedTweet,
+		Entities:      entities,
+	}, nil
+}
+
+// parseTweetDetailPage extracts one page's worth of data out of a TweetDetail response: the
+// focal tweet's content/author (empty when this page is a paginated continuation that
+// doesn't re-include it), the flat list of reply comments on this page, and the cursor (if
+// any) to pass as the `cursor` variable on the next TweetDetail request. Both the
+// "cursor-bottom-" (more top-level replies) and "cursor-showMoreThreads-" (suppressed reply
+// threads) cursor entries are followed the same way, since both resume the same timeline.
+func parseTweetDetailPage(apiResponse *TweetDetailResponse) (tweetContent, tweetAuthor string, media []TwitterMedia, entities TwitterEntities, quotedTweet *TwitterData, comments []TwitterComment, nextCursor string) {
 	for _, instruction := range apiResponse.Data.ThreadedConversationWithInjectionsV2.Instructions {
-		if instruction.Type == "TimelineAddEntries" {
-			for _, entry := range instruction.Entries {
-				if strings.HasPrefix(entry.EntryID, "tweet-") {
-					tweetResult := entry.Content.ItemContent.TweetResults.Result
-					if tweetResult.Typename == "Tweet" {
-						tweetData.TweetContent = tweetResult.Legacy.FullText
-						if tweetResult.Core.UserResults.Result.Legacy.Name != "" {
-							tweetData.TweetAuthor = fmt.Sprintf("%s (@%s)", tweetResult.Core.UserResults.Result.Legacy.Name, tweetResult.Core.UserResults.Result.Legacy.ScreenName)
-						} else {
-							tweetData.TweetAuthor = "Unknown Author"
-						}
+		if instruction.Type != "TimelineAddEntries" {
+			continue
+		}
+		for _, entry := range instruction.Entries {
+			switch {
+			case strings.HasPrefix(entry.EntryID, "tweet-"):
+				tweetResult := entry.Content.ItemContent.TweetResults.Result
+				if tweetResult.Typename == "Tweet" {
+					focal := tweetResult
+					if focal.Legacy.RetweetedStatusResult != nil {
+						focal = focal.Legacy.RetweetedStatusResult.Result
+					}
+					tweetContent = expandFullText(focal.Legacy)
+					tweetAuthor = authorFromResult(tweetResult)
+					media = mediaFromLegacy(focal.Legacy)
+					entities = entitiesFromLegacy(focal.Legacy)
+					if focal.QuotedStatusResult != nil {
+						quotedTweet = tweetDataFromResult(focal.QuotedStatusResult.Result)
 					}
-				} else if strings.HasPrefix(entry.EntryID, "conversationthread-") {
-					for _, item := range entry.Content.Items {
-						tweetResult := item.Item.ItemContent.TweetResults.Result
-						if tweetResult.Typename == "Tweet" {
-							comment := TwitterComment{
-								Author:    tweetResult.Core.UserResults.Result.Legacy.Name,
-								Username:  "@" + tweetResult.Core.UserResults.Result.Legacy.ScreenName,
-								Content:   tweetResult.Legacy.FullText,
-								Timestamp: tweetResult.Legacy.CreatedAt,
-								Likes:     fmt.Sprintf("%d", tweetResult.Legacy.FavoriteCount),
-								Replies:   fmt.Sprintf("%d", tweetResult.Legacy.ReplyCount),
-								Retweets:  fmt.Sprintf("%d", tweetResult.Legacy.RetweetCount),
-							}
-							if comment.Author == "" {
-								comment.Author = "Unknown"
-							}
-							tweetData.Comments = append(tweetData.Comments, comment)
-						}
+				}
+			case strings.HasPrefix(entry.EntryID, "conversationthread-"):
+				for _, item := range entry.Content.Items {
+					tweetResult := item.Item.ItemContent.TweetResults.Result
+					if tweetResult.Typename == "Tweet" {
+						comments = append(comments, commentFromTweetResult(tweetResult))
 					}
 				}
+			case entry.Content.EntryType == "TimelineTimelineCursor" &&
+				(strings.HasPrefix(entry.EntryID, "cursor-bottom-") || strings.HasPrefix(entry.EntryID, "cursor-showMoreThreads-")):
+				if entry.Content.Value != "" {
+					nextCursor = entry.Content.Value
+				}
 			}
 		}
 	}
+	return tweetContent, tweetAuthor, media, entities, quotedTweet, comments, nextCursor
+}
 
-	tweetData.TotalComments = len(tweetData.Comments)
+// authorFromResult formats a TweetResult's author as "Display Name (@screen_name)", falling
+// back to "Unknown Author" when the user_results payload is empty (e.g. a suspended account).
+func authorFromResult(tweetResult TweetResult) string {
+	name := tweetResult.Core.UserResults.Result.Legacy.Name
+	if name == "" {
+		return "Unknown Author"
+	}
+	return fmt.Sprintf("%s (@%s)", name, tweetResult.Core.UserResults.Result.Legacy.ScreenName)
+}
 
-	if tweetData.TweetContent == "" {
-		return nil, fmt.Errorf("could not find main tweet content in the API response")
+// expandFullText rewrites legacy.FullText by substituting each t.co URL with its
+// expanded_url, and stripping any trailing t.co URL that merely points at an attached media
+// item, since that link is redundant with the decoded TwitterMedia entry for it.
+func expandFullText(legacy TweetLegacy) string {
+	text := legacy.FullText
+	for _, u := range legacy.Entities.URLs {
+		if u.URL == "" || u.ExpandedURL == "" {
+			continue
+		}
+		text = strings.ReplaceAll(text, u.URL, u.ExpandedURL)
+	}
+	for _, m := range legacy.ExtendedEntities.Media {
+		if m.ShortURL == "" {
+			continue
+		}
+		text = strings.ReplaceAll(text, m.ShortURL, "")
 	}
+	return strings.TrimSpace(text)
+}
 
-	return tweetData, nil
+// mediaFromLegacy decodes legacy.extended_entities.media (falling back to legacy.entities.media
+// if extended_entities is empty) into TwitterMedia, selecting the highest-bitrate MP4 variant
+// for video and animated_gif attachments.
+func mediaFromLegacy(legacy TweetLegacy) []TwitterMedia {
+	items := legacy.ExtendedEntities.Media
+	if len(items) == 0 {
+		items = legacy.Entities.Media
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	media := make([]TwitterMedia, 0, len(items))
+	for _, item := range items {
+		m := TwitterMedia{Type: item.Type, URL: item.MediaURL}
+		bestBitrate := -1
+		for _, variant := range item.VideoInfo.Variants {
+			if variant.ContentType == "video/mp4" && variant.Bitrate >= bestBitrate {
+				bestBitrate = variant.Bitrate
+				m.URL = variant.URL
+			}
+		}
+		media = append(media, m)
+	}
+	return media
 }
 
-// saveCookies saves browser cookies to a file
-func (e *TwitterExtractor) saveCookies(page *rod.Page, filename string) error {
-	e.cookieMutex.Lock()
-	defer e.cookieMutex.Unlock()
+// entitiesFromLegacy decodes legacy.entities into the caller-facing TwitterEntities shape.
+func entitiesFromLegacy(legacy TweetLegacy) TwitterEntities {
+	var entities TwitterEntities
+	for _, u := range legacy.Entities.URLs {
+		entities.URLs = append(entities.URLs, TwitterURLEntity{URL: u.URL, ExpandedURL: u.ExpandedURL, DisplayURL: u.DisplayURL})
+	}
+	for _, m := range legacy.Entities.UserMentions {
+		entities.UserMentions = append(entities.UserMentions, TwitterMention{Name: m.Name, ScreenName: m.ScreenName})
+	}
+	for _, h := range legacy.Entities.Hashtags {
+		entities.Hashtags = append(entities.Hashtags, h.Text)
+	}
+	return entities
+}
 
-	cookies, err := page.Cookies(nil)
-	if err != nil {
-		return fmt.Errorf("could not get cookies: %w", err)
+// commentFromTweetResult builds a TwitterComment from a single reply's TweetResult,
+// carrying its id and in_reply_to_status_id so buildCommentTree can reconstruct the reply
+// tree afterwards.
+func commentFromTweetResult(tweetResult TweetResult) TwitterComment {
+	comment := TwitterComment{
+		ID:                tweetResult.RestID,
+		InReplyToStatusID: tweetResult.Legacy.InReplyToStatusIDStr,
+		Author:            tweetResult.Core.UserResults.Result.Legacy.Name,
+		Username:          "@" + tweetResult.Core.UserResults.Result.Legacy.ScreenName,
+		Content:           expandFullText(tweetResult.Legacy),
+		Timestamp:         tweetResult.Legacy.CreatedAt,
+		Likes:             fmt.Sprintf("%d", tweetResult.Legacy.FavoriteCount),
+		Replies:           fmt.Sprintf("%d", tweetResult.Legacy.ReplyCount),
+		Retweets:          fmt.Sprintf("%d", tweetResult.Legacy.RetweetCount),
+	}
+	if comment.Author == "" {
+		comment.Author = "Unknown"
 	}
+	return comment
+}
 
-	// Use jsoniter for performance
-	json := jsoniter.ConfigCompatibleWithStandardLibrary
-	jsonData, err := json.MarshalIndent(cookies, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal cookies: %w", err)
+// buildCommentTree groups a flat, possibly multi-page list of reply comments into a nested
+// tree rooted at whatever directly replies to focalTweetID, using each comment's
+// InReplyToStatusID. Comments nested deeper than maxDepth are attached flat under their
+// deepest kept ancestor instead of being dropped, so pagination limits never lose data, only
+// its shape.
+func buildCommentTree(comments []TwitterComment, focalTweetID string, maxDepth int) []TwitterComment {
+	if maxDepth <= 0 {
+		maxDepth = 1
 	}
 
-	err = os.WriteFile(filename, jsonData, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to save cookies: %w", err)
+	byParent := make(map[string][]TwitterComment, len(comments))
+	var roots []TwitterComment
+	for _, c := range comments {
+		if c.InReplyToStatusID == "" || c.InReplyToStatusID == focalTweetID {
+			roots = append(roots, c)
+		} else {
+			byParent[c.InReplyToStatusID] = append(byParent[c.InReplyToStatusID], c)
+		}
 	}
 
-	slog.Info("TwitterExtractor: Session cookies saved", "filename", filename)
-	return nil
+	var attach func(node *TwitterComment, depth int)
+	attach = func(node *TwitterComment, depth int) {
+		children := byParent[node.ID]
+		if len(children) == 0 {
+			return
+		}
+		if depth >= maxDepth {
+			node.Thread = flattenDescendants(byParent, node.ID)
+			return
+		}
+		for i := range children {
+			attach(&children[i], depth+1)
+		}
+		node.Thread = children
+	}
+	for i := range roots {
+		attach(&roots[i], 1)
+	}
+	return roots
 }
 
-// loadCookies loads browser cookies from a file
-func (e *TwitterExtractor) loadCookies(page *rod.Page, filename string) bool {
-	e.cookieMutex.RLock()
-	defer e.cookieMutex.RUnlock()
-
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return false
+// flattenDescendants collects every descendant of id, regardless of nesting depth, as a
+// single flat slice; used once buildCommentTree's depth cap is reached so deeper replies are
+// still present in the output.
+func flattenDescendants(byParent map[string][]TwitterComment, id string) []TwitterComment {
+	var all []TwitterComment
+	for _, child := range byParent[id] {
+		all = append(all, child)
+		all = append(all, flattenDescendants(byParent, child.ID)...)
 	}
+	return all
+}
 
-	data, err := os.ReadFile(filename)
+// saveCookies persists the page's current cookies to the credential store under
+// username, so a later extraction for the same account can skip logging in again.
+func (e *TwitterExtractor) saveCookies(page *rod.Page, username string) error {
+	pageCookies, err := page.Cookies(nil)
 	if err != nil {
-		slog.Warn("TwitterExtractor: Could not read cookies file", "error", err)
-		return false
+		return fmt.Errorf("could not get cookies: %w", err)
 	}
 
-	// Use jsoniter for performance
-	json := jsoniter.ConfigCompatibleWithStandardLibrary
-	var cookieData []map[string]interface{}
-	err = json.Unmarshal(data, &cookieData)
-	if err != nil {
-		slog.Warn("TwitterExtractor: Could not parse cookies", "error", err)
+	cookies := make([]auth.Cookie, 0, len(pageCookies))
+	for _, c := range pageCookies {
+		cookies = append(cookies, auth.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			Expires:  c.Expires.Time(),
+		})
+	}
+
+	if err := e.Credentials.Save(username, cookies); err != nil {
+		return fmt.Errorf("could not save cookies: %w", err)
+	}
+
+	slog.Info("TwitterExtractor: Session cookies saved", "username", username)
+	return nil
+}
+
+// loadCookies installs the session previously saved for username onto page, and reports
+// whether one was found.
+func (e *TwitterExtractor) loadCookies(page *rod.Page, username string) bool {
+	cookies, ok := e.Credentials.Load(username)
+	if !ok {
 		return false
 	}
 
-	var cookies []*proto.NetworkCookieParam
-	for _, cookieMap := range cookieData {
-		cookie := &proto.NetworkCookieParam{}
-		if name, ok := cookieMap["name"].(string); ok {
-			cookie.Name = name
-		}
-		if value, ok := cookieMap["value"].(string); ok {
-			cookie.Value = value
-		}
-		if domain, ok := cookieMap["domain"].(string); ok {
-			cookie.Domain = domain
-		}
-		if path, ok := cookieMap["path"].(string); ok {
-			cookie.Path = path
-		}
-		if httpOnly, ok := cookieMap["httpOnly"].(bool); ok {
-			cookie.HTTPOnly = httpOnly
-		}
-		if secure, ok := cookieMap["secure"].(bool); ok {
-			cookie.Secure = secure
-		}
-		cookies = append(cookies, cookie)
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		params = append(params, &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+		})
 	}
-	page.MustSetCookies(cookies...)
+	page.MustSetCookies(params...)
 	return true
 }
 
-// extractFromProfileURL handles the extraction of the latest 5 tweets from a profile URL.
-func (e *TwitterExtractor) extractFromProfileURL(ctx context.Context, profileURL string, maxChars *int, result *ExtractedResult) error {
+// extractFromProfileURL handles the extraction of the latest 5 tweets from a profile URL,
+// using the guest-token GraphQL client unless authMode forces a browser session.
+func (e *TwitterExtractor) extractFromProfileURL(ctx context.Context, profileURL string, maxChars *int, result *ExtractedResult, authMode string) error {
 	result.SourceType = "twitter_profile"
 
+	if authMode != "session" {
+		profileResult, err := e.extractFromProfileURLGuest(ctx, profileURL)
+		if err == nil {
+			result.Data = profileResult
+			result.ProcessedSuccessfully = true
+			slog.Info("TwitterExtractor: Successfully extracted latest tweets from profile via guest token", "url", profileURL)
+			return nil
+		}
+		if authMode == "guest" {
+			return fmt.Errorf("failed to extract profile via guest token: %w", err)
+		}
+		slog.Warn("TwitterExtractor: guest-token profile extraction failed, falling back to session auth", "url", profileURL, "error", err)
+	}
+
+	if e.Accounts.Empty() {
+		return fmt.Errorf("no twitter accounts configured for session auth mode")
+	}
+
 	tweetURLs, err := e.extractTweetURLsFromProfile(ctx, profileURL)
 	if err != nil {
 		return fmt.Errorf("failed to extract tweet URLs from profile: %w", err)
@@ -627,24 +1270,224 @@ func (e *TwitterExtractor) extractFromProfileURL(ctx context.Context, profileURL
 	return nil
 }
 
+// extractFromProfileURLGuest fetches the latest 5 tweets from a profile using the guest
+// token GraphQL client: resolve the screen name to a numeric user ID via
+// UserByScreenName, then list their recent tweets via UserTweets.
+func (e *TwitterExtractor) extractFromProfileURLGuest(ctx context.Context, profileURL string) (*TwitterProfileResult, error) {
+	screenName := extractScreenNameFromProfileURL(profileURL)
+	if screenName == "" {
+		return nil, fmt.Errorf("could not extract screen name from profile URL")
+	}
+
+	userID, err := e.fetchUserIDGuest(ctx, screenName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve user id for @%s: %w", screenName, err)
+	}
+
+	tweetExtracts, err := e.fetchUserTweetsGuest(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tweets for @%s: %w", screenName, err)
+	}
+
+	return &TwitterProfileResult{
+		ProfileURL:   profileURL,
+		LatestTweets: tweetExtracts,
+	}, nil
+}
+
+// extractScreenNameFromProfileURL pulls the username out of a Twitter/X profile URL, e.g.
+// https://x.com/golang -> "golang".
+func extractScreenNameFromProfileURL(profileURL string) string {
+	if !strings.Contains(profileURL, "://") {
+		profileURL = "https://" + profileURL
+	}
+	parsedURL, err := url.Parse(profileURL)
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(parsedURL.Path, "/")
+}
+
+// fetchUserIDGuest resolves a screen name to its numeric rest_id via the
+// UserByScreenName GraphQL operation.
+func (e *TwitterExtractor) fetchUserIDGuest(ctx context.Context, screenName string) (string, error) {
+	guestToken, err := e.getGuestToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain guest token: %w", err)
+	}
+
+	variables := map[string]interface{}{
+		"screen_name":              screenName,
+		"withSafetyModeUserFields": true,
+	}
+
+	apiURL := fmt.Sprintf("https://twitter.com/i/api/graphql/%s/UserByScreenName", twitterQueryIDUserByScreenName)
+	body, err := e.graphQLGet(ctx, guestToken, apiURL, variables, twitterGraphQLFeatures)
+	if err != nil {
+		return "", err
+	}
+
+	var userResp struct {
+		Data struct {
+			User struct {
+				Result struct {
+					RestID string `json:"rest_id"`
+				} `json:"result"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(body, &userResp); err != nil {
+		return "", fmt.Errorf("failed to parse UserByScreenName response: %w", err)
+	}
+	if userResp.Data.User.Result.RestID == "" {
+		return "", fmt.Errorf("could not resolve user id for @%s", screenName)
+	}
+	return userResp.Data.User.Result.RestID, nil
+}
+
+// userTweetsResponse mirrors the subset of the UserTweets GraphQL response this
+// extractor cares about: a timeline of entries, each possibly wrapping a tweet result.
+type userTweetsResponse struct {
+	Data struct {
+		User struct {
+			Result struct {
+				TimelineV2 struct {
+					Timeline struct {
+						Instructions []struct {
+							Type    string `json:"type"`
+							Entries []struct {
+								EntryID string `json:"entryId"`
+								Content struct {
+									ItemContent struct {
+										TweetResults struct {
+											Result TweetResult `json:"result"`
+										} `json:"tweet_results"`
+									} `json:"itemContent"`
+								} `json:"content"`
+							} `json:"entries"`
+						} `json:"instructions"`
+					} `json:"timeline"`
+				} `json:"timeline_v2"`
+			} `json:"result"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+// fetchUserTweetsGuest lists a user's 5 most recent tweets via the UserTweets GraphQL
+// operation. Each tweet's text and author come directly from the timeline entry; unlike
+// the single-tweet TweetDetail path, replies aren't fetched for timeline listings.
+func (e *TwitterExtractor) fetchUserTweetsGuest(ctx context.Context, userID string) ([]TweetExtract, error) {
+	guestToken, err := e.getGuestToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain guest token: %w", err)
+	}
+
+	variables := map[string]interface{}{
+		"userId":                                 userID,
+		"count":                                  5,
+		"includePromotedContent":                 false,
+		"withQuickPromoteEligibilityTweetFields": false,
+		"withVoice":                              true,
+		"withV2Timeline":                         true,
+	}
+
+	apiURL := fmt.Sprintf("https://twitter.com/i/api/graphql/%s/UserTweets", twitterQueryIDUserTweets)
+	body, err := e.graphQLGet(ctx, guestToken, apiURL, variables, twitterGraphQLFeatures)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeline userTweetsResponse
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(body, &timeline); err != nil {
+		return nil, fmt.Errorf("failed to parse UserTweets response: %w", err)
+	}
+
+	var tweetExtracts []TweetExtract
+	for _, instruction := range timeline.Data.User.Result.TimelineV2.Timeline.Instructions {
+		if instruction.Type != "TimelineAddEntries" {
+			continue
+		}
+		for _, entry := range instruction.Entries {
+			if !strings.HasPrefix(entry.EntryID, "tweet-") {
+				continue
+			}
+			tweetResult := entry.Content.ItemContent.TweetResults.Result
+			if tweetResult.Typename != "Tweet" || tweetResult.Legacy.FullText == "" {
+				continue
+			}
+			screenName := tweetResult.Core.UserResults.Result.Legacy.ScreenName
+			tweetExtracts = append(tweetExtracts, TweetExtract{
+				URL:  fmt.Sprintf("https://x.com/%s/status/%s", screenName, tweetResult.RestID),
+				Data: tweetDataFromResult(tweetResult),
+			})
+			if len(tweetExtracts) >= 5 {
+				break
+			}
+		}
+		if len(tweetExtracts) >= 5 {
+			break
+		}
+	}
+
+	if len(tweetExtracts) == 0 {
+		return nil, fmt.Errorf("no tweets found in timeline")
+	}
+
+	return tweetExtracts, nil
+}
+
+// tweetDataFromResult builds a TwitterData from a single timeline TweetResult. Timeline
+// listings don't include replies, so Comments is left empty. If tr is a plain retweet, the
+// retweeted tweet's own content/media/entities are used instead of tr's truncated
+// "RT @user: ..." text, while TweetAuthor still credits whoever retweeted it.
+func tweetDataFromResult(tr TweetResult) *TwitterData {
+	if tr.Legacy.RetweetedStatusResult != nil {
+		data := tweetDataFromResult(tr.Legacy.RetweetedStatusResult.Result)
+		data.TweetAuthor = authorFromResult(tr)
+		return data
+	}
+
+	data := &TwitterData{
+		TweetContent: expandFullText(tr.Legacy),
+		TweetAuthor:  authorFromResult(tr),
+		Comments:     []TwitterComment{},
+		Media:        mediaFromLegacy(tr.Legacy),
+		Entities:     entitiesFromLegacy(tr.Legacy),
+	}
+	if tr.QuotedStatusResult != nil {
+		data.QuotedTweet = tweetDataFromResult(tr.QuotedStatusResult.Result)
+	}
+	return data
+}
+
 // extractTweetURLsFromProfile extracts the latest 5 tweet URLs from a profile page.
 func (e *TwitterExtractor) extractTweetURLsFromProfile(ctx context.Context, profileURL string) ([]string, error) {
-	browser := e.BrowserPool.Get()
-	defer e.BrowserPool.Return(browser)
+	session, err := e.Accounts.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire twitter account: %w", err)
+	}
+	defer e.Accounts.Release(session)
 
-	page, err := browser.Page(proto.TargetCreateTarget{URL: ""})
+	page, err := session.Browser.Page(proto.TargetCreateTarget{URL: ""})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
 	defer page.MustClose()
+	page = page.Context(ctx)
 
 	if err := page.Navigate(profileURL); err != nil {
 		return nil, fmt.Errorf("failed to navigate to profile page: %w", err)
 	}
 
-	page.MustWaitLoad()
-	// Wait for the <article> element to be present, which contains tweets.
-	page.MustElement("article").MustWaitVisible()
+	if err := recoverToError(func() {
+		page.MustWaitLoad()
+		// Wait for the <article> element to be present, which contains tweets.
+		page.MustElement("article").MustWaitVisible()
+	}); err != nil {
+		return nil, err
+	}
 
 	articles, err := page.Elements("article")
 	if err != nil {
@@ -0,0 +1,207 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"web-search-api-for-llms/internal/browser"
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/metrics"
+)
+
+// twitterDefaultQuarantine is how long an account is quarantined after a 429 or an
+// exhausted x-rate-limit-remaining header when the response doesn't also carry an
+// x-rate-limit-reset timestamp to quarantine it until.
+const twitterDefaultQuarantine = 15 * time.Minute
+
+// poolAccount is one account AccountPool tracks, alongside the rate-limit state
+// observed from its session's hijacked responses.
+type poolAccount struct {
+	config.TwitterAccountConfig
+	quarantineUntil time.Time
+}
+
+// AccountSession is a browser checked out of AccountPool together with the account it's
+// authenticated (or will authenticate) as. Callers must call AccountPool.Release when
+// done so the account and browser can be reused.
+type AccountSession struct {
+	Browser  *rod.Browser
+	Username string
+	Password string
+
+	account *poolAccount
+}
+
+// AccountPool rotates a fixed set of Twitter accounts across concurrent session-auth
+// extractions, so a profile fan-out of several goroutines doesn't hammer a single
+// account's session until Twitter rate-limits or locks it. Accounts quarantined by a 429
+// or an exhausted x-rate-limit-remaining header are skipped until their
+// x-rate-limit-reset deadline.
+type AccountPool struct {
+	browserPool *browser.Pool
+
+	mu        sync.Mutex
+	accounts  []*poolAccount
+	available chan *poolAccount
+}
+
+// NewAccountPool builds an AccountPool from appConfig.TwitterAccounts, falling back to a
+// single account built from TwitterUsername/TwitterPassword when none are configured, so
+// existing single-account deployments keep working unchanged.
+func NewAccountPool(browserPool *browser.Pool, appConfig *config.AppConfig) *AccountPool {
+	accounts := appConfig.TwitterAccounts
+	if len(accounts) == 0 && appConfig.TwitterUsername != "" {
+		accounts = []config.TwitterAccountConfig{{
+			Username: appConfig.TwitterUsername,
+			Password: appConfig.TwitterPassword,
+		}}
+	}
+
+	pool := &AccountPool{
+		browserPool: browserPool,
+		available:   make(chan *poolAccount, len(accounts)),
+	}
+	for _, acc := range accounts {
+		pooled := &poolAccount{TwitterAccountConfig: acc}
+		pool.accounts = append(pool.accounts, pooled)
+		pool.available <- pooled
+	}
+
+	// Register, rather than MustRegister: tests construct multiple AccountPools (one per
+	// TwitterExtractor) against the same process-wide metrics.Registry, and a second
+	// registration attempt for an identical collector is expected, not a bug.
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if err := metrics.Registry.Register(newAccountPoolCollector(pool)); err != nil && !errors.As(err, &alreadyRegistered) {
+		slog.Warn("Failed to register twitter account pool metrics collector", "error", err)
+	}
+
+	return pool
+}
+
+// Empty reports whether the pool has no accounts configured.
+func (p *AccountPool) Empty() bool {
+	return len(p.accounts) == 0
+}
+
+// Acquire blocks until an account is available (not checked out and not quarantined),
+// then checks out a browser from the underlying browser.Pool for it.
+func (p *AccountPool) Acquire(ctx context.Context) (*AccountSession, error) {
+	if p.Empty() {
+		return nil, fmt.Errorf("no twitter accounts configured")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context cancelled while acquiring twitter account: %w", ctx.Err())
+		case acc := <-p.available:
+			p.mu.Lock()
+			wait := time.Until(acc.quarantineUntil)
+			p.mu.Unlock()
+
+			if wait > 0 {
+				slog.Debug("TwitterExtractor: account quarantined, waiting", "username", acc.Username, "wait", wait)
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					p.available <- acc
+					return nil, fmt.Errorf("context cancelled while waiting out account quarantine: %w", ctx.Err())
+				}
+			}
+
+			return &AccountSession{
+				Browser:  p.browserPool.Get(),
+				Username: acc.Username,
+				Password: acc.Password,
+				account:  acc,
+			}, nil
+		}
+	}
+}
+
+// Release returns session's browser to the underlying browser.Pool and makes its
+// account available to the next Acquire call.
+func (p *AccountPool) Release(session *AccountSession) {
+	p.browserPool.Return(session.Browser)
+	p.available <- session.account
+}
+
+// RecordResponse inspects a hijacked response's status and rate-limit headers and
+// quarantines username's account if Twitter has rate-limited it, until the response's
+// x-rate-limit-reset timestamp (or twitterDefaultQuarantine from now if that header is
+// missing).
+func (p *AccountPool) RecordResponse(username string, statusCode int, headers http.Header) {
+	exhausted := statusCode == http.StatusTooManyRequests || headers.Get("x-rate-limit-remaining") == "0"
+	if !exhausted {
+		return
+	}
+
+	until := time.Now().Add(twitterDefaultQuarantine)
+	if resetStr := headers.Get("x-rate-limit-reset"); resetStr != "" {
+		if resetUnix, err := strconv.ParseInt(resetStr, 10, 64); err == nil {
+			until = time.Unix(resetUnix, 0)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, acc := range p.accounts {
+		if acc.Username == username && until.After(acc.quarantineUntil) {
+			acc.quarantineUntil = until
+			slog.Warn("TwitterExtractor: account rate-limited, quarantining", "username", username, "until", until)
+			return
+		}
+	}
+}
+
+// accountPoolCollector reports an AccountPool's account count and how many of them are
+// currently quarantined, so an operator can see "Twitter anti-bot measures are eating
+// this pool alive" before every account is quarantined at once and extraction stalls
+// outright. Modeled on worker.PoolCollector: it reads pool state at scrape time rather
+// than updating gauges from AccountPool itself.
+type accountPoolCollector struct {
+	pool *AccountPool
+
+	accounts    *prometheus.Desc
+	quarantined *prometheus.Desc
+}
+
+func newAccountPoolCollector(pool *AccountPool) *accountPoolCollector {
+	return &accountPoolCollector{
+		pool: pool,
+		accounts: prometheus.NewDesc("ragforge_twitter_accounts",
+			"Twitter accounts configured in the rotation pool.", nil, nil),
+		quarantined: prometheus.NewDesc("ragforge_twitter_accounts_quarantined",
+			"Twitter accounts currently quarantined after a rate limit or lockout.", nil, nil),
+	}
+}
+
+func (c *accountPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.accounts
+	ch <- c.quarantined
+}
+
+func (c *accountPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.pool.mu.Lock()
+	total := len(c.pool.accounts)
+	now := time.Now()
+	quarantined := 0
+	for _, acc := range c.pool.accounts {
+		if acc.quarantineUntil.After(now) {
+			quarantined++
+		}
+	}
+	c.pool.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(c.accounts, prometheus.GaugeValue, float64(total))
+	ch <- prometheus.MustNewConstMetric(c.quarantined, prometheus.GaugeValue, float64(quarantined))
+}
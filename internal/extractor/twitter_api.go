@@ -0,0 +1,395 @@
+package extractor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"web-search-api-for-llms/internal/browser"
+	"web-search-api-for-llms/internal/config"
+)
+
+// TwitterAPIExtractor fetches a single tweet via the TweetDetail GraphQL operation,
+// authenticated with this deployment's own credentials (an app bearer token, or OAuth1a
+// user credentials) rather than TwitterExtractor's headless browser or public guest
+// token. Real app/user credentials aren't subject to the guest token's tighter,
+// easily-throttled rate limits, so this cuts per-tweet latency by roughly an order of
+// magnitude whenever that access is available. See NewTwitterContentExtractor for how
+// the two are selected between.
+type TwitterAPIExtractor struct {
+	BaseExtractor
+	Config *config.AppConfig
+
+	// oauth1 is non-nil when Config has a full OAuth1a credential set, preferred over a
+	// bare bearer token because a signed user context can also reach endpoints an
+	// app-only bearer token can't.
+	oauth1 *oauth1Credentials
+}
+
+type oauth1Credentials struct {
+	consumerKey    string
+	consumerSecret string
+	accessToken    string
+	accessSecret   string
+}
+
+// hasTwitterAPICredentials reports whether cfg has either a bearer token or a full
+// OAuth1a credential set, i.e. whether TwitterAPIExtractor has anything to sign requests
+// with at all.
+func hasTwitterAPICredentials(cfg *config.AppConfig) bool {
+	if cfg.TwitterBearerToken != "" {
+		return true
+	}
+	return cfg.TwitterOAuth1ConsumerKey != "" && cfg.TwitterOAuth1ConsumerSecret != "" &&
+		cfg.TwitterOAuth1AccessToken != "" && cfg.TwitterOAuth1AccessSecret != ""
+}
+
+// NewTwitterAPIExtractor creates a TwitterAPIExtractor. ok is false when cfg has neither
+// a bearer token nor OAuth1a credentials, so NewTwitterContentExtractor can fall back to
+// TwitterExtractor without treating that as an initialization error.
+func NewTwitterAPIExtractor(appConfig *config.AppConfig, factory TransportFactory) (e *TwitterAPIExtractor, ok bool, err error) {
+	if !hasTwitterAPICredentials(appConfig) {
+		return nil, false, nil
+	}
+
+	base, err := NewBaseExtractor(appConfig, factory, "x.com")
+	if err != nil {
+		return nil, false, err
+	}
+
+	e = &TwitterAPIExtractor{BaseExtractor: base, Config: appConfig}
+	if appConfig.TwitterOAuth1ConsumerKey != "" && appConfig.TwitterOAuth1ConsumerSecret != "" &&
+		appConfig.TwitterOAuth1AccessToken != "" && appConfig.TwitterOAuth1AccessSecret != "" {
+		e.oauth1 = &oauth1Credentials{
+			consumerKey:    appConfig.TwitterOAuth1ConsumerKey,
+			consumerSecret: appConfig.TwitterOAuth1ConsumerSecret,
+			accessToken:    appConfig.TwitterOAuth1AccessToken,
+			accessSecret:   appConfig.TwitterOAuth1AccessSecret,
+		}
+	}
+	return e, true, nil
+}
+
+// Extract fetches a single tweet (body, author, comments) via TweetDetail. Search and
+// profile URLs aren't implemented here; NewTwitterContentExtractor only routes plain
+// tweet URLs to TwitterAPIExtractor and handles those other shapes with TwitterExtractor
+// directly.
+func (e *TwitterAPIExtractor) Extract(parentCtx context.Context, targetURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
+	slog.Info("TwitterAPIExtractor: Starting extraction", "url", targetURL)
+	result.SourceType = "twitter"
+
+	ctx, cancel := context.WithTimeout(parentCtx, 2*time.Minute)
+	defer cancel()
+
+	tweetID := extractTweetID(targetURL)
+	if tweetID == "" {
+		return fmt.Errorf("could not extract tweet ID from URL")
+	}
+
+	tweetData, err := e.fetchTweetDetail(ctx, tweetID)
+	if err != nil {
+		return fmt.Errorf("extraction failed: %w", err)
+	}
+	result.Data = tweetData
+	result.ProcessedSuccessfully = true
+
+	if maxChars != nil {
+		tweetData.TweetContent = truncateText(tweetData.TweetContent, *maxChars)
+	}
+
+	slog.Info("TwitterAPIExtractor: Successfully extracted tweet data", "url", targetURL)
+	return nil
+}
+
+// fetchTweetDetail mirrors TwitterExtractor.fetchTweetDetailGuest's TweetDetail
+// cursor-pagination loop, reusing its response parsing (parseTweetDetailPage,
+// buildCommentTree); the only difference is graphQLGet authorizes with e's own
+// credentials instead of a guest token.
+func (e *TwitterAPIExtractor) fetchTweetDetail(ctx context.Context, tweetID string) (*TwitterData, error) {
+	var tweetContent, tweetAuthor string
+	var tweetMedia []TwitterMedia
+	var tweetEntities TwitterEntities
+	var tweetQuotedTweet *TwitterData
+	var comments []TwitterComment
+	cursor := ""
+
+	for page := 0; page < twitterMaxTweetDetailPages; page++ {
+		variables := map[string]interface{}{
+			"focalTweetId":                          tweetID,
+			"with_rux_injections":                   false,
+			"includePromotedContent":                false,
+			"withCommunity":                         true,
+			"withQuickPromoteEligibilityTweetFields": false,
+			"withBirdwatchNotes":                     false,
+			"withVoice":                              true,
+			"withV2Timeline":                         true,
+		}
+		if cursor != "" {
+			variables["cursor"] = cursor
+		}
+
+		apiURL := fmt.Sprintf("https://api.twitter.com/graphql/%s/TweetDetail", twitterQueryIDTweetDetail)
+		body, err := e.graphQLGet(ctx, apiURL, variables, twitterGraphQLFeatures)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			slog.Warn("TwitterAPIExtractor: paginated TweetDetail request failed, returning thread collected so far", "tweet_id", tweetID, "page", page, "error", err)
+			break
+		}
+
+		var detailResp TweetDetailResponse
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		if err := json.Unmarshal(body, &detailResp); err != nil {
+			if page == 0 {
+				return nil, fmt.Errorf("failed to parse TweetDetail response: %w", err)
+			}
+			slog.Warn("TwitterAPIExtractor: failed to parse a paginated TweetDetail response, returning thread collected so far", "tweet_id", tweetID, "page", page, "error", err)
+			break
+		}
+
+		pageContent, pageAuthor, pageMedia, pageEntities, pageQuotedTweet, pageComments, nextCursor := parseTweetDetailPage(&detailResp)
+		if pageContent != "" {
+			tweetContent = pageContent
+			tweetAuthor = pageAuthor
+			tweetMedia = pageMedia
+			tweetEntities = pageEntities
+			tweetQuotedTweet = pageQuotedTweet
+		}
+		comments = append(comments, pageComments...)
+
+		if nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+
+		if e.Config.TwitterMaxComments > 0 && len(comments) >= e.Config.TwitterMaxComments {
+			break
+		}
+	}
+
+	if tweetContent == "" {
+		return nil, fmt.Errorf("could not find main tweet content in the API response")
+	}
+
+	if e.Config.TwitterMaxComments > 0 && len(comments) > e.Config.TwitterMaxComments {
+		comments = comments[:e.Config.TwitterMaxComments]
+	}
+
+	return &TwitterData{
+		TweetContent:  tweetContent,
+		TweetAuthor:   tweetAuthor,
+		Comments:      buildCommentTree(comments, tweetID, e.Config.TwitterMaxThreadDepth),
+		TotalComments: len(comments),
+		Media:         tweetMedia,
+		QuotedTweet:   tweetQuotedTweet,
+		Entities:      tweetEntities,
+	}, nil
+}
+
+// graphQLGet issues a GET against one of Twitter/X's api.twitter.com GraphQL endpoints,
+// authorized with e's bearer token or OAuth1a credentials (see authorize), and returns
+// the raw response body for the caller to unmarshal.
+func (e *TwitterAPIExtractor) graphQLGet(ctx context.Context, apiURL string, variables, features map[string]interface{}) ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL variables: %w", err)
+	}
+	featuresJSON, err := json.Marshal(features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL features: %w", err)
+	}
+
+	q := url.Values{}
+	q.Set("variables", string(variablesJSON))
+	q.Set("features", string(featuresJSON))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	if err := e.authorize(req, q); err != nil {
+		return nil, fmt.Errorf("failed to authorize GraphQL request: %w", err)
+	}
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("graphql request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graphql response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorForStatus(resp, fmt.Sprintf("graphql request to %s failed", apiURL))
+	}
+
+	return body, nil
+}
+
+// authorize sets req's Authorization header: an OAuth1a HMAC-SHA1 signature (built from
+// req's URL and query, per RFC 5849) when e.oauth1 is set, otherwise e.Config's bearer
+// token.
+func (e *TwitterAPIExtractor) authorize(req *http.Request, query url.Values) error {
+	if e.oauth1 != nil {
+		header, err := e.oauth1.authorizationHeader(req.Method, req.URL, query)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", header)
+		return nil
+	}
+	req.Header.Set("Authorization", "Bearer "+e.Config.TwitterBearerToken)
+	return nil
+}
+
+// authorizationHeader builds an OAuth1.0a "Authorization: OAuth ..." header for a GET
+// request to reqURL with query query, signed HMAC-SHA1 per RFC 5849 section 3.4.
+func (c *oauth1Credentials) authorizationHeader(method string, reqURL *url.URL, query url.Values) (string, error) {
+	nonce, err := oauth1Nonce()
+	if err != nil {
+		return "", err
+	}
+
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     c.consumerKey,
+		"oauth_nonce":            nonce,
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_token":            c.accessToken,
+		"oauth_version":          "1.0",
+	}
+
+	signingParams := url.Values{}
+	for k, v := range query {
+		signingParams[k] = v
+	}
+	for k, v := range oauthParams {
+		signingParams.Set(k, v)
+	}
+
+	baseURL := (&url.URL{Scheme: reqURL.Scheme, Host: reqURL.Host, Path: reqURL.Path}).String()
+	baseString := method + "&" + url.QueryEscape(baseURL) + "&" + url.QueryEscape(encodeOAuthParams(signingParams))
+	signingKey := url.QueryEscape(c.consumerSecret) + "&" + url.QueryEscape(c.accessSecret)
+
+	mac := hmac.New(sha1.New, []byte(signingKey))
+	mac.Write([]byte(baseString))
+	oauthParams["oauth_signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	keys := make([]string, 0, len(oauthParams))
+	for k := range oauthParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("OAuth ")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf(`%s="%s"`, k, url.QueryEscape(oauthParams[k])))
+	}
+	return b.String(), nil
+}
+
+// encodeOAuthParams percent-encodes and joins params in sorted key order, per RFC 5849's
+// signature base string construction.
+func encodeOAuthParams(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range params[k] {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// oauth1Nonce generates a random, URL-safe nonce for one OAuth1a request.
+func oauth1Nonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate oauth nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// twitterContentExtractor picks between TwitterAPIExtractor and TwitterExtractor per
+// request, rather than replacing one with the other, because TwitterAPIExtractor only
+// implements the single-tweet TweetDetail lookup: profile and search URLs, and any
+// single-tweet request that errors out, still need TwitterExtractor's browser/guest-token
+// path.
+type twitterContentExtractor struct {
+	api     *TwitterAPIExtractor
+	browser *TwitterExtractor
+}
+
+// NewTwitterContentExtractor is the Twitter/X entry point Dispatcher registers: it wraps
+// TwitterExtractor (browser/guest-token, always available) and, when this deployment has
+// its own Twitter API credentials configured, TwitterAPIExtractor as well (see
+// hasTwitterAPICredentials). NewTwitterExtractor's own signature and behavior are left
+// untouched, since twitter_test.go calls unexported methods directly on its concrete
+// return type.
+func NewTwitterContentExtractor(appConfig *config.AppConfig, browserPool *browser.Pool, factory TransportFactory) (Extractor, error) {
+	browserExtractor, err := NewTwitterExtractor(appConfig, browserPool, factory)
+	if err != nil {
+		return nil, err
+	}
+
+	apiExtractor, ok, err := NewTwitterAPIExtractor(appConfig, factory)
+	if err != nil {
+		slog.Warn("Failed to initialize TwitterAPIExtractor, falling back to browser/guest-token extraction for all Twitter/X URLs", "error", err)
+		return browserExtractor, nil
+	}
+	if !ok {
+		return browserExtractor, nil
+	}
+
+	return &twitterContentExtractor{api: apiExtractor, browser: browserExtractor}, nil
+}
+
+// Extract routes plain tweet URLs to e.api, with e.browser as fallback on error; profile
+// and search URLs always go straight to e.browser, which is the only one of the two that
+// implements them.
+func (e *twitterContentExtractor) Extract(ctx context.Context, targetURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
+	if _, ok := twitterSearchQueryFromURL(targetURL); ok {
+		return e.browser.Extract(ctx, targetURL, endpoint, maxChars, result)
+	}
+	if isProfileURL(targetURL) {
+		return e.browser.Extract(ctx, targetURL, endpoint, maxChars, result)
+	}
+
+	if err := e.api.Extract(ctx, targetURL, endpoint, maxChars, result); err != nil {
+		slog.Warn("TwitterAPIExtractor failed, falling back to browser/guest-token extraction", "url", targetURL, "error", err)
+		return e.browser.Extract(ctx, targetURL, endpoint, maxChars, result)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// TwitterSearchResult holds the tweets TwitterExtractor.Search collected for a query.
+type TwitterSearchResult struct {
+	Query  string         `json:"query"`
+	Tweets []TweetExtract `json:"tweets"`
+}
+
+// searchTimelineResponse mirrors the subset of the SearchTimeline GraphQL response this
+// extractor cares about: a timeline of entries with the same shape UserTweets and
+// TweetDetail use, wrapped under search_by_raw_query instead of user/threaded_conversation.
+type searchTimelineResponse struct {
+	Data struct {
+		SearchByRawQuery struct {
+			SearchTimeline struct {
+				Timeline struct {
+					Instructions []struct {
+						Type    string  `json:"type"`
+						Entries []Entry `json:"entries"`
+					} `json:"instructions"`
+				} `json:"timeline"`
+			} `json:"search_timeline"`
+		} `json:"search_by_raw_query"`
+	} `json:"data"`
+}
+
+// twitterSearchQueryFromURL extracts the SearchTimeline query a Twitter/X search or hashtag
+// URL represents: the raw "q" parameter for "/search", or "#tag" for "/hashtag/tag". It
+// returns ok=false for any other Twitter/X URL (single tweet, profile, etc.) or non-Twitter
+// domain.
+func twitterSearchQueryFromURL(targetURL string) (query string, ok bool) {
+	if !strings.Contains(targetURL, "://") {
+		targetURL = "https://" + targetURL
+	}
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil || !IsTwitterDomain(parsedURL.Hostname()) {
+		return "", false
+	}
+
+	path := strings.Trim(parsedURL.Path, "/")
+	switch {
+	case path == "search":
+		if q := parsedURL.Query().Get("q"); q != "" {
+			return q, true
+		}
+	case strings.HasPrefix(path, "hashtag/"):
+		if tag := strings.TrimPrefix(path, "hashtag/"); tag != "" {
+			return "#" + tag, true
+		}
+	}
+	return "", false
+}
+
+// Search runs query against the SearchTimeline GraphQL operation, unauthenticated via a
+// guest token like fetchTweetDetailGuest. product selects the result ranking ("Latest" or
+// "Top"; defaults to Config.TwitterSearchProduct), sinceID stops pagination once that tweet
+// id is reached (for incremental polling, see HandleTwitterStream), and maxResults caps how
+// many tweets are returned (defaults to Config.TwitterSearchMaxResults). Results are
+// de-duplicated by rest_id, since SearchTimeline can repeat a tweet across adjacent pages.
+func (e *TwitterExtractor) Search(ctx context.Context, query, product, sinceID string, maxResults int) (*TwitterSearchResult, error) {
+	if product == "" {
+		product = e.Config.TwitterSearchProduct
+	}
+	if maxResults <= 0 {
+		maxResults = e.Config.TwitterSearchMaxResults
+	}
+
+	guestToken, err := e.getGuestToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain guest token: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var tweets []TweetExtract
+	cursor := ""
+
+	for page := 0; page < twitterMaxTweetDetailPages && len(tweets) < maxResults; page++ {
+		variables := map[string]interface{}{
+			"rawQuery":    query,
+			"count":       20,
+			"querySource": "typed_query",
+			"product":     product,
+		}
+		if cursor != "" {
+			variables["cursor"] = cursor
+		}
+
+		apiURL := fmt.Sprintf("https://twitter.com/i/api/graphql/%s/SearchTimeline", twitterQueryIDSearchTimeline)
+		body, err := e.graphQLGet(ctx, guestToken, apiURL, variables, twitterGraphQLFeatures)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			slog.Warn("TwitterExtractor: paginated SearchTimeline request failed, returning results collected so far", "query", query, "page", page, "error", err)
+			break
+		}
+
+		var searchResp searchTimelineResponse
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		if err := json.Unmarshal(body, &searchResp); err != nil {
+			if page == 0 {
+				return nil, fmt.Errorf("failed to parse SearchTimeline response: %w", err)
+			}
+			slog.Warn("TwitterExtractor: failed to parse a paginated SearchTimeline response, returning results collected so far", "query", query, "page", page, "error", err)
+			break
+		}
+
+		nextCursor := ""
+		reachedSinceID := false
+		for _, instruction := range searchResp.Data.SearchByRawQuery.SearchTimeline.Timeline.Instructions {
+			if instruction.Type != "TimelineAddEntries" {
+				continue
+			}
+			for _, entry := range instruction.Entries {
+				switch {
+				case strings.HasPrefix(entry.EntryID, "tweet-"):
+					tweetResult := entry.Content.ItemContent.TweetResults.Result
+					if tweetResult.Typename != "Tweet" || tweetResult.Legacy.FullText == "" {
+						continue
+					}
+					if sinceID != "" && tweetResult.RestID == sinceID {
+						reachedSinceID = true
+						continue
+					}
+					if seen[tweetResult.RestID] {
+						continue
+					}
+					seen[tweetResult.RestID] = true
+					screenName := tweetResult.Core.UserResults.Result.Legacy.ScreenName
+					tweets = append(tweets, TweetExtract{
+						URL:  fmt.Sprintf("https://x.com/%s/status/%s", screenName, tweetResult.RestID),
+						Data: tweetDataFromResult(tweetResult),
+					})
+				case entry.Content.EntryType == "TimelineTimelineCursor" && strings.HasPrefix(entry.EntryID, "cursor-bottom-"):
+					if entry.Content.Value != "" {
+						nextCursor = entry.Content.Value
+					}
+				}
+			}
+		}
+
+		if len(tweets) >= maxResults || reachedSinceID || nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	if len(tweets) > maxResults {
+		tweets = tweets[:maxResults]
+	}
+
+	return &TwitterSearchResult{Query: query, Tweets: tweets}, nil
+}
+
+// ExtractTweetID recovers a tweet's id from a TweetExtract.URL, such as the ones Search
+// returns. It's exported for callers outside this package (see HandleTwitterStream) that
+// need to track the newest tweet id seen between polls.
+func ExtractTweetID(tweetURL string) string {
+	return extractTweetID(tweetURL)
+}
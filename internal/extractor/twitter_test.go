@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"testing"
@@ -235,7 +236,10 @@ func TestTwitterExtractorWithContext(t *testing.T) {
 		t.Skip("Skipping Twitter login test - TWITTER_USERNAME and TWITTER_PASSWORD not set")
 	}
 
-	extractor := NewTwitterExtractor(appConfig)
+	extractor, err := NewTwitterExtractor(appConfig, nil, NewTransportFactory(appConfig, &http.Client{}))
+	if err != nil {
+		t.Fatalf("Failed to create TwitterExtractor: %v", err)
+	}
 
 	// Test with timeout context
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -247,7 +251,7 @@ func TestTwitterExtractorWithContext(t *testing.T) {
 	// Cancel context immediately to test cancellation handling
 	cancel()
 	
-	_, err := extractor.extractTweetDataWithContext(ctx, "1234567890", "https://twitter.com/test/status/1234567890")
+	_, err = extractor.extractTweetDataWithContext(ctx, "1234567890", "https://twitter.com/test/status/1234567890")
 	if err == nil {
 		t.Error("Expected error when context is cancelled")
 	} else if !strings.Contains(err.Error(), "context cancelled") {
@@ -347,12 +351,16 @@ func TestTwitterExtractorIntegration(t *testing.T) {
 		t.Skip("Skipping integration test - TWITTER_USERNAME and TWITTER_PASSWORD not set")
 	}
 
-	extractor := NewTwitterExtractor(appConfig)
+	extractor, err := NewTwitterExtractor(appConfig, nil, NewTransportFactory(appConfig, &http.Client{}))
+	if err != nil {
+		t.Fatalf("Failed to create TwitterExtractor: %v", err)
+	}
 
 	// Test with a known public tweet
 	testURL := "https://twitter.com/Twitter/status/1683542487476011008"
 	
-	result, err := extractor.Extract(testURL)
+	result := &ExtractedResult{}
+	err = extractor.Extract(context.Background(), testURL, "/extract", nil, result)
 	if err != nil {
 		t.Fatalf("Failed to extract tweet: %v", err)
 	}
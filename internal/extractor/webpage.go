@@ -1,18 +1,18 @@
 package extractor
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/gocolly/colly/v2"
 
 	"web-search-api-for-llms/internal/config"
-	"web-search-api-for-llms/internal/logger"
+	"web-search-api-for-llms/internal/metrics"
 	"web-search-api-for-llms/internal/useragent"
 )
 
@@ -22,73 +22,82 @@ type WebpageExtractor struct {
 }
 
 // NewWebpageExtractor creates a new WebpageExtractor.
-func NewWebpageExtractor(appConfig *config.AppConfig, client *http.Client) *WebpageExtractor {
-	return &WebpageExtractor{
-		BaseExtractor: NewBaseExtractor(appConfig, client),
+func NewWebpageExtractor(appConfig *config.AppConfig, factory TransportFactory) (*WebpageExtractor, error) {
+	base, err := NewBaseExtractor(appConfig, factory, "")
+	if err != nil {
+		return nil, err
 	}
+	return &WebpageExtractor{
+		BaseExtractor: base,
+	}, nil
 }
 
-// Extract uses Colly to scrape visible text content and title from a webpage.
-func (e *WebpageExtractor) Extract(url string, endpoint string, maxChars *int, result *ExtractedResult) error {
+// Extract downloads url and scrapes its visible text content and title. A response
+// under e.Config.StreamThresholdBytes is buffered and parsed with goquery for full
+// fidelity (title, JSON-LD/structured data, readability fallback); a larger one is
+// handed to extractFromStream instead, so an adversarial or oversized page can't spike
+// the HTTP worker pool's memory with a full DOM.
+func (e *WebpageExtractor) Extract(ctx context.Context, url string, endpoint string, maxChars *int, result *ExtractedResult) error {
+	start := time.Now()
+	defer func() { metrics.ExtractionDuration.WithLabelValues("webpage").Observe(time.Since(start).Seconds()) }()
+
 	slog.Info("WebpageExtractor: Starting extraction", "url", url)
 	result.SourceType = "webpage"
 
-	c := colly.NewCollector(
-		colly.MaxDepth(1),
-		colly.UserAgent(useragent.RandomDesktop()),
-	)
-
-	// Create a new http.Client for this request to avoid data races
-	// on the shared client. This is a shallow copy, so it will reuse
-	// the transport (and thus connection pooling).
-	client := *e.HTTPClient
-	client.Timeout = 10 * time.Second
-	c.SetClient(&client)
-
-	var pageTitle string
-	var textContentBuilder strings.Builder
-	var collyErr error
-
-	c.OnHTML("title", func(h *colly.HTMLElement) {
-		pageTitle = strings.TrimSpace(h.Text)
-	})
-
-	// Remove common non-content elements before extracting text
-	c.OnHTML("script, style, noscript, iframe, nav, footer, header, aside, form, menu", func(h *colly.HTMLElement) {
-		h.DOM.Remove()
-	})
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for %s: %w", url, err)
+	}
+	req.Header.Set("User-Agent", useragent.RandomDesktop())
 
-	c.OnHTML("body", func(h *colly.HTMLElement) {
-		// A more robust way to get clean text content
-		textContentBuilder.WriteString(h.DOM.Text())
-	})
+	resp, err := e.DoDecoded(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("WebpageExtractor: failed to close response body", "url", url, "error", err)
+		}
+	}()
 
-	c.OnError(func(r *colly.Response, err error) {
-		errMsg := fmt.Sprintf("Colly request failed: status_code=%d, error=%v", r.StatusCode, err)
-		logger.LogError("WebpageExtractor: Error scraping", "url", url, "error", errMsg)
-		collyErr = errors.New(errMsg)
-	})
+	if resp.StatusCode != http.StatusOK {
+		if _, classifiedErr := ClassifyHTTPStatus(resp.StatusCode, retryAfter(resp)); classifiedErr != nil {
+			return classifiedErr
+		}
+		return fmt.Errorf("fetch failed for %s with status %s", url, resp.Status)
+	}
 
-	c.OnScraped(func(r *colly.Response) {
-		slog.Info("WebpageExtractor: Finished scraping", "url", url, "title", pageTitle, "text_length", textContentBuilder.Len())
-	})
+	threshold := e.Config.StreamThresholdBytes
+	if threshold <= 0 {
+		threshold = 2 * 1024 * 1024
+	}
 
-	if err := c.Visit(url); err != nil {
-		if collyErr != nil {
-			return fmt.Errorf("failed to visit and scrape webpage: %w (colly error: %v)", err, collyErr)
-		}
-		return fmt.Errorf("failed to visit and scrape webpage: %w", err)
+	// Read up to threshold+1 bytes: reaching EOF within that means the whole body fits
+	// under threshold (the common case), read by actual bytes seen rather than trusting
+	// Content-Length, which is often absent (chunked transfer) or inaccurate.
+	prefix, err := io.ReadAll(io.LimitReader(resp.Body, threshold+1))
+	if err != nil {
+		return fmt.Errorf("failed to read content from %s: %w", url, err)
 	}
 
-	if collyErr != nil {
-		return collyErr
+	if int64(len(prefix)) <= threshold {
+		return e.ExtractFromContent(url, prefix, maxChars, result)
 	}
 
-	textContent := strings.TrimSpace(textContentBuilder.String())
+	slog.Info("WebpageExtractor: response exceeds stream threshold, switching to streaming extraction", "url", url, "threshold_bytes", threshold)
+	effectiveMaxChars := -1
+	if maxChars != nil {
+		effectiveMaxChars = *maxChars
+	}
+	if effectiveMaxChars < 0 {
+		effectiveMaxChars = defaultStreamMaxChars
+	}
+	pageTitle, textContent := extractFromStream(io.MultiReader(strings.NewReader(string(prefix)), resp.Body), effectiveMaxChars)
 
-	// Truncate if necessary
-	if maxChars != nil && len(textContent) > *maxChars {
-		textContent = textContent[:*maxChars]
+	// Discard whatever's left of the body (extractFromStream may have returned early
+	// once it hit effectiveMaxChars) so the connection can still be reused.
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		slog.Warn("WebpageExtractor: failed to drain remaining response body", "url", url, "error", err)
 	}
 
 	result.ProcessedSuccessfully = true
@@ -97,9 +106,15 @@ func (e *WebpageExtractor) Extract(url string, endpoint string, maxChars *int, r
 		Title:       pageTitle,
 	}
 
+	slog.Info("WebpageExtractor: Finished streaming extraction", "url", url, "title", pageTitle, "text_length", len(textContent))
 	return nil
 }
 
+// defaultStreamMaxChars bounds extractFromStream's output when the caller didn't pass a
+// maxChars cap of its own; streaming exists to bound memory, so "no cap" still needs a
+// concrete (generous) limit rather than an unbounded ring buffer.
+const defaultStreamMaxChars = 10 * 1024 * 1024
+
 // ExtractFromContent extracts content from a pre-fetched byte slice.
 func (e *WebpageExtractor) ExtractFromContent(url string, content []byte, maxChars *int, result *ExtractedResult) error {
 	slog.Info("WebpageExtractor: Starting extraction from content", "url", url)
@@ -111,10 +126,25 @@ func (e *WebpageExtractor) ExtractFromContent(url string, content []byte, maxCha
 	}
 
 	pageTitle := strings.TrimSpace(doc.Find("title").Text())
+
+	var jsonLDBlocks []string
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		jsonLDBlocks = append(jsonLDBlocks, s.Text())
+	})
+	data := extractStructuredData(doc, jsonLDBlocks)
+	var structured *WebpageStructuredData
+	if data != (WebpageStructuredData{}) {
+		structured = &data
+	}
+
 	// Remove common non-content elements before extracting text
 	doc.Find("script, style, noscript, iframe, nav, footer, header, aside, form, menu").Remove()
 	textContent := strings.TrimSpace(doc.Find("body").Text())
 
+	if len(textContent) < minReadableTextLength && data.ArticleBody != "" {
+		textContent = data.ArticleBody
+	}
+
 	// Truncate if necessary
 	if maxChars != nil && len(textContent) > *maxChars {
 		textContent = textContent[:*maxChars]
@@ -124,6 +154,7 @@ func (e *WebpageExtractor) ExtractFromContent(url string, content []byte, maxCha
 	result.Data = WebpageData{
 		TextContent: textContent,
 		Title:       pageTitle,
+		Structured:  structured,
 	}
 
 	slog.Info("WebpageExtractor: Finished extracting from content", "url", url, "title", pageTitle, "text_length", len(textContent))
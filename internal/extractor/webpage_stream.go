@@ -0,0 +1,77 @@
+package extractor
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// streamDropTags mirrors the goquery path's "script, style, noscript, iframe, nav,
+// footer, header, aside, form, menu" removal (see WebpageExtractor.ExtractFromContent),
+// so the streaming path drops the same boilerplate without ever building a DOM.
+var streamDropTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "iframe": true,
+	"nav": true, "footer": true, "header": true, "aside": true, "form": true, "menu": true,
+}
+
+// extractFromStream walks r incrementally with an html.Tokenizer instead of buffering
+// the whole body into a goquery DOM, so a multi-hundred-MB page (a Wikipedia dump, a
+// sitemap index, a JS bundle served with the wrong Content-Type) can be extracted
+// without holding it all in memory at once. It tracks a stack of currently-open "drop"
+// tags and appends everything else's text to a bounded buffer, returning as soon as
+// maxChars is reached so the caller can stop reading the rest of the body rather than
+// paying to download it. There is no JSON-LD/structured-data extraction in this path: a
+// page large enough to need streaming isn't one operators run structured-data pipelines
+// against, and scanning for it would mean buffering script bodies anyway.
+func extractFromStream(r io.Reader, maxChars int) (title, textContent string) {
+	z := html.NewTokenizer(r)
+	var text strings.Builder
+	var dropStack []string
+	var inTitle bool
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if tag == "title" {
+				inTitle = true
+			}
+			if streamDropTags[tag] && tt == html.StartTagToken {
+				dropStack = append(dropStack, tag)
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if tag == "title" {
+				inTitle = false
+			}
+			if len(dropStack) > 0 && dropStack[len(dropStack)-1] == tag {
+				dropStack = dropStack[:len(dropStack)-1]
+			}
+		case html.TextToken:
+			if len(dropStack) > 0 {
+				continue
+			}
+			chunk := string(z.Text())
+			if inTitle {
+				if title == "" {
+					title = strings.TrimSpace(chunk)
+				}
+				continue
+			}
+			text.WriteString(chunk)
+			if text.Len() >= maxChars {
+				return title, text.String()[:maxChars]
+			}
+		}
+	}
+
+	return title, strings.TrimSpace(text.String())
+}
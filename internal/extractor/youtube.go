@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,25 +22,155 @@ import (
 // YouTubeExtractor implements the Extractor interface for YouTube URLs.
 type YouTubeExtractor struct {
 	BaseExtractor
+	// keyless is set once at construction from whether Config.YouTubeAPIKey is empty.
+	// When true, the four fetchVideo*/fetchPlaylist* methods scrape the watch/playlist
+	// page's ytInitialData instead of calling the billable, rate-limited Data API (see
+	// fetchVideoDetailsKeyless and friends in youtube_keyless.go).
+	keyless bool
+
+	// transcriptProviders holds one TranscriptProvider instance per registered factory in
+	// transcriptProviderFactories (see internal/extractor/transcript.go), built once at
+	// construction so they reuse this extractor's HTTPClient/Config.
+	transcriptProviders map[string]TranscriptProvider
+	// transcriptBreakers holds one transcriptCircuitBreaker per entry in
+	// transcriptProviders, tracking that provider's recent failures independently of the
+	// others.
+	transcriptBreakers map[string]*transcriptCircuitBreaker
+	// transcriptMaxRetries and transcriptRetryBackoff configure
+	// fetchTranscriptWithRetry's per-provider retry policy, copied from
+	// Config.TranscriptProviderMaxRetries/TranscriptProviderBackoff.
+	transcriptMaxRetries   int
+	transcriptRetryBackoff time.Duration
+	// transcriptCircuitThreshold and transcriptCircuitCooldown configure when a provider's
+	// breaker trips and how long it then stays open, copied from
+	// Config.TranscriptCircuitBreakerThreshold/TranscriptCircuitBreakerCooldown.
+	transcriptCircuitThreshold int
+	transcriptCircuitCooldown  time.Duration
+
+	// kv is the on-disk sub-resource cache (internal/extractor/kvcache.go) memoizing
+	// fetchVideoDetails/fetchVideoComments/fetchPlaylistDetails/fetchPlaylistItems and
+	// transcripts by ID, or nil when Config.YouTubeCacheDir is empty (caching disabled).
+	kv *kvCache
+	// kvTTLMeta, kvTTLComments, and kvTTLTranscript mirror
+	// Config.YouTubeCacheTTLMeta/Comments/Transcript; read alongside kv so callers don't
+	// need a nil-Config check on every cache lookup.
+	kvTTLMeta       time.Duration
+	kvTTLComments   time.Duration
+	kvTTLTranscript time.Duration
+}
+
+// TranscriptSegment is one caption entry of a video's transcript, with its position in
+// the video so callers can align text to timestamps or request just a time window.
+type TranscriptSegment struct {
+	Start    float64 `json:"start"`
+	Duration float64 `json:"duration"`
+	Text     string  `json:"text"`
+}
+
+// TranscriptOptions narrows transcript extraction: Start/End restrict it to a time
+// window in seconds (End == 0 means no upper bound), and Language selects a caption
+// track's BCP-47 code instead of the "en" extractTranscriptWithTactiq otherwise
+// hardcodes. The zero value extracts the full English transcript, today's behavior.
+type TranscriptOptions struct {
+	Start    float64
+	End      float64
+	Language string
+}
+
+type transcriptOptionsContextKey struct{}
+
+// NewTranscriptContext returns a copy of ctx carrying opts, retrievable by
+// YouTubeExtractor via TranscriptOptionsFromContext without changing the Extractor
+// interface's signature (the same pattern as readability.NewContext/FromContext).
+func NewTranscriptContext(ctx context.Context, opts TranscriptOptions) context.Context {
+	return context.WithValue(ctx, transcriptOptionsContextKey{}, opts)
+}
+
+// TranscriptOptionsFromContext returns the TranscriptOptions ctx carries, defaulting to
+// the zero value (full English transcript) if the request didn't set one.
+func TranscriptOptionsFromContext(ctx context.Context) TranscriptOptions {
+	opts, _ := ctx.Value(transcriptOptionsContextKey{}).(TranscriptOptions)
+	return opts
+}
+
+// PlaylistOptions narrows how Extract and extractPlaylist handle playlist URLs. Prefer
+// selects which path Extract takes when a URL carries both a video ID and a playlist ID
+// (e.g. a "watch?v=...&list=..." link): "playlist", the zero value, preserves today's
+// behavior of extracting the whole playlist; "video" extracts just that video instead.
+// MaxVideos caps how many items extractPlaylist accumulates for this request, overriding
+// Config.MaxPlaylistItems when smaller and non-zero. The zero value matches today's
+// behavior (playlist, Config.MaxPlaylistItems).
+type PlaylistOptions struct {
+	Prefer    string
+	MaxVideos int
+}
+
+type playlistOptionsContextKey struct{}
+
+// NewPlaylistContext returns a copy of ctx carrying opts, retrievable by
+// YouTubeExtractor via PlaylistOptionsFromContext without changing the Extractor
+// interface's signature (the same pattern as TranscriptOptions above).
+func NewPlaylistContext(ctx context.Context, opts PlaylistOptions) context.Context {
+	return context.WithValue(ctx, playlistOptionsContextKey{}, opts)
+}
+
+// PlaylistOptionsFromContext returns the PlaylistOptions ctx carries, defaulting to the
+// zero value (prefer playlist, no per-request cap) if the request didn't set one.
+func PlaylistOptionsFromContext(ctx context.Context) PlaylistOptions {
+	opts, _ := ctx.Value(playlistOptionsContextKey{}).(PlaylistOptions)
+	return opts
 }
 
 // NewYouTubeExtractor creates a new YouTubeExtractor.
-func NewYouTubeExtractor(appConfig *config.AppConfig, client *http.Client) (*YouTubeExtractor, error) {
-	return &YouTubeExtractor{
-		BaseExtractor: NewBaseExtractor(appConfig, client),
-	}, nil
+func NewYouTubeExtractor(appConfig *config.AppConfig, factory TransportFactory) (*YouTubeExtractor, error) {
+	base, err := NewBaseExtractor(appConfig, factory, "youtube.com")
+	if err != nil {
+		return nil, err
+	}
+	e := &YouTubeExtractor{
+		BaseExtractor:              base,
+		keyless:                    appConfig.YouTubeAPIKey == "",
+		transcriptMaxRetries:       appConfig.TranscriptProviderMaxRetries,
+		transcriptRetryBackoff:     appConfig.TranscriptProviderBackoff,
+		transcriptCircuitThreshold: appConfig.TranscriptCircuitBreakerThreshold,
+		transcriptCircuitCooldown:  appConfig.TranscriptCircuitBreakerCooldown,
+		kvTTLMeta:                  appConfig.YouTubeCacheTTLMeta,
+		kvTTLComments:              appConfig.YouTubeCacheTTLComments,
+		kvTTLTranscript:            appConfig.YouTubeCacheTTLTranscript,
+	}
+	e.transcriptProviders = make(map[string]TranscriptProvider, len(transcriptProviderFactories))
+	e.transcriptBreakers = make(map[string]*transcriptCircuitBreaker, len(transcriptProviderFactories))
+	for name, newProvider := range transcriptProviderFactories {
+		e.transcriptProviders[name] = newProvider(e)
+		e.transcriptBreakers[name] = &transcriptCircuitBreaker{}
+	}
+	if appConfig.YouTubeCacheDir != "" {
+		kv, err := newKVCache(appConfig.YouTubeCacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("youtube sub-resource cache: %w", err)
+		}
+		e.kv = kv
+	}
+	return e, nil
 }
 
 // Extract determines if the URL is a video or playlist and calls the appropriate handler.
-func (e *YouTubeExtractor) Extract(videoURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
+func (e *YouTubeExtractor) Extract(ctx context.Context, videoURL string, endpoint string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("YouTubeExtractor: Starting extraction", "url", videoURL)
 
-	if playlistID := extractPlaylistID(videoURL); playlistID != "" {
-		return e.extractPlaylist(videoURL, playlistID, maxChars, result)
+	playlistID := extractPlaylistID(videoURL)
+	videoID := extractVideoID(videoURL)
+
+	if playlistID != "" {
+		// A "watch?v=...&list=..." URL carries both IDs; prefer=video lets a caller pull
+		// just that video (e.g. its transcript) instead of the whole playlist.
+		if opts := PlaylistOptionsFromContext(ctx); opts.Prefer != "video" || videoID == "" {
+			return e.extractPlaylist(ctx, videoURL, playlistID, maxChars, result)
+		}
 	}
 
-	if videoID := extractVideoID(videoURL); videoID != "" {
-		return e.extractVideo(videoURL, videoID, maxChars, result)
+	if videoID != "" {
+		return e.extractVideo(ctx, videoURL, videoID, maxChars, result)
 	}
 
 	result.SourceType = "youtube"
@@ -49,38 +180,50 @@ func (e *YouTubeExtractor) Extract(videoURL string, endpoint string, maxChars *i
 }
 
 // extractVideo fetches title, channel, top comments, and transcript for a single YouTube video.
-func (e *YouTubeExtractor) extractVideo(videoURL string, videoID string, maxChars *int, result *ExtractedResult) error {
+func (e *YouTubeExtractor) extractVideo(ctx context.Context, videoURL string, videoID string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("YouTubeExtractor: Extracted Video ID", "video_id", videoID, "url", videoURL)
 	result.SourceType = "youtube"
 
-	var videoTitle, channelName string
+	transcriptOpts := TranscriptOptionsFromContext(ctx)
+
+	var meta videoMetadata
 	var commentsData []interface{}
 	var transcriptText string
+	var transcriptSegments []TranscriptSegment
 	var wg sync.WaitGroup
 	var errs []string
 	var errsMutex sync.Mutex
 
-	// 1. Fetch Video Details (Title, Channel)
+	var cacheStatus string
+	if e.kv != nil {
+		var probe videoMetadata
+		if e.kv.get("meta", "video:"+videoID, e.kvTTLMeta, &probe) {
+			cacheStatus = "HIT"
+		} else {
+			cacheStatus = "MISS"
+		}
+	}
+
+	// 1. Fetch Video Details (Title, Channel, Duration, Views, Likes, Tags, Category, ...)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		title, chName, err := e.fetchVideoDetails(videoID)
+		m, err := e.fetchVideoDetails(ctx, videoID)
 		if err != nil {
 			errsMutex.Lock()
 			errs = append(errs, fmt.Sprintf("youtube api video details: %v", err))
 			errsMutex.Unlock()
 			return
 		}
-		videoTitle = title
-		channelName = chName
-		slog.Debug("YouTubeExtractor: Fetched video details", "title", videoTitle, "channel", channelName, "video_id", videoID)
+		meta = m
+		slog.Debug("YouTubeExtractor: Fetched video details", "title", meta.Title, "channel", meta.ChannelName, "video_id", videoID)
 	}()
 
 	// 2. Fetch Top Comments
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		comments, err := e.fetchVideoComments(videoID)
+		comments, err := e.fetchVideoComments(ctx, videoID)
 		if err != nil {
 			errsMutex.Lock()
 			errs = append(errs, fmt.Sprintf("youtube api comments: %v", err))
@@ -95,12 +238,12 @@ func (e *YouTubeExtractor) extractVideo(videoURL string, videoID string, maxChar
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		transcriptCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
 		slog.Debug("YouTubeExtractor: Fetching transcript", "video_id", videoID)
 
-		transcript, err := e.extractTranscript(ctx, videoID, videoURL)
+		transcript, segments, err := e.extractTranscript(transcriptCtx, videoID, videoURL, transcriptOpts)
 		if err != nil {
 			errsMutex.Lock()
 			errs = append(errs, fmt.Sprintf("transcript: %v", err))
@@ -109,7 +252,8 @@ func (e *YouTubeExtractor) extractVideo(videoURL string, videoID string, maxChar
 		}
 
 		transcriptText = transcript
-		slog.Debug("YouTubeExtractor: Fetched transcript", "length", len(transcriptText), "video_id", videoID)
+		transcriptSegments = segments
+		slog.Debug("YouTubeExtractor: Fetched transcript", "length", len(transcriptText), "segments", len(transcriptSegments), "video_id", videoID)
 	}()
 
 	wg.Wait()
@@ -120,25 +264,47 @@ func (e *YouTubeExtractor) extractVideo(videoURL string, videoID string, maxChar
 	}
 
 	// Mark as successful if we got at least something
-	if videoTitle != "" || channelName != "" || len(commentsData) > 0 || transcriptText != "" {
+	if meta.Title != "" || meta.ChannelName != "" || len(commentsData) > 0 || transcriptText != "" {
 		if result.Error == "" {
 			result.ProcessedSuccessfully = true
 		}
 	}
 
 	result.Data = YouTubeData{
-		Title:       videoTitle,
-		ChannelName: channelName,
-		Comments:    commentsData,
-		Transcript:  transcriptText,
+		Title:              meta.Title,
+		ChannelName:        meta.ChannelName,
+		Comments:           commentsData,
+		Transcript:         transcriptText,
+		TranscriptSegments: transcriptSegments,
+		CacheStatus:        cacheStatus,
+		Duration:           meta.Duration,
+		ViewCount:          meta.ViewCount,
+		LikeCount:          meta.LikeCount,
+		PublishedAt:        meta.PublishedAt,
+		Category:           meta.Category,
+		Tags:               meta.Tags,
+		Chapters:           parseChapters(meta.Description),
 	}
 
 	if maxChars != nil {
 		if data, ok := result.Data.(YouTubeData); ok {
-			data.Transcript = truncateText(data.Transcript, *maxChars)
+			// Metadata fields (title, duration, tags, chapters, ...) are structured, not
+			// free text, so they're never truncated themselves; they still count against
+			// *maxChars so a caller requesting maxChars=0 for "metadata only" doesn't also
+			// get a full transcript/comment set squeezed in alongside it.
+			budget := *maxChars - metadataCharCount(data)
+			if budget < 0 {
+				budget = 0
+			}
+
+			if data.TranscriptSegments != nil {
+				data.TranscriptSegments, data.Transcript = truncateSegments(data.TranscriptSegments, budget)
+			} else {
+				data.Transcript = truncateText(data.Transcript, budget)
+			}
 
 			// Truncate comments as well
-			remainingChars := *maxChars - len(data.Transcript)
+			remainingChars := budget - len(data.Transcript)
 			if remainingChars > 0 {
 				var truncatedComments []interface{}
 				for _, comment := range data.Comments {
@@ -175,21 +341,42 @@ func (e *YouTubeExtractor) extractVideo(videoURL string, videoID string, maxChar
 }
 
 // extractPlaylist fetches the title, channel, and a list of video titles from a YouTube playlist.
-func (e *YouTubeExtractor) extractPlaylist(playlistURL, playlistID string, maxChars *int, result *ExtractedResult) error {
+func (e *YouTubeExtractor) extractPlaylist(ctx context.Context, playlistURL, playlistID string, maxChars *int, result *ExtractedResult) error {
 	slog.Info("YouTubeExtractor: Starting playlist extraction", "playlist_id", playlistID)
 	result.SourceType = "youtube_playlist"
 
 	// 1. Get Playlist Details (Title, Channel)
-	playlistTitle, channelName, err := e.fetchPlaylistDetails(playlistID)
+	playlistTitle, channelName, err := e.fetchPlaylistDetails(ctx, playlistID)
 	if err != nil {
 		return fmt.Errorf("youtube api playlist details: %w", err)
 	}
 	slog.Debug("YouTubeExtractor: Fetched playlist details", "title", playlistTitle, "channel", channelName)
 
-	// 2. Get Playlist Items (Video IDs and Titles)
-	videoItems, err := e.fetchPlaylistItems(playlistID)
-	if err != nil {
-		return fmt.Errorf("youtube api playlist items: %w", err)
+	// 2. Get Playlist Items (Video IDs and Titles), following nextPageToken until either
+	// the playlist is exhausted or maxItems is reached.
+	maxItems := e.Config.MaxPlaylistItems
+	if opts := PlaylistOptionsFromContext(ctx); opts.MaxVideos > 0 && (maxItems <= 0 || opts.MaxVideos < maxItems) {
+		maxItems = opts.MaxVideos
+	}
+
+	var videoItems []map[string]string
+	pageToken := ""
+	for {
+		items, nextPageToken, err := e.fetchPlaylistItems(ctx, playlistID, pageToken)
+		if err != nil {
+			return fmt.Errorf("youtube api playlist items: %w", err)
+		}
+		videoItems = append(videoItems, items...)
+		slog.Info("YouTubeExtractor: Fetched playlist page", "playlist_id", playlistID, "page_items", len(items), "total_items", len(videoItems))
+
+		if maxItems > 0 && len(videoItems) >= maxItems {
+			videoItems = videoItems[:maxItems]
+			break
+		}
+		if nextPageToken == "" {
+			break
+		}
+		pageToken = nextPageToken
 	}
 	slog.Debug("YouTubeExtractor: Fetched video items from playlist", "count", len(videoItems), "playlist_id", playlistID)
 
@@ -207,41 +394,192 @@ func (e *YouTubeExtractor) extractPlaylist(playlistURL, playlistID string, maxCh
 	return nil
 }
 
-func (e *YouTubeExtractor) fetchVideoDetails(videoID string) (string, string, error) {
-	apiURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/videos?part=snippet&id=%s&key=%s", videoID, e.Config.YouTubeAPIKey)
-	resp, err := e.HTTPClient.Get(apiURL)
+// videoMetadata is everything fetchVideoDetails recovers about a video, beyond the
+// title/channel extractVideo originally surfaced. It doubles as the on-disk shape of a
+// cached fetchVideoDetails result (kind "meta").
+type videoMetadata struct {
+	Title       string   `json:"title"`
+	ChannelName string   `json:"channel_name"`
+	Description string   `json:"-"` // only used to derive Chapters; not itself exposed
+	Duration    int64    `json:"duration_seconds"`
+	ViewCount   int64    `json:"view_count"`
+	LikeCount   int64    `json:"like_count"`
+	PublishedAt string   `json:"published_at"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+}
+
+func (e *YouTubeExtractor) fetchVideoDetails(ctx context.Context, videoID string) (videoMetadata, error) {
+	if e.kv != nil {
+		var cached videoMetadata
+		if e.kv.get("meta", "video:"+videoID, e.kvTTLMeta, &cached) {
+			return cached, nil
+		}
+	}
+	meta, err := e.fetchVideoDetailsUncached(ctx, videoID)
+	if err == nil && e.kv != nil {
+		e.kv.set("meta", "video:"+videoID, meta)
+	}
+	return meta, err
+}
+
+func (e *YouTubeExtractor) fetchVideoDetailsUncached(ctx context.Context, videoID string) (videoMetadata, error) {
+	if e.keyless {
+		return e.fetchVideoDetailsKeyless(ctx, videoID)
+	}
+	apiURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/videos?part=snippet,contentDetails,statistics,topicDetails&id=%s&key=%s", videoID, e.Config.YouTubeAPIKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return "", "", err
+		return videoMetadata{}, err
+	}
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return videoMetadata{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", fmt.Errorf("bad status code: %d", resp.StatusCode)
+		return videoMetadata{}, fmt.Errorf("bad status code: %d", resp.StatusCode)
 	}
 
 	var videoResponse struct {
 		Items []struct {
 			Snippet struct {
-				Title        string `json:"title"`
-				ChannelTitle string `json:"channelTitle"`
+				Title        string   `json:"title"`
+				ChannelTitle string   `json:"channelTitle"`
+				Description  string   `json:"description"`
+				PublishedAt  string   `json:"publishedAt"`
+				CategoryId   string   `json:"categoryId"`
+				Tags         []string `json:"tags"`
 			} `json:"snippet"`
+			ContentDetails struct {
+				Duration string `json:"duration"`
+			} `json:"contentDetails"`
+			Statistics struct {
+				ViewCount string `json:"viewCount"`
+				LikeCount string `json:"likeCount"`
+			} `json:"statistics"`
 		} `json:"items"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&videoResponse); err != nil {
-		return "", "", err
+		return videoMetadata{}, err
 	}
 
 	if len(videoResponse.Items) == 0 {
-		return "", "", errors.New("no video details found")
+		return videoMetadata{}, errors.New("no video details found")
+	}
+
+	item := videoResponse.Items[0]
+	viewCount, _ := strconv.ParseInt(item.Statistics.ViewCount, 10, 64)
+	likeCount, _ := strconv.ParseInt(item.Statistics.LikeCount, 10, 64)
+	return videoMetadata{
+		Title:       item.Snippet.Title,
+		ChannelName: item.Snippet.ChannelTitle,
+		Description: item.Snippet.Description,
+		Duration:    parseISO8601Duration(item.ContentDetails.Duration),
+		ViewCount:   viewCount,
+		LikeCount:   likeCount,
+		PublishedAt: item.Snippet.PublishedAt,
+		Category:    youtubeCategoryName(item.Snippet.CategoryId),
+		Tags:        item.Snippet.Tags,
+	}, nil
+}
+
+// iso8601DurationRe matches an ISO-8601 duration of the form contentDetails.duration
+// uses: "P" optionally followed by a weeks/days date part, "T", then hours/minutes/seconds
+// (e.g. "PT1H2M3S", "P0D" for an unset duration, "PT45S").
+var iso8601DurationRe = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseISO8601Duration converts an ISO-8601 duration string into whole seconds, returning
+// 0 for an empty or unrecognized string (e.g. a livestream's "P0D") rather than erroring,
+// since Duration is best-effort metadata, not something worth failing the whole
+// extraction over.
+func parseISO8601Duration(s string) int64 {
+	m := iso8601DurationRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0
 	}
+	weeks, _ := strconv.ParseInt(m[1], 10, 64)
+	days, _ := strconv.ParseInt(m[2], 10, 64)
+	hours, _ := strconv.ParseInt(m[3], 10, 64)
+	minutes, _ := strconv.ParseInt(m[4], 10, 64)
+	seconds, _ := strconv.ParseInt(m[5], 10, 64)
+	return weeks*7*24*3600 + days*24*3600 + hours*3600 + minutes*60 + seconds
+}
 
-	return videoResponse.Items[0].Snippet.Title, videoResponse.Items[0].Snippet.ChannelTitle, nil
+// VideoChapter is a single chapter marker recovered from a video's description, in
+// YouTube's own "<timestamp> <title>" convention.
+type VideoChapter struct {
+	StartSeconds int64  `json:"start_seconds"`
+	Title        string `json:"title"`
 }
 
-func (e *YouTubeExtractor) fetchVideoComments(videoID string) ([]interface{}, error) {
+// metadataCharCount estimates how many of maxChars a YouTubeData's structured metadata
+// fields consume, so extractVideo can deduct it from the transcript/comment truncation
+// budget instead of letting maxChars=0 ("metadata only") still squeeze in free text.
+func metadataCharCount(data YouTubeData) int {
+	n := len(data.Title) + len(data.ChannelName) + len(data.Category) + len(data.PublishedAt)
+	for _, tag := range data.Tags {
+		n += len(tag)
+	}
+	for _, chapter := range data.Chapters {
+		n += len(chapter.Title)
+	}
+	return n
+}
+
+// videoChapterRe matches a chapter marker line in a video description: a leading
+// timestamp ("0:00", "1:23:45") followed by its title, the convention YouTube's own
+// chapters feature parses descriptions for.
+var videoChapterRe = regexp.MustCompile(`(?m)^\s*(?:(\d+):)?(\d{1,2}):(\d{2})\s*[-–—:]?\s*(.+)$`)
+
+// parseChapters extracts chapter markers from a video's description using the same
+// "<timestamp> <title>" convention YouTube's own chapters feature looks for. Returns nil
+// if the description has fewer than two matching lines, since a single timestamp is more
+// likely an incidental mention than an actual chapter list.
+func parseChapters(description string) []VideoChapter {
+	matches := videoChapterRe.FindAllStringSubmatch(description, -1)
+	if len(matches) < 2 {
+		return nil
+	}
+	chapters := make([]VideoChapter, 0, len(matches))
+	for _, m := range matches {
+		hours, _ := strconv.ParseInt(m[1], 10, 64)
+		minutes, _ := strconv.ParseInt(m[2], 10, 64)
+		seconds, _ := strconv.ParseInt(m[3], 10, 64)
+		chapters = append(chapters, VideoChapter{
+			StartSeconds: hours*3600 + minutes*60 + seconds,
+			Title:        strings.TrimSpace(m[4]),
+		})
+	}
+	return chapters
+}
+
+func (e *YouTubeExtractor) fetchVideoComments(ctx context.Context, videoID string) ([]interface{}, error) {
+	if e.kv != nil {
+		var cached []interface{}
+		if e.kv.get("comments", videoID, e.kvTTLComments, &cached) {
+			return cached, nil
+		}
+	}
+	comments, err := e.fetchVideoCommentsUncached(ctx, videoID)
+	if err == nil && e.kv != nil {
+		e.kv.set("comments", videoID, comments)
+	}
+	return comments, err
+}
+
+func (e *YouTubeExtractor) fetchVideoCommentsUncached(ctx context.Context, videoID string) ([]interface{}, error) {
+	if e.keyless {
+		return e.fetchVideoCommentsKeyless(ctx, videoID)
+	}
 	apiURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/commentThreads?part=snippet&videoId=%s&order=relevance&maxResults=50&key=%s", videoID, e.Config.YouTubeAPIKey)
-	resp, err := e.HTTPClient.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -280,9 +618,36 @@ func (e *YouTubeExtractor) fetchVideoComments(videoID string) ([]interface{}, er
 	return commentsData, nil
 }
 
-func (e *YouTubeExtractor) fetchPlaylistDetails(playlistID string) (string, string, error) {
+// kvPlaylistDetails is the on-disk shape of a cached fetchPlaylistDetails result.
+type kvPlaylistDetails struct {
+	Title       string `json:"title"`
+	ChannelName string `json:"channel_name"`
+}
+
+func (e *YouTubeExtractor) fetchPlaylistDetails(ctx context.Context, playlistID string) (string, string, error) {
+	if e.kv != nil {
+		var cached kvPlaylistDetails
+		if e.kv.get("meta", "playlist:"+playlistID, e.kvTTLMeta, &cached) {
+			return cached.Title, cached.ChannelName, nil
+		}
+	}
+	title, channelName, err := e.fetchPlaylistDetailsUncached(ctx, playlistID)
+	if err == nil && e.kv != nil {
+		e.kv.set("meta", "playlist:"+playlistID, kvPlaylistDetails{Title: title, ChannelName: channelName})
+	}
+	return title, channelName, err
+}
+
+func (e *YouTubeExtractor) fetchPlaylistDetailsUncached(ctx context.Context, playlistID string) (string, string, error) {
+	if e.keyless {
+		return e.fetchPlaylistDetailsKeyless(ctx, playlistID)
+	}
 	apiURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/playlists?part=snippet&id=%s&key=%s", playlistID, e.Config.YouTubeAPIKey)
-	resp, err := e.HTTPClient.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := e.HTTPClient.Do(req)
 	if err != nil {
 		return "", "", err
 	}
@@ -312,16 +677,52 @@ func (e *YouTubeExtractor) fetchPlaylistDetails(playlistID string) (string, stri
 	return playlistResponse.Items[0].Snippet.Title, playlistResponse.Items[0].Snippet.ChannelTitle, nil
 }
 
-func (e *YouTubeExtractor) fetchPlaylistItems(playlistID string) ([]map[string]string, error) {
+// fetchPlaylistItems fetches one page of playlist items starting at pageToken ("" for
+// the first page), returning the next page's token ("" once the playlist is exhausted).
+// The keyless path has no equivalent continuation token (see fetchPlaylistItemsKeyless),
+// so it always returns everything ytInitialData embeds in a single page.
+// kvPlaylistPage is the on-disk shape of a cached fetchPlaylistItems page.
+type kvPlaylistPage struct {
+	Items         []map[string]string `json:"items"`
+	NextPageToken string              `json:"next_page_token"`
+}
+
+func (e *YouTubeExtractor) fetchPlaylistItems(ctx context.Context, playlistID, pageToken string) ([]map[string]string, string, error) {
+	cacheKey := playlistID + ":" + pageToken
+	if e.kv != nil {
+		var cached kvPlaylistPage
+		if e.kv.get("playlist_items", cacheKey, e.kvTTLMeta, &cached) {
+			return cached.Items, cached.NextPageToken, nil
+		}
+	}
+	items, nextPageToken, err := e.fetchPlaylistItemsUncached(ctx, playlistID, pageToken)
+	if err == nil && e.kv != nil {
+		e.kv.set("playlist_items", cacheKey, kvPlaylistPage{Items: items, NextPageToken: nextPageToken})
+	}
+	return items, nextPageToken, err
+}
+
+func (e *YouTubeExtractor) fetchPlaylistItemsUncached(ctx context.Context, playlistID, pageToken string) ([]map[string]string, string, error) {
+	if e.keyless {
+		items, err := e.fetchPlaylistItemsKeyless(ctx, playlistID)
+		return items, "", err
+	}
 	apiURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/playlistItems?part=snippet&playlistId=%s&maxResults=50&key=%s", playlistID, e.Config.YouTubeAPIKey)
-	resp, err := e.HTTPClient.Get(apiURL)
+	if pageToken != "" {
+		apiURL += "&pageToken=" + url.QueryEscape(pageToken)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad status code: %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("bad status code: %d", resp.StatusCode)
 	}
 
 	var playlistItemsResponse struct {
@@ -333,10 +734,11 @@ func (e *YouTubeExtractor) fetchPlaylistItems(playlistID string) ([]map[string]s
 				} `json:"resourceId"`
 			} `json:"snippet"`
 		} `json:"items"`
+		NextPageToken string `json:"nextPageToken"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&playlistItemsResponse); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var videoItems []map[string]string
@@ -347,7 +749,7 @@ func (e *YouTubeExtractor) fetchPlaylistItems(playlistID string) ([]map[string]s
 		})
 	}
 
-	return videoItems, nil
+	return videoItems, playlistItemsResponse.NextPageToken, nil
 }
 
 // Close is no longer needed as there's no python helper process to terminate.
@@ -514,27 +916,31 @@ func isValidYouTubeVideoID(videoID string) bool {
 
 // extractTranscriptWithYTAPI uses the new transcript microservice to fetch a transcript.
 // It returns the transcript text or an error if retrieval/parsing fails.
-func (e *YouTubeExtractor) extractTranscriptWithYTAPI(ctx context.Context, videoID string) (string, error) {
+func (e *YouTubeExtractor) extractTranscriptWithYTAPI(ctx context.Context, videoID string, opts TranscriptOptions) (string, []TranscriptSegment, error) {
 	if e.Config.TranscriptServiceURL == "" {
-		return "", fmt.Errorf("transcript service URL is not configured")
+		return "", nil, fmt.Errorf("transcript service URL is not configured")
 	}
 
 	slog.Debug("YouTubeExtractor: Calling transcript service", "video_id", videoID)
 
-	requestBody, err := json.Marshal(map[string]string{"video_id": videoID})
+	requestPayload := map[string]string{"video_id": videoID}
+	if opts.Language != "" {
+		requestPayload["language"] = opts.Language
+	}
+	requestBody, err := json.Marshal(requestPayload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", e.Config.TranscriptServiceURL+"/get_transcript", bytes.NewBuffer(requestBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request to transcript service: %w", err)
+		return "", nil, fmt.Errorf("failed to create request to transcript service: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := e.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to call transcript service: %w", err)
+		return "", nil, fmt.Errorf("failed to call transcript service: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -547,25 +953,59 @@ func (e *YouTubeExtractor) extractTranscriptWithYTAPI(ctx context.Context, video
 			Detail string `json:"detail"`
 		}
 		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err == nil {
-			return "", fmt.Errorf("transcript service returned error: %s", errorResponse.Detail)
+			return "", nil, fmt.Errorf("transcript service returned error: %s", errorResponse.Detail)
 		}
-		return "", fmt.Errorf("transcript service returned status code %d", resp.StatusCode)
+		return "", nil, fmt.Errorf("transcript service returned status code %d", resp.StatusCode)
 	}
 
+	// Segments is optional: older deployments of the transcript microservice only return
+	// flat text, in which case time-range filtering falls back to character slicing.
 	var successResponse struct {
-		Transcript string `json:"transcript"`
+		Transcript string              `json:"transcript"`
+		Segments   []TranscriptSegment `json:"segments,omitempty"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&successResponse); err != nil {
-		return "", fmt.Errorf("failed to decode response from transcript service: %w", err)
+		return "", nil, fmt.Errorf("failed to decode response from transcript service: %w", err)
 	}
 
 	slog.Debug("YouTubeExtractor: Successfully got transcript from service", "video_id", videoID)
-	return successResponse.Transcript, nil
+	return successResponse.Transcript, successResponse.Segments, nil
+}
+
+// kvTranscript is the on-disk shape of a cached extractTranscript result.
+type kvTranscript struct {
+	Text     string              `json:"text"`
+	Segments []TranscriptSegment `json:"segments"`
+}
+
+// extractTranscript wraps extractTranscriptUncached with e.kv, keyed by videoID plus
+// opts.Language. Only whole-transcript requests (opts.Start == opts.End == 0) go through
+// the cache: extractTranscriptUncached already applies opts' time-range filter before
+// returning, so a windowed request's result can't be safely reused for a different
+// window without re-fetching and re-filtering from scratch anyway.
+func (e *YouTubeExtractor) extractTranscript(ctx context.Context, videoID, videoURL string, opts TranscriptOptions) (string, []TranscriptSegment, error) {
+	cacheable := e.kv != nil && opts.Start <= 0 && opts.End <= 0
+	cacheKey := videoID + ":" + opts.Language
+	if cacheable {
+		var cached kvTranscript
+		if e.kv.get("transcript", cacheKey, e.kvTTLTranscript, &cached) {
+			return cached.Text, cached.Segments, nil
+		}
+	}
+	text, segments, err := e.extractTranscriptUncached(ctx, videoID, videoURL, opts)
+	if err == nil && cacheable {
+		e.kv.set("transcript", cacheKey, kvTranscript{Text: text, Segments: segments})
+	}
+	return text, segments, err
 }
 
-// extractTranscript attempts to retrieve a transcript using youtube-transcript-api first and
-// falls back to Tactiq if necessary.
-func (e *YouTubeExtractor) extractTranscript(ctx context.Context, videoID, videoURL string) (string, error) {
+// extractTranscriptUncached attempts to retrieve a transcript by walking
+// Config.TranscriptOrder's registered TranscriptProviders in order (see
+// internal/extractor/transcript.go), retrying each one per e.transcriptMaxRetries and
+// skipping it entirely while its circuit breaker is open. When opts restricts a time
+// range, the chosen provider's segments (if any) are filtered before being re-joined
+// into the returned flat text.
+func (e *YouTubeExtractor) extractTranscriptUncached(ctx context.Context, videoID, videoURL string, opts TranscriptOptions) (string, []TranscriptSegment, error) {
 	orderStr := "ytapi,tactiq"
 	if e.Config != nil && e.Config.TranscriptOrder != "" {
 		orderStr = e.Config.TranscriptOrder
@@ -574,58 +1014,128 @@ func (e *YouTubeExtractor) extractTranscript(ctx context.Context, videoID, video
 	methods := strings.Split(orderStr, ",")
 
 	for _, m := range methods {
-		m = strings.TrimSpace(strings.ToLower(m))
-		var txt string
-		var err error
-		switch m {
-		case "ytapi", "youtube_api", "youtubeapi":
-			slog.Debug("YouTubeExtractor: Attempting transcript extraction using youtube-transcript-api", "video_id", videoID)
-			txt, err = e.extractTranscriptWithYTAPI(ctx, videoID)
-		case "tactiq":
-			slog.Debug("YouTubeExtractor: Attempting transcript extraction using Tactiq API", "video_id", videoID)
-			txt, err = e.extractTranscriptWithTactiq(ctx, videoURL)
-		default:
-			continue // Unknown token, skip
-		}
-		if err == nil && strings.TrimSpace(txt) != "" {
-			slog.Info("YouTubeExtractor: Successfully extracted transcript", "method", m, "video_id", videoID, "length", len(txt))
-			return txt, nil
-		} else {
-			if err == nil && strings.TrimSpace(txt) == "" {
-				slog.Warn("YouTubeExtractor: Transcript extraction failed, transcript is empty", "method", m, "video_id", videoID)
+		name := strings.TrimSpace(strings.ToLower(m))
+		provider, ok := e.transcriptProviders[name]
+		if !ok {
+			slog.Warn("YouTubeExtractor: Unknown transcript provider, skipping", "provider", name, "video_id", videoID)
+			continue
+		}
+		breaker := e.transcriptBreakers[name]
+		if breaker != nil && !breaker.allow() {
+			slog.Warn("YouTubeExtractor: Transcript provider circuit open, skipping", "provider", name, "video_id", videoID)
+			continue
+		}
+
+		slog.Debug("YouTubeExtractor: Attempting transcript extraction", "provider", name, "video_id", videoID)
+		transcript, err := e.fetchTranscriptWithRetry(ctx, provider, videoID, videoURL, opts.Language)
+
+		if err == nil && strings.TrimSpace(transcript.Text) == "" {
+			err = fmt.Errorf("transcript is empty")
+		}
+		if breaker != nil {
+			if err != nil {
+				breaker.recordFailure(e.transcriptCircuitThreshold, e.transcriptCircuitCooldown)
 			} else {
-				slog.Warn("YouTubeExtractor: Transcript extraction failed", "method", m, "video_id", videoID, "error", err)
+				breaker.recordSuccess()
 			}
 		}
+		if err != nil {
+			slog.Warn("YouTubeExtractor: Transcript extraction failed", "provider", name, "video_id", videoID, "error", err)
+			continue
+		}
+
+		txt, segments := transcript.Text, transcript.Segments
+		slog.Info("YouTubeExtractor: Successfully extracted transcript", "provider", name, "video_id", videoID, "length", len(txt))
+		if segments != nil {
+			segments = filterSegmentsByTimeRange(segments, opts.Start, opts.End)
+			txt = joinSegments(segments)
+		}
+		return txt, segments, nil
 	}
 	slog.Error("YouTubeExtractor: All transcript extraction methods failed", "video_id", videoID, "tried_methods", orderStr)
-	return "", fmt.Errorf("no transcript available via specified order (%s)", orderStr)
+	return "", nil, fmt.Errorf("no transcript available via specified order (%s)", orderStr)
+}
+
+// filterSegmentsByTimeRange keeps only the segments overlapping [start, end) in seconds.
+// end == 0 means no upper bound. A zero-value range (both 0) is a no-op, returning segs
+// unchanged.
+func filterSegmentsByTimeRange(segs []TranscriptSegment, start, end float64) []TranscriptSegment {
+	if start <= 0 && end <= 0 {
+		return segs
+	}
+	filtered := make([]TranscriptSegment, 0, len(segs))
+	for _, seg := range segs {
+		if seg.Start+seg.Duration < start {
+			continue
+		}
+		if end > 0 && seg.Start >= end {
+			continue
+		}
+		filtered = append(filtered, seg)
+	}
+	return filtered
+}
+
+// truncateSegments keeps whole segments up to max characters of joined text, so a
+// maxChars cutoff lands on a caption boundary instead of splitting a word the way
+// truncateText's raw character slicing would. Returns the kept segments and their
+// rejoined text.
+func truncateSegments(segs []TranscriptSegment, max int) ([]TranscriptSegment, string) {
+	kept := make([]TranscriptSegment, 0, len(segs))
+	length := 0
+	for _, seg := range segs {
+		// +1 accounts for the joining space joinSegments inserts between segments.
+		next := length + len(seg.Text) + 1
+		if length > 0 && next > max {
+			break
+		}
+		kept = append(kept, seg)
+		length = next
+	}
+	return kept, joinSegments(kept)
+}
+
+// joinSegments rebuilds a flat transcript string from segs, space-separated the same way
+// extractTranscriptWithTactiq's caption concatenation always has.
+func joinSegments(segs []TranscriptSegment) string {
+	var builder strings.Builder
+	for _, seg := range segs {
+		if seg.Text == "" {
+			continue
+		}
+		builder.WriteString(seg.Text)
+		builder.WriteString(" ")
+	}
+	return strings.TrimSpace(builder.String())
 }
 
 // extractTranscriptWithTactiq calls Tactiq's public transcript endpoint as a last-resort fallback.
 // It requires no authentication and returns JSON containing caption segments.
-func (e *YouTubeExtractor) extractTranscriptWithTactiq(ctx context.Context, videoURL string) (string, error) {
+func (e *YouTubeExtractor) extractTranscriptWithTactiq(ctx context.Context, videoURL string, opts TranscriptOptions) (string, []TranscriptSegment, error) {
 	apiURL := "https://tactiq-apps-prod.tactiq.io/transcript"
 
-	// Prepare request payload. Default to English captions.
+	langCode := opts.Language
+	if langCode == "" {
+		langCode = "en"
+	}
 	bodyMap := map[string]string{
 		"videoUrl": videoURL,
-		"langCode": "en",
+		"langCode": langCode,
 	}
 	bodyBytes, err := json.Marshal(bodyMap)
 	if err != nil {
-		return "", fmt.Errorf("tactiq marshal: %w", err)
+		return "", nil, fmt.Errorf("tactiq marshal: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("tactiq request: %w", err)
+		return "", nil, fmt.Errorf("tactiq request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := e.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("tactiq http: %w", err)
+		return "", nil, fmt.Errorf("tactiq http: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -634,32 +1144,34 @@ func (e *YouTubeExtractor) extractTranscriptWithTactiq(ctx context.Context, vide
 	}()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("tactiq status: %d", resp.StatusCode)
+		return "", nil, fmt.Errorf("tactiq status: %d", resp.StatusCode)
 	}
 
 	// Response structure based on reverse-engineering tactiq front-end.
 	var apiResp struct {
 		Captions []struct {
-			Text string `json:"text"`
+			Text  string  `json:"text"`
+			Start float64 `json:"start"`
+			Dur   float64 `json:"dur"`
 		} `json:"captions"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("tactiq decode: %w", err)
+		return "", nil, fmt.Errorf("tactiq decode: %w", err)
 	}
 
-	var builder strings.Builder
+	segments := make([]TranscriptSegment, 0, len(apiResp.Captions))
 	for _, c := range apiResp.Captions {
-		if c.Text != "" {
-			builder.WriteString(c.Text)
-			builder.WriteString(" ")
+		if c.Text == "" {
+			continue
 		}
+		segments = append(segments, TranscriptSegment{Start: c.Start, Duration: c.Dur, Text: c.Text})
 	}
 
-	transcript := strings.TrimSpace(builder.String())
+	transcript := joinSegments(segments)
 	if transcript == "" {
-		return "", fmt.Errorf("tactiq empty transcript")
+		return "", nil, fmt.Errorf("tactiq empty transcript")
 	}
-	return transcript, nil
+	return transcript, segments, nil
 }
 
 // extractPlaylistID extracts the YouTube playlist ID from a URL.
@@ -0,0 +1,47 @@
+package extractor
+
+// youtubeCategories maps a video's snippet.categoryId to its display name, mirroring the
+// static table YouTube's own API exposes via videoCategories.list (and that yt-dlp/ytsync
+// bundle rather than calling, since the table almost never changes). fetchVideoDetails
+// uses it to turn Data API responses' bare numeric ID into something a caller can read
+// without a second API call.
+var youtubeCategories = map[string]string{
+	"1":  "Film & Animation",
+	"2":  "Autos & Vehicles",
+	"10": "Music",
+	"15": "Pets & Animals",
+	"17": "Sports",
+	"18": "Short Movies",
+	"19": "Travel & Events",
+	"20": "Gaming",
+	"21": "Videoblogging",
+	"22": "People & Blogs",
+	"23": "Comedy",
+	"24": "Entertainment",
+	"25": "News & Politics",
+	"26": "Howto & Style",
+	"27": "Education",
+	"28": "Science & Technology",
+	"29": "Nonprofits & Activism",
+	"30": "Movies",
+	"31": "Anime/Animation",
+	"32": "Action/Adventure",
+	"33": "Classics",
+	"34": "Comedy",
+	"35": "Documentary",
+	"36": "Drama",
+	"37": "Family",
+	"38": "Foreign",
+	"39": "Horror",
+	"40": "Sci-Fi/Fantasy",
+	"41": "Thriller",
+	"42": "Shorts",
+	"43": "Shows",
+	"44": "Trailers",
+}
+
+// youtubeCategoryName looks up id in youtubeCategories, returning "" for an unknown or
+// empty id instead of a placeholder string.
+func youtubeCategoryName(id string) string {
+	return youtubeCategories[id]
+}
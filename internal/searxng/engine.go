@@ -0,0 +1,28 @@
+package searxng
+
+import "context"
+
+// Result is a single search hit returned by a SearchEngine, normalized across engines so
+// Client's aggregation logic (tryEngine, FuseRRF) never needs engine-specific knowledge.
+type Result struct {
+	URL   string
+	Title string
+	// Score is the engine's own relevance signal, used to sort one engine's results when
+	// aggregation falls back to native ranking instead of RRF fusion (see
+	// Client.tryEngine and Client.fetchAggregated); higher is more relevant. Engines that
+	// don't expose a real score (Serper, LibreX, Brave) derive one from result order.
+	Score float64
+}
+
+// SearchEngine is one search backend Client can query: by name in "failover" mode (see
+// AppConfig.MainSearchEngine/FallbackSearchEngine), or all at once in "rrf" mode (see
+// Client.fetchAggregated). SearxNG and Serper are the original two; LibreX/LibreY and
+// Brave were added alongside this interface to prove out the abstraction.
+type SearchEngine interface {
+	// Name identifies the engine in config, logs, and RRF fusion diagnostics.
+	Name() string
+	// Weight scales this engine's contribution to a FuseRRF score.
+	Weight() int
+	// Search returns up to maxResults results for query, ranked best-first.
+	Search(ctx context.Context, query string, maxResults int) ([]Result, error)
+}
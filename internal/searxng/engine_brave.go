@@ -0,0 +1,105 @@
+package searxng
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"web-search-api-for-llms/internal/config"
+)
+
+// braveWebResult matches a single entry in Brave Search API's "web.results" array.
+type braveWebResult struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// braveSearchResponse matches the subset of Brave Search API's response this engine uses.
+type braveSearchResponse struct {
+	Web struct {
+		Results []braveWebResult `json:"results"`
+	} `json:"web"`
+}
+
+// braveEngine queries the Brave Search API.
+type braveEngine struct {
+	apiKey     string
+	apiURL     string
+	weight     int
+	httpClient *http.Client
+}
+
+func newBraveEngine(cfg *config.AppConfig, httpClient *http.Client) *braveEngine {
+	return &braveEngine{apiKey: cfg.BraveAPIKey, apiURL: cfg.BraveAPIURL, weight: cfg.BraveWeight, httpClient: httpClient}
+}
+
+func (e *braveEngine) Name() string { return "brave" }
+func (e *braveEngine) Weight() int  { return e.weight }
+
+// Search fetches results from the Brave Search API. Brave doesn't expose a relevance
+// score, so Result.Score is derived from result order (best first).
+func (e *braveEngine) Search(ctx context.Context, query string, maxResults int) ([]Result, error) {
+	if e.apiKey == "" {
+		return nil, fmt.Errorf("Brave API key not configured")
+	}
+	if e.apiURL == "" {
+		return nil, fmt.Errorf("Brave API URL not configured")
+	}
+
+	apiURL, err := url.Parse(e.apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Brave API URL: %w", err)
+	}
+	params := url.Values{}
+	params.Add("q", query)
+	if maxResults > 0 {
+		params.Add("count", fmt.Sprintf("%d", maxResults))
+	}
+	apiURL.RawQuery = params.Encode()
+
+	slog.Info("Fetching Brave Search results", "query", query, "url", apiURL.String())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Brave Search request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", e.apiKey)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching results from Brave Search: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Brave Search request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var braveResp braveSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&braveResp); err != nil {
+		return nil, fmt.Errorf("error decoding Brave Search response: %w", err)
+	}
+
+	var results []Result
+	for i, item := range braveResp.Web.Results {
+		if item.URL == "" {
+			continue
+		}
+		results = append(results, Result{URL: item.URL, Title: item.Title, Score: 1 / float64(i+1)})
+		if maxResults > 0 && len(results) >= maxResults {
+			break
+		}
+	}
+	slog.Info("Fetched URLs from Brave Search", "count", len(results))
+	return results, nil
+}
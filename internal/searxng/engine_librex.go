@@ -0,0 +1,94 @@
+package searxng
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/useragent"
+)
+
+// libreXResultItem matches a single entry in a LibreX/LibreY instance's api.php JSON
+// response.
+type libreXResultItem struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// libreXEngine queries a self-hosted LibreX (or the fork LibreY) instance's JSON API.
+type libreXEngine struct {
+	baseURL    string
+	weight     int
+	httpClient *http.Client
+}
+
+func newLibreXEngine(cfg *config.AppConfig, httpClient *http.Client) *libreXEngine {
+	return &libreXEngine{baseURL: cfg.LibreXURL, weight: cfg.LibreXWeight, httpClient: httpClient}
+}
+
+func (e *libreXEngine) Name() string { return "librex" }
+func (e *libreXEngine) Weight() int  { return e.weight }
+
+// Search fetches results from LibreX's api.php. LibreX doesn't expose a relevance
+// score, so Result.Score is derived from result order (best first).
+func (e *libreXEngine) Search(ctx context.Context, query string, maxResults int) ([]Result, error) {
+	if e.baseURL == "" {
+		return nil, fmt.Errorf("LibreX URL not configured")
+	}
+
+	apiURL, err := url.Parse(e.baseURL + "/api.php")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing LibreX base URL: %w", err)
+	}
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("p", "0")
+	apiURL.RawQuery = params.Encode()
+
+	slog.Info("Fetching LibreX results", "query", query, "url", apiURL.String())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating LibreX request: %w", err)
+	}
+	req.Header.Set("User-Agent", useragent.Random())
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching results from LibreX: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("LibreX request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var items []libreXResultItem
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("error decoding LibreX response: %w", err)
+	}
+
+	var results []Result
+	for i, item := range items {
+		if item.URL == "" {
+			continue
+		}
+		results = append(results, Result{URL: item.URL, Title: item.Title, Score: 1 / float64(i+1)})
+		if maxResults > 0 && len(results) >= maxResults {
+			break
+		}
+	}
+	slog.Info("Fetched URLs from LibreX", "count", len(results))
+	return results, nil
+}
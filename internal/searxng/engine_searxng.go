@@ -0,0 +1,262 @@
+package searxng
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/logger"
+	"web-search-api-for-llms/internal/useragent"
+)
+
+// SearxNGResultItem matches the structure of individual items in SearxNG's JSON output.
+type SearxNGResultItem struct {
+	URL     string  `json:"url"`
+	Title   string  `json:"title"`
+	Content string  `json:"content"`
+	Score   float64 `json:"score"`
+	Engine  string  `json:"engine"`
+	// Add other fields if needed, e.g., "category", "publishedDate"
+}
+
+// SearxNGResponse matches the top-level structure of SearxNG's JSON output.
+type SearxNGResponse struct {
+	Query               string                `json:"query"`
+	NumberOfResults     int                   `json:"number_of_results"` // This might be total results, not per page.
+	Results             []SearxNGResultItem   `json:"results"`
+	Answers             []jsoniter.RawMessage `json:"answers,omitempty"`     // Using json.RawMessage for fields with variable structure
+	Corrections         []jsoniter.RawMessage `json:"corrections,omitempty"` // Or define specific structs if structure is known and needed
+	Infoboxes           []jsoniter.RawMessage `json:"infoboxes,omitempty"`
+	Suggestions         []string              `json:"suggestions,omitempty"`
+	UnresponsiveEngines [][]string            `json:"unresponsive_engines,omitempty"`
+}
+
+// searxNGEngine queries a SearxNG instance, paginating concurrently. When pool is non-nil
+// (config.SearxNGPoolEnabled), each page request picks its own instance from the pool
+// instead of always using baseURL, spreading concurrent pages across distinct hosts and
+// backing off an instance that 429s, 5xxs, or times out.
+type searxNGEngine struct {
+	baseURL    string
+	weight     int
+	httpClient *http.Client
+	pool       *InstancePool
+}
+
+func newSearxNGEngine(cfg *config.AppConfig, httpClient *http.Client, pool *InstancePool) *searxNGEngine {
+	return &searxNGEngine{baseURL: cfg.SearxNGURL, weight: cfg.SearxNGWeight, httpClient: httpClient, pool: pool}
+}
+
+func (e *searxNGEngine) Name() string { return "searxng" }
+func (e *searxNGEngine) Weight() int  { return e.weight }
+
+// Search fetches results from SearxNG and keeps its own relevance score, so Result.Score
+// reflects SearxNG's ranking rather than a derived one.
+func (e *searxNGEngine) Search(ctx context.Context, query string, maxResults int) ([]Result, error) {
+	items, err := e.fetchItems(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(items))
+	for i, item := range items {
+		results[i] = Result{URL: item.URL, Title: item.Title, Score: item.Score}
+	}
+	return results, nil
+}
+
+// searxNGPageMaxAttempts bounds how many distinct instances fetchPage will try for one
+// page before giving up, so a pool-wide outage fails a page instead of looping forever.
+const searxNGPageMaxAttempts = 3
+
+// fetchPage fetches one page of results, either from e.baseURL (pool disabled) or from an
+// instance picked from e.pool (pool enabled). On a 429, 5xx, or timeout it marks that
+// instance unhealthy via MarkFailure and retries the page against the next-best instance,
+// up to searxNGPageMaxAttempts. tried records every instance any concurrent page has
+// already picked this call to fetchItems, so pages spread across distinct hosts.
+func (e *searxNGEngine) fetchPage(ctx context.Context, query string, pageNum int, triedMu *sync.Mutex, tried map[string]bool) ([]SearxNGResultItem, error) {
+	excluded := make(map[string]bool)
+	var lastErr error
+
+	for attempt := 0; attempt < searxNGPageMaxAttempts; attempt++ {
+		base := e.baseURL
+		if e.pool != nil {
+			triedMu.Lock()
+			picked, ok := e.pool.Pick(mergeExcluded(excluded, tried))
+			if ok {
+				tried[picked] = true
+			}
+			triedMu.Unlock()
+			if !ok {
+				return nil, fmt.Errorf("no SearxNG instances available in pool")
+			}
+			base = picked
+		}
+
+		items, err := e.fetchPageFrom(ctx, base, query, pageNum)
+		if err == nil {
+			if e.pool != nil {
+				e.pool.MarkSuccess(base)
+			}
+			return items, nil
+		}
+		lastErr = err
+		if e.pool == nil {
+			return nil, err
+		}
+		e.pool.MarkFailure(base)
+		excluded[base] = true
+		logger.LogError("SearxNG instance %s failed for page %d, trying next instance: %v", base, pageNum, err)
+	}
+	return nil, lastErr
+}
+
+// mergeExcluded returns the union of two exclusion sets, without mutating either.
+func mergeExcluded(a, b map[string]bool) map[string]bool {
+	merged := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		merged[k] = true
+	}
+	for k := range b {
+		merged[k] = true
+	}
+	return merged
+}
+
+// fetchPageFrom fetches one page of results from a single SearxNG instance at baseURL.
+func (e *searxNGEngine) fetchPageFrom(ctx context.Context, baseURL, query string, pageNum int) ([]SearxNGResultItem, error) {
+	apiURL, err := url.Parse(baseURL + "/search")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SearxNG base URL: %w", err)
+	}
+
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("format", "json")
+	params.Add("pageno", fmt.Sprintf("%d", pageNum))
+	apiURL.RawQuery = params.Encode()
+
+	slog.Debug("Fetching page from SearxNG", "page", pageNum, "url", apiURL.String())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating SearxNG request: %w", err)
+	}
+	req.Header.Set("User-Agent", useragent.RandomFor(apiURL.Hostname()))
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body for page", "page", pageNum, "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("SearxNG request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var searxNGResp SearxNGResponse
+	if err := json.NewDecoder(resp.Body).Decode(&searxNGResp); err != nil {
+		return nil, fmt.Errorf("error decoding SearxNG response: %w", err)
+	}
+
+	slog.Debug("Fetched results from SearxNG page", "count", len(searxNGResp.Results), "page", pageNum)
+	return searxNGResp.Results, nil
+}
+
+// fetchItems fetches search results from a SearxNG instance with concurrent pagination.
+func (e *searxNGEngine) fetchItems(ctx context.Context, query string, maxResults int) ([]SearxNGResultItem, error) {
+	resultsPerPage := 10 // Default assumption for SearxNG
+	maxPages := 5        // Maximum pages to fetch concurrently
+
+	slog.Info("Fetching SearxNG results", "query", query, "max_results", maxResults)
+
+	// Calculate how many pages we might need
+	estimatedPages := (maxResults + resultsPerPage - 1) / resultsPerPage
+	if estimatedPages > maxPages {
+		estimatedPages = maxPages
+	}
+
+	// Create channels for concurrent page fetching
+	type pageResult struct {
+		page  int
+		items []SearxNGResultItem
+		err   error
+	}
+
+	resultsChan := make(chan pageResult, estimatedPages)
+	var wg sync.WaitGroup
+
+	// tried tracks which instance each concurrent page picked, so distinct pages spread
+	// across distinct instances instead of the pool handing out its top-ranked instance
+	// to every page at once. Only consulted when e.pool is set.
+	var triedMu sync.Mutex
+	tried := make(map[string]bool)
+
+	// Fetch pages concurrently
+	for page := 1; page <= estimatedPages; page++ {
+		wg.Add(1)
+		go func(pageNum int) {
+			defer wg.Done()
+
+			// Check if the context has been cancelled before making a request.
+			select {
+			case <-ctx.Done():
+				resultsChan <- pageResult{page: pageNum, err: ctx.Err()}
+				return
+			default:
+			}
+
+			items, err := e.fetchPage(ctx, query, pageNum, &triedMu, tried)
+			resultsChan <- pageResult{page: pageNum, items: items, err: err}
+		}(page)
+	}
+
+	// Wait for all goroutines to complete
+	go func() {
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	// Collect and sort results by page number
+	pageResults := make(map[int][]SearxNGResultItem)
+	var errors []error
+
+	for result := range resultsChan {
+		if result.err != nil {
+			errors = append(errors, result.err)
+			continue
+		}
+		pageResults[result.page] = result.items
+	}
+
+	// If all pages failed, return the first error
+	if len(pageResults) == 0 && len(errors) > 0 {
+		return nil, errors[0]
+	}
+
+	// Combine results in page order
+	var allItems []SearxNGResultItem
+	for page := 1; page <= estimatedPages; page++ {
+		if items, exists := pageResults[page]; exists {
+			allItems = append(allItems, items...)
+			// Stop if we have enough results
+			if len(allItems) >= maxResults*2 && maxResults > 0 {
+				slog.Debug("Collected enough candidates from SearxNG, stopping.", "count", len(allItems))
+				break
+			}
+		}
+	}
+
+	slog.Info("Total items collected from SearxNG", "count", len(allItems))
+	return allItems, nil
+}
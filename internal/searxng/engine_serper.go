@@ -0,0 +1,119 @@
+package searxng
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/useragent"
+)
+
+// SerperOrganicResult defines the structure for a single organic result from Serper API.
+type SerperOrganicResult struct {
+	Title    string `json:"title"`
+	Link     string `json:"link"`
+	Snippet  string `json:"snippet"`
+	Position int    `json:"position"`
+}
+
+// SerperSearchResponse matches the top-level structure of Serper Search API's JSON output.
+type SerperSearchResponse struct {
+	SearchParameters jsoniter.RawMessage   `json:"searchParameters,omitempty"`
+	Organic          []SerperOrganicResult `json:"organic"`
+	// Add other fields like relatedSearches, peopleAlsoAsk, etc. if needed
+}
+
+// serperEngine queries the Serper.dev Google-search-results API.
+type serperEngine struct {
+	apiKey     string
+	apiURL     string
+	weight     int
+	httpClient *http.Client
+}
+
+func newSerperEngine(cfg *config.AppConfig, httpClient *http.Client) *serperEngine {
+	return &serperEngine{apiKey: cfg.SerperAPIKey, apiURL: cfg.SerperAPIURL, weight: cfg.SerperWeight, httpClient: httpClient}
+}
+
+func (e *serperEngine) Name() string { return "serper" }
+func (e *serperEngine) Weight() int  { return e.weight }
+
+// Search fetches results from the Serper.dev API. Serper doesn't expose a relevance
+// score, so Result.Score is derived from organic result order (best first).
+func (e *serperEngine) Search(ctx context.Context, query string, maxResults int) ([]Result, error) {
+	if e.apiKey == "" {
+		slog.Warn("Serper API key is not configured. Skipping Serper search.")
+		return nil, fmt.Errorf("serper API key not configured")
+	}
+	if e.apiURL == "" {
+		return nil, fmt.Errorf("serper API URL not configured")
+	}
+
+	// Serper uses 'num' for number of results, but it's often 10, 20, 30, etc.
+	// We'll fetch a reasonable amount and then trim if necessary,
+	// as Serper might not support arbitrary 'num' values for fine-grained control like '7'.
+	// The API docs suggest 'num' defaults to 10. Let's request a bit more if maxResults is high.
+	numResultsToRequest := 10
+	if maxResults > 10 && maxResults <= 20 {
+		numResultsToRequest = 20
+	} else if maxResults > 20 {
+		numResultsToRequest = 30 // Or adjust as per Serper's typical pagination/result counts
+	}
+
+	payload := map[string]interface{}{
+		"q":   query,
+		"num": numResultsToRequest,
+		// Potentially add other params like "gl" (country), "hl" (language)
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling Serper request payload: %w", err)
+	}
+
+	slog.Info("Fetching Serper API results", "query", query, "url", e.apiURL, "num_results", numResultsToRequest)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.apiURL, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating Serper API request: %w", err)
+	}
+	req.Header.Set("X-API-KEY", e.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", useragent.RandomFor(req.URL.Hostname()))
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching results from Serper API: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("serper API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var serperResp SerperSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&serperResp); err != nil {
+		return nil, fmt.Errorf("error decoding Serper API response: %w", err)
+	}
+
+	var results []Result
+	for i, item := range serperResp.Organic {
+		if item.Link == "" {
+			continue
+		}
+		results = append(results, Result{URL: item.Link, Title: item.Title, Score: 1 / float64(i+1)})
+	}
+	slog.Info("Fetched URLs from Serper API", "count", len(results))
+	return results, nil
+}
@@ -0,0 +1,315 @@
+package searxng
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/logger"
+)
+
+// backupInstanceURLs is a hand-curated list of public SearxNG instances, baked into the
+// binary so InstancePool still has somewhere to send requests when searx.space itself is
+// unreachable. Pulled from https://searx.space at the time this was written; an instance
+// going away just means fetchInstances's next refresh (or a failed request) marks it
+// unhealthy like any other instance.
+var backupInstanceURLs = []string{
+	"https://searx.be",
+	"https://searx.tiekoetter.com",
+	"https://priv.au",
+	"https://search.bus-hit.me",
+	"https://paulgo.io",
+}
+
+// poolRetryBackoffSchedule is how long an unhealthy instance is skipped before
+// InstancePool lets it take traffic again, doubling (capped) on each consecutive
+// failure, in the spirit of redditRetryBackoffSchedule.
+var poolRetryBackoffSchedule = []time.Duration{
+	30 * time.Second,
+	1 * time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+}
+
+// instance is one SearxNG base URL InstancePool tracks, with enough health state to pick
+// the best one and to back off from one that's erroring.
+type instance struct {
+	baseURL     string
+	uptime      float64 // 0-100, higher is better; searx.space-reported or math.Inf for backups
+	failures    int
+	nextRetryAt time.Time
+}
+
+func (i *instance) healthy(now time.Time) bool {
+	return i.failures == 0 || !now.Before(i.nextRetryAt)
+}
+
+// InstancePool auto-discovers, health-checks, and ranks a set of public SearxNG
+// instances, so searxNGEngine doesn't depend on a single self-hosted/user-pinned
+// instance staying up. It refreshes from searx.space on a timer, falls back to
+// backupInstanceURLs when that fetch fails, and tracks per-instance failures with
+// exponential backoff so a flaky instance is skipped for a while instead of being
+// retried every request.
+type InstancePool struct {
+	cfg        *config.AppConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	instances []*instance
+
+	stop chan struct{}
+}
+
+// NewInstancePool creates an InstancePool seeded with backupInstanceURLs and starts its
+// background refresh loop. Call Stop when the pool is no longer needed.
+func NewInstancePool(cfg *config.AppConfig, httpClient *http.Client) *InstancePool {
+	p := &InstancePool{
+		cfg:        cfg,
+		httpClient: httpClient,
+		stop:       make(chan struct{}),
+	}
+	p.instances = backupInstances()
+	go p.refreshLoop()
+	return p
+}
+
+func backupInstances() []*instance {
+	instances := make([]*instance, len(backupInstanceURLs))
+	for i, u := range backupInstanceURLs {
+		instances[i] = &instance{baseURL: u, uptime: 0}
+	}
+	return instances
+}
+
+// Stop ends the background refresh loop.
+func (p *InstancePool) Stop() {
+	close(p.stop)
+}
+
+// refreshLoop re-fetches the instance directory every SearxNGPoolRefreshInterval until
+// Stop is called.
+func (p *InstancePool) refreshLoop() {
+	p.refresh()
+	ticker := time.NewTicker(p.cfg.SearxNGPoolRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.refresh()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// refresh fetches and filters the searx.space instance directory, replacing the pool's
+// instance list on success. On failure it logs and leaves the existing list (which
+// starts as backupInstanceURLs and narrows to real data once a fetch succeeds) in place.
+func (p *InstancePool) refresh() {
+	fetched, err := p.fetchInstances()
+	if err != nil {
+		logger.LogError("SearxNG instance pool: refresh failed, keeping previous list: %v", err)
+		return
+	}
+	if len(fetched) == 0 {
+		slog.Warn("SearxNG instance pool: refresh returned no usable instances, keeping previous list")
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	byURL := make(map[string]*instance, len(p.instances))
+	for _, existing := range p.instances {
+		byURL[existing.baseURL] = existing
+	}
+	for _, fresh := range fetched {
+		if existing, ok := byURL[fresh.baseURL]; ok {
+			existing.uptime = fresh.uptime
+		} else {
+			byURL[fresh.baseURL] = fresh
+		}
+	}
+	merged := make([]*instance, 0, len(byURL))
+	for _, inst := range byURL {
+		merged = append(merged, inst)
+	}
+	p.instances = merged
+	slog.Info("SearxNG instance pool refreshed", "count", len(p.instances))
+}
+
+// fetchInstances fetches and decodes SearxNGInstancesURL, keeping only HTTPS instances
+// with a TLS grade of A/A+, a reported JSON search format, and at least
+// SearxNGPoolMinUptime uptime.
+func (p *InstancePool) fetchInstances() ([]*instance, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, p.cfg.SearxNGInstancesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating searx.space request: %w", err)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching searx.space instance directory: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("searx.space instance directory request failed with status %d", resp.StatusCode)
+	}
+
+	var directory struct {
+		Instances map[string]struct {
+			NetworkType string `json:"network_type"`
+			Tls         struct {
+				Grade string `json:"grade"`
+			} `json:"tls"`
+			Timing struct {
+				SearchWikipedia struct {
+					SuccessPercentage float64 `json:"success_percentage"`
+				} `json:"search_wp"`
+			} `json:"timing"`
+			Generator string `json:"generator"`
+		} `json:"instances"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&directory); err != nil {
+		return nil, fmt.Errorf("error decoding searx.space instance directory: %w", err)
+	}
+
+	var instances []*instance
+	for rawURL, data := range directory.Instances {
+		grade := data.Tls.Grade
+		if grade != "A" && grade != "A+" {
+			continue
+		}
+		uptime := data.Timing.SearchWikipedia.SuccessPercentage * 100
+		if uptime < p.cfg.SearxNGPoolMinUptime {
+			continue
+		}
+		instances = append(instances, &instance{baseURL: trimTrailingSlash(rawURL), uptime: uptime})
+	}
+	sort.SliceStable(instances, func(i, j int) bool { return instances[i].uptime > instances[j].uptime })
+	return instances, nil
+}
+
+func trimTrailingSlash(u string) string {
+	if len(u) > 0 && u[len(u)-1] == '/' {
+		return u[:len(u)-1]
+	}
+	return u
+}
+
+// Pick returns the best-ranked healthy instance not present in exclude, so a caller
+// fetching several pages concurrently can spread them across distinct hosts instead of
+// hammering one. Unhealthy instances (those serving a backoff after a recent failure)
+// are skipped unless every instance is unhealthy, in which case the best one is returned
+// anyway rather than failing outright.
+func (p *InstancePool) Pick(exclude map[string]bool) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.instances) == 0 {
+		return "", false
+	}
+
+	now := time.Now()
+	ranked := make([]*instance, len(p.instances))
+	copy(ranked, p.instances)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].uptime > ranked[j].uptime })
+
+	var fallback *instance
+	for _, inst := range ranked {
+		if exclude[inst.baseURL] {
+			continue
+		}
+		if fallback == nil {
+			fallback = inst
+		}
+		if inst.healthy(now) {
+			return inst.baseURL, true
+		}
+	}
+	if fallback != nil {
+		return fallback.baseURL, true
+	}
+	return "", false
+}
+
+// MarkFailure records a failed request (429, 5xx, or timeout) against baseURL, pushing
+// its next retry out by poolRetryBackoffSchedule[min(failures, len-1)].
+func (p *InstancePool) MarkFailure(baseURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, inst := range p.instances {
+		if inst.baseURL != baseURL {
+			continue
+		}
+		delay := poolRetryBackoffSchedule[len(poolRetryBackoffSchedule)-1]
+		if inst.failures < len(poolRetryBackoffSchedule) {
+			delay = poolRetryBackoffSchedule[inst.failures]
+		}
+		inst.failures++
+		inst.nextRetryAt = time.Now().Add(delay)
+		return
+	}
+}
+
+// MarkSuccess clears baseURL's failure count after a successful request.
+func (p *InstancePool) MarkSuccess(baseURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, inst := range p.instances {
+		if inst.baseURL == baseURL {
+			inst.failures = 0
+			return
+		}
+	}
+}
+
+// InstanceStatus is one instance's health snapshot, for DebugHandler.
+type InstanceStatus struct {
+	BaseURL     string    `json:"base_url"`
+	Uptime      float64   `json:"uptime"`
+	Healthy     bool      `json:"healthy"`
+	Failures    int       `json:"failures"`
+	NextRetryAt time.Time `json:"next_retry_at,omitempty"`
+}
+
+// Status returns every tracked instance's health snapshot, ranked best-first, for
+// DebugHandler.
+func (p *InstancePool) Status() []InstanceStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ranked := make([]*instance, len(p.instances))
+	copy(ranked, p.instances)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].uptime > ranked[j].uptime })
+
+	now := time.Now()
+	statuses := make([]InstanceStatus, len(ranked))
+	for i, inst := range ranked {
+		statuses[i] = InstanceStatus{
+			BaseURL:     inst.baseURL,
+			Uptime:      inst.uptime,
+			Healthy:     inst.healthy(now),
+			Failures:    inst.failures,
+			NextRetryAt: inst.nextRetryAt,
+		}
+	}
+	return statuses
+}
+
+// DebugHandler serves the pool's current instance list and health state as JSON, for
+// operators at GET /debug/searxng/instances.
+func (p *InstancePool) DebugHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(p.Status()); err != nil {
+			slog.Warn("Failed to write SearxNG instance pool debug response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package searxng
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// rrfK is the standard Reciprocal Rank Fusion damping constant: it keeps a single
+// very-high rank (rank 0) from dominating the sum, so an engine placing one result first
+// doesn't automatically outrank a page several engines agree is relevant but rank
+// slightly lower.
+const rrfK = 60
+
+// trackingParamPrefixes are query-string parameter prefixes normalizeURL strips before
+// two URLs are compared for RRF dedup, so "https://example.com/a?utm_source=x" and
+// "https://example.com/a" collapse into the same candidate instead of competing as
+// separate results.
+var trackingParamPrefixes = []string{"utm_", "fbclid", "gclid", "mc_cid", "mc_eid"}
+
+// normalizeURL lowercases the host, strips tracking query params, and drops a trailing
+// slash, so the same page returned by different engines under slightly different URLs
+// collapses to one RRF candidate. Malformed input is returned unchanged.
+func normalizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for key := range q {
+			lower := strings.ToLower(key)
+			for _, prefix := range trackingParamPrefixes {
+				if strings.HasPrefix(lower, prefix) {
+					q.Del(key)
+					break
+				}
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	if u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	}
+	return u.String()
+}
+
+// engineResults pairs a SearchEngine's Name/Weight with the Results it returned for one
+// query, for FuseRRF.
+type engineResults struct {
+	name    string
+	weight  int
+	results []Result
+}
+
+// fusedCandidate is one normalized URL's fused RRF score, across every engine that
+// returned it.
+type fusedCandidate struct {
+	url   string
+	title string
+	score float64
+}
+
+// FuseRRF combines several engines' ranked Results via weighted Reciprocal Rank Fusion:
+// for each unique URL u, score(u) = Σ_e weight_e / (k + rank_e(u)), where rank_e is u's
+// 1-based position in engine e's own result list and k=rrfK. URLs are compared after
+// normalizeURL, so the same page via different query strings or hosts still collapses
+// into one candidate. Returns up to maxResults normalized URLs, sorted by descending
+// fused score.
+func FuseRRF(engines []engineResults, maxResults int) []string {
+	candidates := make(map[string]*fusedCandidate)
+	for _, er := range engines {
+		for rank, r := range er.results {
+			key := normalizeURL(r.URL)
+			if key == "" {
+				continue
+			}
+			c, ok := candidates[key]
+			if !ok {
+				c = &fusedCandidate{url: key, title: r.Title}
+				candidates[key] = c
+			}
+			c.score += float64(er.weight) / float64(rrfK+rank+1)
+		}
+	}
+
+	fused := make([]*fusedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		fused = append(fused, c)
+	}
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].score > fused[j].score })
+
+	if maxResults > 0 && len(fused) > maxResults {
+		fused = fused[:maxResults]
+	}
+	urls := make([]string, len(fused))
+	for i, c := range fused {
+		urls[i] = c.url
+	}
+	return urls
+}
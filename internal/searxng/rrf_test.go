@@ -0,0 +1,119 @@
+package searxng
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "lowercases host",
+			in:   "https://Example.COM/path",
+			want: "https://example.com/path",
+		},
+		{
+			name: "strips trailing slash",
+			in:   "https://example.com/path/",
+			want: "https://example.com/path",
+		},
+		{
+			name: "keeps root slash",
+			in:   "https://example.com/",
+			want: "https://example.com/",
+		},
+		{
+			name: "strips tracking params",
+			in:   "https://example.com/a?utm_source=x&utm_campaign=y&id=1",
+			want: "https://example.com/a?id=1",
+		},
+		{
+			name: "strips fragment",
+			in:   "https://example.com/a#section",
+			want: "https://example.com/a",
+		},
+		{
+			name: "malformed input returned unchanged",
+			in:   "http://[::1",
+			want: "http://[::1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeURL(tt.in); got != tt.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	t.Run("higher rank and weight win", func(t *testing.T) {
+		engines := []engineResults{
+			{
+				name:   "a",
+				weight: 2,
+				results: []Result{
+					{URL: "https://example.com/first"},
+					{URL: "https://example.com/second"},
+				},
+			},
+			{
+				name:   "b",
+				weight: 1,
+				results: []Result{
+					{URL: "https://example.com/second"},
+					{URL: "https://example.com/first"},
+				},
+			},
+		}
+
+		got := FuseRRF(engines, 10)
+		want := []string{"https://example.com/first", "https://example.com/second"}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("FuseRRF() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("dedups across engines after normalization", func(t *testing.T) {
+		engines := []engineResults{
+			{name: "a", weight: 1, results: []Result{{URL: "https://example.com/a?utm_source=x"}}},
+			{name: "b", weight: 1, results: []Result{{URL: "https://example.com/a"}}},
+		}
+
+		got := FuseRRF(engines, 10)
+		if len(got) != 1 {
+			t.Fatalf("FuseRRF() = %v, want exactly 1 deduped candidate", got)
+		}
+		if got[0] != "https://example.com/a" {
+			t.Errorf("FuseRRF()[0] = %q, want %q", got[0], "https://example.com/a")
+		}
+	})
+
+	t.Run("maxResults truncates", func(t *testing.T) {
+		engines := []engineResults{
+			{
+				name:   "a",
+				weight: 1,
+				results: []Result{
+					{URL: "https://example.com/1"},
+					{URL: "https://example.com/2"},
+					{URL: "https://example.com/3"},
+				},
+			},
+		}
+
+		got := FuseRRF(engines, 2)
+		if len(got) != 2 {
+			t.Fatalf("FuseRRF() returned %d results, want 2", len(got))
+		}
+	})
+
+	t.Run("empty input returns empty slice", func(t *testing.T) {
+		got := FuseRRF(nil, 10)
+		if len(got) != 0 {
+			t.Errorf("FuseRRF(nil) = %v, want empty", got)
+		}
+	})
+}
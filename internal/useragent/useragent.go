@@ -0,0 +1,300 @@
+// Package useragent provides randomized User-Agent strings for outbound HTTP requests.
+package useragent
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"web-search-api-for-llms/internal/config"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// fallbackUserAgents is the general-purpose rotation used for non-browser HTTP clients
+// when no Pool is active, or a Pool is active but hasn't fetched live data yet.
+var fallbackUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:127.0) Gecko/20100101 Firefox/127.0",
+}
+
+// fallbackDesktopUserAgents is a small rotation of recent desktop browser User-Agent
+// strings, used the same way fallbackUserAgents is for RandomDesktop.
+var fallbackDesktopUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:127.0) Gecko/20100101 Firefox/127.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+}
+
+// active is the process-wide Pool set by Init, if any. Random/RandomDesktop fall back to
+// the static rotations above when it's nil or hasn't fetched usable data yet.
+var active *Pool
+
+// Init starts a live, usage-share-weighted Pool when cfg.UserAgentPoolEnabled, so
+// Random/RandomDesktop draw from real-world browser market share instead of the small
+// static rotation. Safe to call once at startup; a no-op when the pool is disabled.
+func Init(cfg *config.AppConfig, httpClient *http.Client) {
+	if !cfg.UserAgentPoolEnabled {
+		return
+	}
+	active = NewPool(cfg, httpClient)
+}
+
+// Random returns a random User-Agent string suitable for general HTTP requests, weighted
+// by live browser market share when a Pool is active, or picked uniformly from the static
+// fallback rotation otherwise.
+func Random() string {
+	if active != nil {
+		if ua, ok := active.random(); ok {
+			return ua
+		}
+	}
+	return fallbackUserAgents[rand.Intn(len(fallbackUserAgents))]
+}
+
+// RandomDesktop returns a random User-Agent string for a desktop browser, for use
+// with collectors that need to look like a regular browser session.
+func RandomDesktop() string {
+	if active != nil {
+		if ua, ok := active.randomDesktop(); ok {
+			return ua
+		}
+	}
+	return fallbackDesktopUserAgents[rand.Intn(len(fallbackDesktopUserAgents))]
+}
+
+// pinWindow bounds how long RandomFor keeps returning the same User-Agent for a given
+// hostname before rotating to a new (still deterministic) pick.
+const pinWindow = time.Hour
+
+// RandomFor returns a User-Agent string pinned to hostname for pinWindow: repeated calls
+// for the same host within that window return the same string, weighted by live browser
+// market share the same way Random is. This is for fetchers that make several requests
+// to the same target over a short session (e.g. paginating a SearxNG instance or polling
+// the same Serper endpoint) where a different User-Agent on every request is itself a
+// signal some anti-bot systems key on. Different hosts, and the same host in a later
+// window, still get independently varied picks.
+func RandomFor(hostname string) string {
+	r := rand.New(rand.NewSource(pinSeed(hostname)))
+	if active != nil {
+		if ua, ok := active.randomSeeded(r); ok {
+			return ua
+		}
+	}
+	return fallbackUserAgents[r.Intn(len(fallbackUserAgents))]
+}
+
+// pinSeed derives a seed from hostname and the current pinWindow bucket, so the seed
+// (and the User-Agent RandomFor picks from it) stays stable for pinWindow and then
+// changes.
+func pinSeed(hostname string) int64 {
+	bucket := time.Now().Unix() / int64(pinWindow/time.Second)
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(hostname))
+	var bucketBytes [8]byte
+	binary.LittleEndian.PutUint64(bucketBytes[:], uint64(bucket))
+	_, _ = h.Write(bucketBytes[:])
+	return int64(h.Sum64())
+}
+
+// share is one User-Agent string and its current usage share (0-100), as reported by
+// UserAgentStatsURL.
+type share struct {
+	ua  string
+	pct float64
+}
+
+// Pool periodically fetches live browser-share data from config.UserAgentStatsURL and
+// serves Random/RandomDesktop draws weighted by each User-Agent's reported share, so the
+// rotation tracks real-world traffic instead of a hand-picked, slowly-stale list.
+type Pool struct {
+	cfg        *config.AppConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	general []share
+	desktop []share
+	total   float64 // sum of general[*].pct, cached for weighted picks
+	dtotal  float64 // sum of desktop[*].pct
+
+	stop chan struct{}
+}
+
+// NewPool creates a Pool and starts its background refresh loop. Call Stop when the pool
+// is no longer needed. The pool serves no live data (random/randomDesktop report !ok)
+// until its first refresh succeeds.
+func NewPool(cfg *config.AppConfig, httpClient *http.Client) *Pool {
+	p := &Pool{
+		cfg:        cfg,
+		httpClient: httpClient,
+		stop:       make(chan struct{}),
+	}
+	go p.refreshLoop()
+	return p
+}
+
+// Stop ends the background refresh loop.
+func (p *Pool) Stop() {
+	close(p.stop)
+}
+
+// refreshLoop re-fetches UserAgentStatsURL every UserAgentPoolRefreshInterval until Stop
+// is called.
+func (p *Pool) refreshLoop() {
+	p.refresh()
+	ticker := time.NewTicker(p.cfg.UserAgentPoolRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.refresh()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// refresh fetches the general and desktop-only breakdowns from UserAgentStatsURL,
+// replacing the pool's current shares on success. On failure it logs and leaves the
+// existing shares (empty until the first successful fetch) in place.
+func (p *Pool) refresh() {
+	general, err := p.fetch("")
+	if err != nil {
+		slog.Warn("User-Agent pool: refresh failed, keeping previous data", "error", err)
+		return
+	}
+	desktop, err := p.fetch("desktop")
+	if err != nil {
+		slog.Warn("User-Agent pool: desktop refresh failed, keeping previous data", "error", err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.general, p.total = general, sumShares(general)
+	p.desktop, p.dtotal = desktop, sumShares(desktop)
+	slog.Info("User-Agent pool refreshed", "general", len(p.general), "desktop", len(p.desktop))
+}
+
+// fetch fetches and decodes UserAgentStatsURL, keeping only entries at or above
+// UserAgentPoolMinShare. deviceType ("" or "desktop") is passed through as the
+// useragents.me-style "device_type" query parameter.
+func (p *Pool) fetch(deviceType string) ([]share, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, p.cfg.UserAgentStatsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating User-Agent stats request: %w", err)
+	}
+	if deviceType != "" {
+		q := req.URL.Query()
+		q.Set("device_type", deviceType)
+		req.URL.RawQuery = q.Encode()
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching User-Agent stats: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("User-Agent stats request failed with status %d", resp.StatusCode)
+	}
+
+	var stats struct {
+		Data []struct {
+			UA  string  `json:"ua"`
+			Pct float64 `json:"pct"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("error decoding User-Agent stats: %w", err)
+	}
+
+	shares := make([]share, 0, len(stats.Data))
+	for _, d := range stats.Data {
+		if d.UA == "" || d.Pct < p.cfg.UserAgentPoolMinShare {
+			continue
+		}
+		shares = append(shares, share{ua: d.UA, pct: d.Pct})
+	}
+	return shares, nil
+}
+
+func sumShares(shares []share) float64 {
+	var total float64
+	for _, s := range shares {
+		total += s.pct
+	}
+	return total
+}
+
+// random draws a User-Agent string from the general pool, weighted by usage share.
+// Reports !ok until the first successful refresh has populated the pool.
+func (p *Pool) random() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return pick(p.general, p.total)
+}
+
+// randomDesktop draws a User-Agent string from the desktop-only pool, weighted by usage
+// share. Reports !ok until the first successful refresh has populated the pool.
+func (p *Pool) randomDesktop() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return pick(p.desktop, p.dtotal)
+}
+
+// randomSeeded draws a User-Agent string from the general pool like random, but using r
+// for the weighted pick instead of the global rand source, so RandomFor's per-hostname
+// pinning is deterministic. Reports !ok until the first successful refresh has populated
+// the pool.
+func (p *Pool) randomSeeded(r *rand.Rand) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return pickSeeded(p.general, p.total, r)
+}
+
+// pick draws one entry from shares, weighted by each entry's pct out of total.
+func pick(shares []share, total float64) (string, bool) {
+	if len(shares) == 0 || total <= 0 {
+		return "", false
+	}
+	return pickFromTarget(shares, rand.Float64()*total), true
+}
+
+// pickSeeded is pick, but drawing its random target from r instead of the global rand
+// source.
+func pickSeeded(shares []share, total float64, r *rand.Rand) (string, bool) {
+	if len(shares) == 0 || total <= 0 {
+		return "", false
+	}
+	return pickFromTarget(shares, r.Float64()*total), true
+}
+
+// pickFromTarget walks shares, each consuming target down by its pct, and returns the
+// entry that brings target to zero or below (falling back to the last entry to absorb
+// any floating-point rounding short of the full total).
+func pickFromTarget(shares []share, target float64) string {
+	for _, s := range shares {
+		target -= s.pct
+		if target <= 0 {
+			return s.ua
+		}
+	}
+	return shares[len(shares)-1].ua
+}
@@ -0,0 +1,104 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Record is one parsed WARC record: its WARC-Type, WARC-Target-URI (empty for a
+// warcinfo record), and the raw block that followed the blank line terminating its
+// WARC header (for a request/response record, this is itself a raw HTTP message).
+type Record struct {
+	Type      string
+	TargetURI string
+	Body      []byte
+}
+
+// ReadRecords reads every gzip-per-record member from r (a Writer's ".warc.gz" output)
+// and returns the records it found, in file order. A gzip member that fails to
+// decompress is reported as an error immediately; ReadRecords does not skip over
+// corruption, since a corrupt member usually means truncation and every record after it
+// is suspect too.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	buffered := bufio.NewReader(r)
+	var records []Record
+
+	for {
+		gz, err := gzip.NewReader(buffered)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return records, fmt.Errorf("failed to open WARC gzip member: %w", err)
+		}
+		// Each WARC record is its own independent gzip member (see Writer.writeRecord);
+		// without this, gzip.Reader would transparently concatenate every member in the
+		// file into one decompressed stream instead of stopping at this record's end.
+		gz.Multistream(false)
+
+		raw, err := io.ReadAll(gz)
+		closeErr := gz.Close()
+		if err != nil {
+			return records, fmt.Errorf("failed to decompress WARC record: %w", err)
+		}
+		if closeErr != nil {
+			return records, fmt.Errorf("failed to close WARC gzip member: %w", closeErr)
+		}
+
+		record, err := parseRecord(raw)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+
+		// gzip.NewReader leaves buffered positioned right after this member; peeking a
+		// single byte is enough to tell whether another member follows.
+		if _, err := buffered.Peek(1); err != nil {
+			break
+		}
+	}
+
+	return records, nil
+}
+
+// parseRecord splits raw (one WARC record: header block, blank line, payload) into a
+// Record, trusting Content-Length over scanning for the trailing "\r\n\r\n" Writer
+// appends after every record, since a binary payload could legitimately contain that
+// sequence.
+func parseRecord(raw []byte) (Record, error) {
+	headerEnd := bytes.Index(raw, []byte("\r\n\r\n"))
+	if headerEnd == -1 {
+		return Record{}, fmt.Errorf("malformed WARC record: no header/body separator")
+	}
+
+	var record Record
+	var contentLength int
+	header := string(raw[:headerEnd])
+	for _, line := range strings.Split(header, "\r\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "warc-type":
+			record.Type = value
+		case "warc-target-uri":
+			record.TargetURI = value
+		case "content-length":
+			contentLength, _ = strconv.Atoi(value)
+		}
+	}
+
+	bodyStart := headerEnd + 4
+	if bodyStart+contentLength > len(raw) {
+		return Record{}, fmt.Errorf("malformed WARC record %q: Content-Length %d exceeds record size", record.TargetURI, contentLength)
+	}
+	record.Body = raw[bodyStart : bodyStart+contentLength]
+	return record, nil
+}
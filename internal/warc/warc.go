@@ -0,0 +1,207 @@
+// Package warc writes WARC 1.1 archives (https://iipc.github.io/warc-specifications/)
+// of the raw HTTP traffic behind an extraction batch, so the /extract endpoint can hand
+// back a portable, replayable corpus alongside its JSON response.
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRollSize is the approximate number of bytes a Writer accumulates in one file
+// before rolling over to the next.
+const DefaultRollSize = 1 << 30 // 1 GiB
+
+// Writer appends WARC records to a sequence of gzip-per-record ".warc.gz" files, rolling
+// to a new file once the current one grows past rollSize. Each record is compressed as
+// its own independent gzip member (the convention real WARC tooling, e.g. warcio,
+// expects), so the resulting files stay seekable and splittable even mid-write.
+//
+// A single Writer is shared by every worker processing one /extract batch, so all
+// exported methods are safe for concurrent use.
+type Writer struct {
+	dir      string
+	rollSize int64
+	prefix   string
+
+	mu       sync.Mutex
+	file     *os.File
+	written  int64
+	sequence int
+}
+
+// NewWriter creates dir (if needed) and opens the first file of a new WARC sequence
+// under it, named "crawl-<started>-00001.warc.gz". rollSize <= 0 uses DefaultRollSize.
+func NewWriter(dir string, rollSize int64) (*Writer, error) {
+	if rollSize <= 0 {
+		rollSize = DefaultRollSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create WARC output directory %s: %w", dir, err)
+	}
+
+	w := &Writer{
+		dir:      dir,
+		rollSize: rollSize,
+		prefix:   time.Now().UTC().Format("20060102150405"),
+	}
+	if err := w.roll(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// roll fsyncs and closes the current file (if any), so a completed file is durable on
+// disk before rotation moves on, then opens the next one in the sequence. Callers must
+// hold w.mu.
+func (w *Writer) roll() error {
+	if w.file != nil {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("failed to fsync WARC file: %w", err)
+		}
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("failed to close WARC file: %w", err)
+		}
+	}
+
+	w.sequence++
+	name := fmt.Sprintf("crawl-%s-%05d.warc.gz", w.prefix, w.sequence)
+	file, err := os.OpenFile(filepath.Join(w.dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open WARC file %s: %w", name, err)
+	}
+
+	w.file = file
+	w.written = 0
+	return nil
+}
+
+// Close flushes and closes the current WARC file. It does not write a terminating
+// record; WARC readers treat EOF as the end of the file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	if err := w.file.Sync(); err != nil {
+		w.file.Close()
+		w.file = nil
+		return fmt.Errorf("failed to fsync WARC file: %w", err)
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// writeRecord frames block as one WARC record of recordType, gzip-compresses it as an
+// independent member, and appends it to the current file, rolling to a new file first if
+// the write would push the current file past rollSize.
+func (w *Writer) writeRecord(recordType, targetURI, contentType string, extraHeaders map[string]string, block []byte) error {
+	digest := sha1.Sum(block)
+	header := &bytes.Buffer{}
+	fmt.Fprintf(header, "WARC/1.1\r\n")
+	fmt.Fprintf(header, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(header, "WARC-Record-ID: <urn:uuid:%s>\r\n", uuid.New().String())
+	fmt.Fprintf(header, "WARC-Date: %s\r\n", time.Now().UTC().Format(time.RFC3339))
+	if targetURI != "" {
+		fmt.Fprintf(header, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	fmt.Fprintf(header, "WARC-Payload-Digest: sha1:%s\r\n", base32.StdEncoding.EncodeToString(digest[:]))
+	for key, value := range extraHeaders {
+		fmt.Fprintf(header, "%s: %s\r\n", key, value)
+	}
+	fmt.Fprintf(header, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(header, "Content-Length: %d\r\n", len(block))
+	header.WriteString("\r\n")
+
+	record := &bytes.Buffer{}
+	record.Write(header.Bytes())
+	record.Write(block)
+	record.WriteString("\r\n\r\n")
+
+	compressed := &bytes.Buffer{}
+	gzWriter := gzip.NewWriter(compressed)
+	if _, err := gzWriter.Write(record.Bytes()); err != nil {
+		return fmt.Errorf("failed to gzip WARC record: %w", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return fmt.Errorf("failed to gzip WARC record: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.written > 0 && w.written+int64(compressed.Len()) > w.rollSize {
+		if err := w.roll(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(compressed.Bytes())
+	w.written += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write WARC record: %w", err)
+	}
+	return nil
+}
+
+// WriteWarcinfo writes a single warcinfo record describing this crawl, conventionally
+// the first record in a WARC file. fields becomes an application/warc-fields body (one
+// "key: value" per line).
+func (w *Writer) WriteWarcinfo(fields map[string]string) error {
+	body := &bytes.Buffer{}
+	for key, value := range fields {
+		fmt.Fprintf(body, "%s: %s\r\n", key, value)
+	}
+	return w.writeRecord("warcinfo", "", "application/warc-fields", nil, body.Bytes())
+}
+
+// WriteRequest writes a raw HTTP/1.1 request (status line, headers, blank line, body) as
+// a request record.
+func (w *Writer) WriteRequest(targetURI string, raw []byte) error {
+	return w.writeRecord("request", targetURI, "application/http; msgtype=request", nil, raw)
+}
+
+// WriteResponse writes a raw HTTP/1.1 response (status line, headers, blank line, body)
+// as a response record.
+func (w *Writer) WriteResponse(targetURI string, raw []byte) error {
+	return w.writeRecord("response", targetURI, "application/http; msgtype=response", nil, raw)
+}
+
+// WriteResource writes body as a resource record: a fetched payload with no HTTP framing
+// of its own, for captures (e.g. a JS-rendered page's extracted text) that don't carry a
+// synthesized status line and headers.
+func (w *Writer) WriteResource(targetURI, contentType string, body []byte) error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return w.writeRecord("resource", targetURI, contentType, nil, body)
+}
+
+type contextKey struct{}
+
+var writerContextKey = contextKey{}
+
+// NewContext returns a copy of ctx carrying w, retrievable by extractors via FromContext
+// without changing the Extractor interface's signature.
+func NewContext(ctx context.Context, w *Writer) context.Context {
+	return context.WithValue(ctx, writerContextKey, w)
+}
+
+// FromContext returns the Writer ctx carries, if any. A request that didn't set
+// ExtractRequestPayload.Archive carries none, so extractors must check ok before using w.
+func FromContext(ctx context.Context) (*Writer, bool) {
+	w, ok := ctx.Value(writerContextKey).(*Writer)
+	return w, ok
+}
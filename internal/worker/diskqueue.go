@@ -0,0 +1,315 @@
+package worker
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskQueueRecord is what's persisted to a segment file for an overflowed Job. A Job's
+// ResultChan can't cross a crash (or be serialized at all), so it never goes to disk:
+// only enough to re-run the extraction lives in the record, keyed by ID so WorkerPool can
+// reunite it with the in-memory ResultChan in its pending map.
+type diskQueueRecord struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Endpoint   string    `json:"endpoint"`
+	MaxChars   *int      `json:"max_chars,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
+
+// maxSegmentBytes is how large a segment file grows before diskOverflowQueue rolls to a
+// new one, bounding how much a single compaction pass has to skip over.
+const maxSegmentBytes = 8 << 20 // 8 MiB
+
+// diskOverflowQueue is an append-only, segment-file-backed FIFO queue that
+// WorkerPool.Enqueue spills jobs to once JobQueue's buffer is full, so a burst of
+// incoming work bounds memory instead of blocking the caller or piling up in an
+// unbounded in-memory slice. Each record is length-prefixed JSON appended to the
+// current write segment; a small index file tracks which segment/offset is being read
+// and written so a restart can pick up where it left off.
+//
+// Segments are named "<dir>/segment-<seq>.jsonl". Once every record in a segment has
+// been read, it's deleted; Compact also sweeps for segments orphaned by a crash between
+// a read and the index update that would have recorded it.
+type diskOverflowQueue struct {
+	dir string
+
+	mu       sync.Mutex
+	writer   *os.File
+	writeSeq int
+
+	reader   *bufio.Reader
+	readFile *os.File
+	readSeq  int
+	// readOffset is how many bytes of the current read segment have already been
+	// consumed. It's persisted (see saveIndex) and restored via openReader's Seek so a
+	// restart resumes mid-segment instead of replaying everything already dequeued from
+	// it, which would otherwise re-deliver (and re-extract) every record read before the
+	// crash.
+	readOffset int64
+}
+
+type diskQueueIndex struct {
+	ReadSeq    int   `json:"read_seq"`
+	WriteSeq   int   `json:"write_seq"`
+	ReadOffset int64 `json:"read_offset"`
+}
+
+// newDiskOverflowQueue opens (or creates) dir and resumes from whatever segments and
+// index it finds there, so a restart replays anything left over from before a crash.
+func newDiskOverflowQueue(dir string) (*diskOverflowQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create disk overflow queue directory %s: %w", dir, err)
+	}
+
+	q := &diskOverflowQueue{dir: dir}
+
+	idx, err := q.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	if idx == nil {
+		idx = &diskQueueIndex{ReadSeq: 0, WriteSeq: 0}
+	}
+	q.readSeq = idx.ReadSeq
+	q.writeSeq = idx.WriteSeq
+	q.readOffset = idx.ReadOffset
+
+	if err := q.openWriter(); err != nil {
+		return nil, err
+	}
+	if err := q.openReader(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *diskOverflowQueue) segmentPath(seq int) string {
+	return filepath.Join(q.dir, fmt.Sprintf("segment-%09d.jsonl", seq))
+}
+
+func (q *diskOverflowQueue) indexPath() string {
+	return filepath.Join(q.dir, "index.json")
+}
+
+func (q *diskOverflowQueue) loadIndex() (*diskQueueIndex, error) {
+	raw, err := os.ReadFile(q.indexPath())
+	if os.IsNotExist(err) {
+		return q.recoverIndexFromSegments()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disk overflow queue index: %w", err)
+	}
+	var idx diskQueueIndex
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		return q.recoverIndexFromSegments()
+	}
+	return &idx, nil
+}
+
+// recoverIndexFromSegments rebuilds the index from whatever segment files are present
+// when index.json is missing or unreadable, e.g. after a crash between writing a
+// segment and persisting the index.
+func (q *diskOverflowQueue) recoverIndexFromSegments() (*diskQueueIndex, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disk overflow queue directory: %w", err)
+	}
+	var seqs []int
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "segment-") {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(e.Name(), "segment-"), ".jsonl"))
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	if len(seqs) == 0 {
+		return &diskQueueIndex{}, nil
+	}
+	sort.Ints(seqs)
+	return &diskQueueIndex{ReadSeq: seqs[0], WriteSeq: seqs[len(seqs)-1]}, nil
+}
+
+// saveIndex persists the current read/write position, fsyncing it before the rename so
+// a crash right after can't leave an index that points past data the read/write
+// segment files don't actually have on disk yet.
+func (q *diskOverflowQueue) saveIndex() error {
+	idx := diskQueueIndex{ReadSeq: q.readSeq, WriteSeq: q.writeSeq, ReadOffset: q.readOffset}
+	raw, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp := q.indexPath() + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write disk overflow queue index: %w", err)
+	}
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write disk overflow queue index: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to fsync disk overflow queue index: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close disk overflow queue index: %w", err)
+	}
+	return os.Rename(tmp, q.indexPath())
+}
+
+func (q *diskOverflowQueue) openWriter() error {
+	f, err := os.OpenFile(q.segmentPath(q.writeSeq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open disk overflow queue write segment: %w", err)
+	}
+	q.writer = f
+	return nil
+}
+
+// openReader opens the current read segment and seeks to q.readOffset, so a restart
+// resumes exactly where the last Dequeue left off instead of replaying already-consumed
+// records at the front of the segment.
+func (q *diskOverflowQueue) openReader() error {
+	f, err := os.OpenFile(q.segmentPath(q.readSeq), os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open disk overflow queue read segment: %w", err)
+	}
+	if q.readOffset > 0 {
+		if _, err := f.Seek(q.readOffset, io.SeekStart); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to seek disk overflow queue read segment to offset %d: %w", q.readOffset, err)
+		}
+	}
+	q.readFile = f
+	q.reader = bufio.NewReader(f)
+	return nil
+}
+
+// Enqueue appends record to the current write segment, rolling to a new segment first
+// if the current one has grown past maxSegmentBytes.
+func (q *diskOverflowQueue) Enqueue(record diskQueueRecord) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	info, err := q.writer.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat disk overflow queue write segment: %w", err)
+	}
+	if info.Size() >= maxSegmentBytes {
+		if err := q.writer.Close(); err != nil {
+			return fmt.Errorf("failed to close disk overflow queue write segment: %w", err)
+		}
+		q.writeSeq++
+		if err := q.openWriter(); err != nil {
+			return err
+		}
+		if err := q.saveIndex(); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal disk overflow queue record: %w", err)
+	}
+	var lenPrefix [4]byte
+	binary.BigEndian.PutUint32(lenPrefix[:], uint32(len(payload)))
+	if _, err := q.writer.Write(lenPrefix[:]); err != nil {
+		return fmt.Errorf("failed to write disk overflow queue record length: %w", err)
+	}
+	if _, err := q.writer.Write(payload); err != nil {
+		return fmt.Errorf("failed to write disk overflow queue record: %w", err)
+	}
+	return q.writer.Sync()
+}
+
+// Dequeue returns the oldest unread record, advancing past fully-consumed segments (and
+// deleting them) as needed. ok is false when the queue has nothing left to read.
+func (q *diskOverflowQueue) Dequeue() (record diskQueueRecord, ok bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		var lenPrefix [4]byte
+		_, readErr := io.ReadFull(q.reader, lenPrefix[:])
+		if readErr == nil {
+			size := binary.BigEndian.Uint32(lenPrefix[:])
+			payload := make([]byte, size)
+			if _, err := io.ReadFull(q.reader, payload); err != nil {
+				return diskQueueRecord{}, false, fmt.Errorf("failed to read disk overflow queue record: %w", err)
+			}
+			q.readOffset += int64(4 + size)
+			if err := q.saveIndex(); err != nil {
+				return diskQueueRecord{}, false, err
+			}
+			if err := json.Unmarshal(payload, &record); err != nil {
+				return diskQueueRecord{}, false, fmt.Errorf("failed to unmarshal disk overflow queue record: %w", err)
+			}
+			return record, true, nil
+		}
+		if readErr != io.EOF {
+			return diskQueueRecord{}, false, fmt.Errorf("failed to read disk overflow queue segment: %w", readErr)
+		}
+
+		// Exhausted this segment. If it's also the write segment, there's nothing more
+		// to read yet.
+		if q.readSeq >= q.writeSeq {
+			return diskQueueRecord{}, false, nil
+		}
+
+		// Advance to the next segment, deleting the one we just fully consumed.
+		if err := q.readFile.Close(); err != nil {
+			return diskQueueRecord{}, false, fmt.Errorf("failed to close disk overflow queue read segment: %w", err)
+		}
+		consumedPath := q.segmentPath(q.readSeq)
+		q.readSeq++
+		q.readOffset = 0
+		if err := q.openReader(); err != nil {
+			return diskQueueRecord{}, false, err
+		}
+		if err := q.saveIndex(); err != nil {
+			return diskQueueRecord{}, false, err
+		}
+		if err := os.Remove(consumedPath); err != nil {
+			return diskQueueRecord{}, false, fmt.Errorf("failed to remove consumed disk overflow queue segment: %w", err)
+		}
+	}
+}
+
+// Compact removes any segment files strictly below the current read segment that a
+// crash left behind (the normal Dequeue path already deletes these as it advances; this
+// is a backstop for ones orphaned by a crash between the delete and an index update).
+func (q *diskOverflowQueue) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list disk overflow queue directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "segment-") {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(e.Name(), "segment-"), ".jsonl"))
+		if err != nil || seq >= q.readSeq {
+			continue
+		}
+		_ = os.Remove(filepath.Join(q.dir, e.Name()))
+	}
+	return nil
+}
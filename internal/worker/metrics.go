@@ -0,0 +1,39 @@
+package worker
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// PoolCollector reports a WorkerPool's in-flight and queued job counts as Prometheus
+// gauges, labeled by name (e.g. "browser", "http"). It reads from Status() at scrape
+// time rather than updating gauges from WorkerPool itself, so the pool's hot path
+// doesn't pay for a metrics write on every Enqueue/dequeue.
+type PoolCollector struct {
+	pool *WorkerPool
+	name string
+
+	inFlight *prometheus.Desc
+	queued   *prometheus.Desc
+}
+
+// NewPoolCollector builds a PoolCollector for pool, labeled name. Register the result
+// onto metrics.Registry (see main.go) once per pool.
+func NewPoolCollector(name string, pool *WorkerPool) *PoolCollector {
+	return &PoolCollector{
+		pool: pool,
+		name: name,
+		inFlight: prometheus.NewDesc("ragforge_worker_pool_in_flight_jobs",
+			"Jobs currently being processed by a worker pool.", []string{"pool"}, nil),
+		queued: prometheus.NewDesc("ragforge_worker_pool_queued_jobs",
+			"Jobs waiting in a worker pool's JobQueue.", []string{"pool"}, nil),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.inFlight
+	ch <- c.queued
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	status := c.pool.Status()
+	ch <- prometheus.MustNewConstMetric(c.inFlight, prometheus.GaugeValue, float64(status.ActiveJobs), c.name)
+	ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(status.QueueDepth), c.name)
+}
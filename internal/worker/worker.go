@@ -4,11 +4,20 @@ package worker
 import (
 	"context"
 	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 	"web-search-api-for-llms/internal/extractor"
+
+	"github.com/google/uuid"
 )
 
 // Job represents a task to be executed by a worker.
 type Job struct {
+	// ID identifies a Job that has overflowed to disk (see WorkerPool.Enqueue), so its
+	// ResultChan can be found again in WorkerPool.pending once it's replayed back onto
+	// JobQueue. Jobs sent directly to JobQueue never need one and leave it empty.
+	ID         string
 	URL        string
 	Endpoint   string
 	MaxChars   *int
@@ -19,54 +28,327 @@ type Job struct {
 // WorkerPool manages a pool of concurrent goroutines (workers) to process jobs.
 //
 // How it works:
-// 1. A fixed number of worker goroutines are started (`PoolSize`).
-// 2. Jobs are sent to a shared `JobQueue` channel.
-// 3. Each available worker pulls a job from the queue and processes it using the `Dispatcher`.
-// 4. This pattern limits the total number of concurrent operations, preventing resource exhaustion.
-// 5. The pool is gracefully shut down by closing the `JobQueue`, which terminates the worker goroutines.
+//  1. A number of worker goroutines are started (`PoolSize`), and can be resized at
+//     runtime (see Resize).
+//  2. Jobs are sent to a shared `JobQueue` channel.
+//  3. Each available worker pulls a job from the queue and processes it using the `Dispatcher`.
+//  4. This pattern limits the total number of concurrent operations, preventing resource exhaustion.
+//  5. The pool is gracefully shut down by closing the `JobQueue`, which terminates the worker goroutines.
+//
+// Pause/Resume let an operator stop the pool from pulling new work (e.g. during an
+// incident) without dropping jobs already in flight: a paused worker blocks on a
+// condition variable before its next dequeue, so whatever it was processing still
+// finishes normally.
+//
+// When overflow is configured (see NewWorkerPool), Enqueue spills jobs to a disk-backed
+// queue once JobQueue's buffer is full instead of blocking the caller, bounding memory
+// during an ingestion burst; a background goroutine refills JobQueue from disk as
+// workers drain it. A crash replays whatever was left on disk at the next Start.
 type WorkerPool struct {
 	JobQueue   chan Job
 	Dispatcher *extractor.Dispatcher
 	PoolSize   int
+
+	mu           sync.Mutex
+	cond         *sync.Cond
+	paused       bool
+	targetSize   int32
+	liveWorkers  int32
+	activeJobs   int32
+	nextWorkerID int32
+	counters     sync.Map // endpoint string -> *int64
+
+	overflow   *diskOverflowQueue
+	pendingMu  sync.Mutex
+	pending    map[string]chan *extractor.ExtractedResult
+	stopRefill chan struct{}
+	refillDone chan struct{}
 }
 
-// NewWorkerPool creates and starts a new worker pool.
-func NewWorkerPool(dispatcher *extractor.Dispatcher, poolSize int, queueSize int) *WorkerPool {
+// NewWorkerPool creates a new worker pool. overflowDir, if non-empty, enables spilling
+// to disk once JobQueue's buffer (queueSize) is full; an empty overflowDir means
+// Enqueue blocks like a plain channel send instead.
+func NewWorkerPool(dispatcher *extractor.Dispatcher, poolSize int, queueSize int, overflowDir string) *WorkerPool {
 	jobQueue := make(chan Job, queueSize)
-	return &WorkerPool{
+	wp := &WorkerPool{
 		JobQueue:   jobQueue,
 		Dispatcher: dispatcher,
 		PoolSize:   poolSize,
+		targetSize: int32(poolSize),
+		pending:    make(map[string]chan *extractor.ExtractedResult),
+	}
+	wp.cond = sync.NewCond(&wp.mu)
+
+	if overflowDir != "" {
+		overflow, err := newDiskOverflowQueue(overflowDir)
+		if err != nil {
+			slog.Error("Failed to open disk overflow queue; falling back to in-memory-only backpressure", "dir", overflowDir, "error", err)
+		} else {
+			wp.overflow = overflow
+		}
 	}
+
+	return wp
 }
 
-// Start initializes the worker pool and starts the worker goroutines.
+// Start initializes the worker pool and starts the worker goroutines, plus (if
+// overflow is configured) the background goroutine that replays disk-queued jobs back
+// onto JobQueue as workers free up capacity.
 func (wp *WorkerPool) Start() {
 	for i := 0; i < wp.PoolSize; i++ {
-		go func(workerID int) {
-			slog.Debug("Worker started", "worker_id", workerID)
-			for job := range wp.JobQueue {
-				slog.Debug("Worker processing job", "worker_id", workerID, "url", job.URL)
-				// Get a result from the pool AT THE START of the job.
-				result := extractor.ExtractedResultPool.Get().(*extractor.ExtractedResult)
-				result.Reset() // Reset before use
-				result.URL = job.URL
-
-				// Pass the pooled result to the dispatcher.
-				err := wp.Dispatcher.DispatchAndExtractWithContext(job.URL, job.Endpoint, job.MaxChars, result)
-				if err != nil {
-					result.ProcessedSuccessfully = false
-					result.Error = err.Error()
-				}
-				job.ResultChan <- result
+		wp.spawnWorker()
+	}
+	if wp.overflow != nil {
+		wp.stopRefill = make(chan struct{})
+		wp.refillDone = make(chan struct{})
+		go wp.refillFromDisk()
+	}
+}
+
+// Enqueue submits job to the pool. It tries a non-blocking send to JobQueue first; if
+// that's full and a disk overflow queue is configured, job is persisted there instead
+// (its ResultChan is kept in-memory, keyed by a freshly assigned Job.ID) and picked up
+// later by refillFromDisk. With no overflow queue configured, Enqueue falls back to a
+// blocking send, same as sending to JobQueue directly.
+func (wp *WorkerPool) Enqueue(job Job) error {
+	select {
+	case wp.JobQueue <- job:
+		return nil
+	default:
+	}
+
+	if wp.overflow == nil {
+		wp.JobQueue <- job
+		return nil
+	}
+
+	job.ID = uuid.New().String()
+	wp.pendingMu.Lock()
+	wp.pending[job.ID] = job.ResultChan
+	wp.pendingMu.Unlock()
+
+	if err := wp.overflow.Enqueue(diskQueueRecord{
+		ID:         job.ID,
+		URL:        job.URL,
+		Endpoint:   job.Endpoint,
+		MaxChars:   job.MaxChars,
+		EnqueuedAt: time.Now(),
+	}); err != nil {
+		wp.pendingMu.Lock()
+		delete(wp.pending, job.ID)
+		wp.pendingMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// refillFromDisk continuously moves jobs from the overflow queue back onto JobQueue.
+// The blocking send to JobQueue is what makes this "transparent": it only pulls a new
+// disk record once the channel has room, so the disk queue drains at the same rate
+// workers do. Stop closes stopRefill to ask this goroutine to exit, and waits on
+// refillDone before closing JobQueue, so this never sends on JobQueue after it's closed.
+func (wp *WorkerPool) refillFromDisk() {
+	defer close(wp.refillDone)
+	for {
+		select {
+		case <-wp.stopRefill:
+			return
+		default:
+		}
+
+		record, ok, err := wp.overflow.Dequeue()
+		if err != nil {
+			slog.Error("Disk overflow queue read failed; stopping refill", "error", err)
+			return
+		}
+		if !ok {
+			select {
+			case <-wp.stopRefill:
+				return
+			case <-time.After(100 * time.Millisecond):
 			}
+			continue
+		}
+
+		wp.pendingMu.Lock()
+		resultChan, known := wp.pending[record.ID]
+		delete(wp.pending, record.ID)
+		wp.pendingMu.Unlock()
+		if !known {
+			// No in-memory requester survived (e.g. a crash mid-burst); still run the
+			// job so its cache-write side effects happen, but nobody is waiting on it.
+			resultChan = make(chan *extractor.ExtractedResult, 1)
+			slog.Warn("Replaying disk-queued job with no surviving requester", "id", record.ID, "url", record.URL)
+		}
+
+		select {
+		case wp.JobQueue <- Job{
+			ID:         record.ID,
+			URL:        record.URL,
+			Endpoint:   record.Endpoint,
+			MaxChars:   record.MaxChars,
+			ResultChan: resultChan,
+			Context:    context.Background(),
+		}:
+		case <-wp.stopRefill:
+			return
+		}
+	}
+}
+
+// spawnWorker launches one worker goroutine and records it as live.
+func (wp *WorkerPool) spawnWorker() {
+	workerID := int(atomic.AddInt32(&wp.nextWorkerID, 1))
+	atomic.AddInt32(&wp.liveWorkers, 1)
+	go wp.runWorker(workerID)
+}
+
+// retireIfOverTarget atomically claims one excess slot and decrements liveWorkers if (and
+// only if) the pool is currently over targetSize, returning whether this worker should
+// retire. The load-then-decrement is done as a CAS loop rather than a plain
+// AddInt32(-1) so that only as many workers retire as are actually excess, even when
+// several call this concurrently.
+func (wp *WorkerPool) retireIfOverTarget(workerID int) bool {
+	for {
+		live := atomic.LoadInt32(&wp.liveWorkers)
+		target := atomic.LoadInt32(&wp.targetSize)
+		if live <= target {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&wp.liveWorkers, live, live-1) {
+			slog.Debug("Worker retiring after resize", "worker_id", workerID)
+			return true
+		}
+	}
+}
+
+func (wp *WorkerPool) runWorker(workerID int) {
+	slog.Debug("Worker started", "worker_id", workerID)
+	for {
+		wp.mu.Lock()
+		for wp.paused {
+			wp.cond.Wait()
+		}
+		wp.mu.Unlock()
+
+		// A resize down asks the excess workers to retire between jobs, rather than
+		// abandoning JobQueue's buffer or killing a job mid-flight. The check-then-
+		// decrement has to be a CAS loop: reading liveWorkers and decrementing it are two
+		// separate atomics, so two workers racing this at once could otherwise both read
+		// the same over-target value and both retire, leaving liveWorkers permanently
+		// below targetSize with nothing left to bring it back up.
+		if wp.retireIfOverTarget(workerID) {
+			return
+		}
+
+		job, ok := <-wp.JobQueue
+		if !ok {
+			atomic.AddInt32(&wp.liveWorkers, -1)
 			slog.Debug("Worker stopped", "worker_id", workerID)
-		}(i)
+			return
+		}
+
+		atomic.AddInt32(&wp.activeJobs, 1)
+		slog.Debug("Worker processing job", "worker_id", workerID, "url", job.URL)
+		// Get a result from the pool AT THE START of the job.
+		result := extractor.ExtractedResultPool.Get().(*extractor.ExtractedResult)
+		result.Reset() // Reset before use
+		result.URL = job.URL
+
+		// Pass the pooled result to the dispatcher.
+		err := wp.Dispatcher.DispatchAndExtractWithContext(job.Context, job.URL, job.Endpoint, job.MaxChars, result)
+		if err != nil {
+			result.ProcessedSuccessfully = false
+			result.Error = err.Error()
+			result.ErrorClass = extractor.ClassifyError(err)
+		}
+		wp.recordRequest(job.Endpoint)
+		job.ResultChan <- result
+		atomic.AddInt32(&wp.activeJobs, -1)
+	}
+}
+
+func (wp *WorkerPool) recordRequest(endpoint string) {
+	counter, _ := wp.counters.LoadOrStore(endpoint, new(int64))
+	atomic.AddInt64(counter.(*int64), 1)
+}
+
+// Pause stops the pool from pulling any new job off JobQueue, without touching jobs
+// already being processed. Resume undoes this.
+func (wp *WorkerPool) Pause() {
+	wp.mu.Lock()
+	wp.paused = true
+	wp.mu.Unlock()
+}
+
+// Resume lets paused workers resume pulling jobs off JobQueue.
+func (wp *WorkerPool) Resume() {
+	wp.mu.Lock()
+	wp.paused = false
+	wp.mu.Unlock()
+	wp.cond.Broadcast()
+}
+
+// Resize changes how many workers the pool runs. Growing spawns the difference
+// immediately; shrinking lets the excess workers finish whatever they're doing (or wait
+// out a pause) and retire on their own, so no in-flight job is interrupted.
+func (wp *WorkerPool) Resize(n int) {
+	if n < 1 {
+		n = 1
 	}
+	atomic.StoreInt32(&wp.targetSize, int32(n))
+	for atomic.LoadInt32(&wp.liveWorkers) < int32(n) {
+		wp.spawnWorker()
+	}
+	wp.mu.Lock()
+	wp.PoolSize = n
+	wp.mu.Unlock()
+	// Wake any worker parked on the pause condition so it re-checks targetSize right away.
+	wp.cond.Broadcast()
+}
+
+// Status summarizes the pool's current runtime state for the admin API.
+type Status struct {
+	TargetSize      int              `json:"target_size"`
+	LiveWorkers     int              `json:"live_workers"`
+	ActiveJobs      int              `json:"active_jobs"`
+	QueueDepth      int              `json:"queue_depth"`
+	QueueCapacity   int              `json:"queue_capacity"`
+	Paused          bool             `json:"paused"`
+	RequestsByRoute map[string]int64 `json:"requests_by_endpoint"`
 }
 
-// Stop gracefully shuts down the worker pool.
+// Status reports the pool's current size, load, and per-endpoint request counters.
+func (wp *WorkerPool) Status() Status {
+	wp.mu.Lock()
+	paused := wp.paused
+	wp.mu.Unlock()
+
+	counts := make(map[string]int64)
+	wp.counters.Range(func(key, value interface{}) bool {
+		counts[key.(string)] = atomic.LoadInt64(value.(*int64))
+		return true
+	})
+
+	return Status{
+		TargetSize:      int(atomic.LoadInt32(&wp.targetSize)),
+		LiveWorkers:     int(atomic.LoadInt32(&wp.liveWorkers)),
+		ActiveJobs:      int(atomic.LoadInt32(&wp.activeJobs)),
+		QueueDepth:      len(wp.JobQueue),
+		QueueCapacity:   cap(wp.JobQueue),
+		Paused:          paused,
+		RequestsByRoute: counts,
+	}
+}
+
+// Stop gracefully shuts down the worker pool. If overflow is configured, the refill
+// goroutine is stopped and awaited first, so it never sends on JobQueue after this
+// closes it.
 func (wp *WorkerPool) Stop() {
 	slog.Info("Stopping worker pool...")
+	if wp.overflow != nil {
+		close(wp.stopRefill)
+		<-wp.refillDone
+	}
 	close(wp.JobQueue)
 }
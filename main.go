@@ -3,26 +3,38 @@ package main
 import (
 	"compress/gzip"
 	"context"
+	"flag"
+	"fmt"
 	"log/slog"
+	"math/rand"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 	"web-search-api-for-llms/internal/api"
+	"web-search-api-for-llms/internal/auth"
+	"web-search-api-for-llms/internal/broker"
 	"web-search-api-for-llms/internal/browser"
 	"web-search-api-for-llms/internal/cache"
 	"web-search-api-for-llms/internal/config"
+	"web-search-api-for-llms/internal/cookies"
 	"web-search-api-for-llms/internal/extractor"
+	"web-search-api-for-llms/internal/metrics"
+	"web-search-api-for-llms/internal/useragent"
+	"web-search-api-for-llms/internal/utils"
 	"web-search-api-for-llms/internal/worker"
 
 	"github.com/google/uuid"
 	_ "github.com/joho/godotenv/autoload" // Automatically load .env file
 	goCache "github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
 	_ "go.uber.org/automaxprocs"
 	"golang.org/x/sys/unix"
 )
@@ -42,6 +54,19 @@ func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
+	if len(os.Args) > 1 && os.Args[1] == "replay-warc" {
+		runReplayWARC(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "mint-token" {
+		runMintToken(os.Args[2:])
+		return
+	}
+
+	reinstallPython := flag.Bool("reinstall-python", false, "force a rebuild of the Python venv from requirements.lock, even if it already matches")
+	flag.Parse()
+
 	// Load configuration
 	appConfig, err := config.LoadConfig()
 	if err != nil {
@@ -49,6 +74,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Bootstrap the Python venv used for transcript extraction from its pinned,
+	// hash-verified requirements.lock. A failure here isn't fatal: the transcript
+	// microservice path (config.TranscriptServiceURL) works without it, so log and
+	// continue rather than refusing to start the whole server over an optional venv.
+	if err := utils.BootstrapPython(context.Background(), *reinstallPython); err != nil {
+		slog.Warn("Failed to bootstrap Python venv", "error", err)
+	}
+
 	// Initialize browser pool
 	browserPool, err := browser.NewPool(appConfig.BrowserPoolSize)
 	if err != nil {
@@ -60,6 +93,10 @@ func main() {
 	// Create a DNS cache
 	// Create a DNS cache with manual cleanup
 	dnsCache := goCache.New(5*time.Minute, -1)
+	metrics.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ragforge_dns_cache_entries",
+		Help: "Entries currently held in the DNS resolution cache.",
+	}, func() float64 { return float64(dnsCache.ItemCount()) }))
 
 	// Create a pool of transports. A size of 4 is a good start.
 	// This gives you an effective MaxIdleConnsPerHost of 4 * 400 = 1600.
@@ -75,39 +112,145 @@ func main() {
 		},
 	}
 
-	// Initialize cache based on configuration
-	var appCache cache.Cache
-	switch appConfig.CacheType {
-	case "redis":
-		slog.Info("Using Redis cache")
-		appCache = cache.NewRedisCache(appConfig.RedisURL, appConfig.RedisPassword, appConfig.RedisDB)
-	default:
-		slog.Info("Using sharded in-memory cache")
-		appCache = cache.NewShardedMemoryCache(10*time.Minute, 15*time.Minute)
+	// Start the User-Agent rotation pool, if configured to track live browser market
+	// share instead of the static fallback rotation (see useragent.Random/RandomDesktop).
+	useragent.Init(appConfig, httpClient)
+
+	// Import an authenticated session from a local browser profile, if configured, so
+	// gated content (private subreddits, logged-in Twitter/X timelines, SSO-walled
+	// articles) can be extracted without the server implementing each site's login flow.
+	if appConfig.CookiesFromBrowser != "" {
+		importedCookies, err := cookies.Load(appConfig.CookiesFromBrowser, "")
+		if err != nil {
+			slog.Warn("Failed to load cookies-from-browser; continuing without an authenticated session", "spec", appConfig.CookiesFromBrowser, "error", err)
+		} else if jar, err := cookies.JarFromCookies(importedCookies); err != nil {
+			slog.Warn("Failed to build cookie jar from browser", "spec", appConfig.CookiesFromBrowser, "error", err)
+		} else {
+			httpClient.Jar = jar
+			browserPool.SetCookies(importedCookies)
+			slog.Info("Loaded cookies from browser", "spec", appConfig.CookiesFromBrowser, "count", len(importedCookies))
+		}
 	}
 
+	// Initialize the per-namespace cache manager (search, content, readability,
+	// browser_html, ...; see AppConfig.Caches).
+	cacheManager, err := cache.NewCacheManager(appConfig.Caches, cache.RedisConfig{
+		Addr:                appConfig.RedisURL,
+		Addrs:               appConfig.RedisAddrs,
+		MasterName:          appConfig.RedisMasterName,
+		ClusterMode:         appConfig.RedisClusterMode,
+		Username:            appConfig.RedisUsername,
+		Password:            appConfig.RedisPassword,
+		DB:                  appConfig.RedisDB,
+		TLS:                 appConfig.RedisTLS,
+		Codec:               appConfig.RedisCodec,
+		Compression:         appConfig.RedisCompression,
+		CompressionMinBytes: appConfig.RedisCompressionMinBytes,
+		Namespace:           appConfig.RedisNamespace,
+	})
+	if err != nil {
+		slog.Error("Failed to initialize cache manager", "error", err)
+		os.Exit(1)
+	}
+
+	// Build the transport factory extractors use to get an *http.Client, so that
+	// per-domain proxy/header overrides (DOMAIN_PROXIES, DOMAIN_HEADERS) and the
+	// configured TRANSPORT_BACKEND apply uniformly across extractors.
+	transportFactory := extractor.NewTransportFactory(appConfig, &http.Client{Timeout: 30 * time.Second})
+
 	// Create a single dispatcher instance
-	dispatcher := extractor.NewDispatcher(appConfig, browserPool, httpClient)
+	dispatcher := extractor.NewDispatcher(appConfig, browserPool, transportFactory)
 
 	// A small pool for heavy, CPU-bound browser jobs. Size should match available cores.
-	browserWorkerPool := worker.NewWorkerPool(dispatcher, appConfig.BrowserPoolSize, appConfig.BrowserPoolSize*2)
+	var browserOverflowDir string
+	if appConfig.JobOverflowDir != "" {
+		browserOverflowDir = filepath.Join(appConfig.JobOverflowDir, "browser")
+	}
+	browserWorkerPool := worker.NewWorkerPool(dispatcher, appConfig.BrowserPoolSize, appConfig.BrowserPoolSize*2, browserOverflowDir)
 	browserWorkerPool.Start()
 	defer browserWorkerPool.Stop()
 	slog.Info("Browser worker pool started", "size", appConfig.BrowserPoolSize)
 
 	// A large pool for light, I/O-bound HTTP jobs.
-	httpWorkerPool := worker.NewWorkerPool(dispatcher, appConfig.HTTPWorkerPoolSize, appConfig.HTTPWorkerPoolSize*2)
+	var httpOverflowDir string
+	if appConfig.JobOverflowDir != "" {
+		httpOverflowDir = filepath.Join(appConfig.JobOverflowDir, "http")
+	}
+	httpWorkerPool := worker.NewWorkerPool(dispatcher, appConfig.HTTPWorkerPoolSize, appConfig.HTTPWorkerPoolSize*2, httpOverflowDir)
 	httpWorkerPool.Start()
 	defer httpWorkerPool.Stop()
 	slog.Info("HTTP worker pool started", "size", appConfig.HTTPWorkerPoolSize)
 
+	metrics.Registry.MustRegister(
+		worker.NewPoolCollector("browser", browserWorkerPool),
+		worker.NewPoolCollector("http", httpWorkerPool),
+	)
+
 	// Initialize handlers, passing the worker pools
-	searchHandler := api.NewSearchHandler(appConfig, browserPool, httpClient, appCache, httpWorkerPool, browserWorkerPool)
+	searchHandler := api.NewSearchHandler(appConfig, browserPool, httpClient, cacheManager, httpWorkerPool, browserWorkerPool)
+	adminHandler := api.NewAdminHandler(appConfig, httpWorkerPool, browserWorkerPool, browserPool, cacheManager)
+
+	// Start the queue broker consumer alongside the HTTP server when configured, so other
+	// services can fan out extraction jobs without going through the HTTP API. Which
+	// consumer (if any) depends on AppConfig.QueueBackend.
+	type queueConsumer interface {
+		Start() error
+		Stop() error
+	}
+	var brokerConsumer queueConsumer
+	switch appConfig.QueueBackend {
+	case "amqp":
+		amqpConsumer, err := broker.NewConsumer(appConfig, httpWorkerPool, browserWorkerPool)
+		if err != nil {
+			slog.Error("Failed to start AMQP broker consumer", "error", err)
+			os.Exit(1)
+		}
+		brokerConsumer = amqpConsumer
+	case "redis":
+		redisConsumer, err := broker.NewRedisStreamConsumer(appConfig, cache.RedisConfig{
+			Addr:        appConfig.RedisURL,
+			Addrs:       appConfig.RedisAddrs,
+			MasterName:  appConfig.RedisMasterName,
+			ClusterMode: appConfig.RedisClusterMode,
+			Username:    appConfig.RedisUsername,
+			Password:    appConfig.RedisPassword,
+			DB:          appConfig.RedisDB,
+		}, httpWorkerPool, browserWorkerPool)
+		if err != nil {
+			slog.Error("Failed to start Redis Streams broker consumer", "error", err)
+			os.Exit(1)
+		}
+		brokerConsumer = redisConsumer
+	}
+	if brokerConsumer != nil {
+		if err := brokerConsumer.Start(); err != nil {
+			slog.Error("Failed to start consuming the queue", "backend", appConfig.QueueBackend, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Build the JWT authenticator gating /search, /extract (and its stream variants),
+	// and /debug/*; a no-op passthrough unless AUTH_ENABLED is set (see
+	// auth.Authenticator.Require).
+	authenticator, err := auth.New(appConfig)
+	if err != nil {
+		slog.Error("Failed to initialize authenticator", "error", err)
+		os.Exit(1)
+	}
 
 	// Setup HTTP server
 	mux := http.NewServeMux()
-	mux.HandleFunc("/search", searchHandler.HandleSearch)
-	mux.HandleFunc("/extract", searchHandler.HandleExtract)
+	mux.HandleFunc("/search", authenticator.Require("/search", searchHandler.HandleSearch))
+	mux.HandleFunc("/extract", authenticator.Require("/extract", searchHandler.HandleExtract))
+	mux.HandleFunc("/extract/stream", authenticator.Require("/extract", searchHandler.HandleExtractStream))
+	mux.HandleFunc("/extract/sse", authenticator.Require("/extract", searchHandler.HandleExtractSSE))
+	mux.HandleFunc("/twitter/stream", authenticator.Require("/extract", searchHandler.HandleTwitterStream))
+	mux.HandleFunc("/admin/pools/", adminHandler.HandlePools)
+	mux.HandleFunc("/cache/", adminHandler.HandleCache)
+	mux.Handle("/metrics", metrics.Handler())
+	if searchHandler.SearxNGClient.InstancePool != nil {
+		mux.HandleFunc("/debug/searxng/instances", authenticator.Require("/debug/*", searchHandler.SearxNGClient.InstancePool.DebugHandler()))
+	}
 
 	// Add health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -120,8 +263,9 @@ func main() {
 		}
 	})
 
-	// Create compression and timeout middleware
+	// Create compression, access-log, and timeout middleware
 	handler := gzipMiddleware(timeoutMiddleware(mux))
+	handler = accessLogMiddleware(appConfig)(handler)
 	requestIDHandler := requestIDMiddleware(handler)
 
 	// Create a custom listener config
@@ -157,7 +301,7 @@ func main() {
 	// Start server in a goroutine with the custom listener
 	go func() {
 		slog.Info("Starting server", "port", 8086)
-		slog.Info("Available endpoints", "endpoints", []string{"POST /search", "POST /extract", "GET /health"})
+		slog.Info("Available endpoints", "endpoints", []string{"POST /search", "POST /extract", "POST /extract/stream", "POST /extract/sse", "GET /health"})
 
 		// Use Serve instead of ListenAndServe
 		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
@@ -175,9 +319,7 @@ func main() {
 			case <-cleanupTicker.C:
 				slog.Info("Running manual cache cleanup")
 				dnsCache.DeleteExpired()
-				if shardedCache, ok := appCache.(*cache.ShardedMemoryCache); ok {
-					shardedCache.DeleteExpired()
-				}
+				cacheManager.Sweep()
 			case <-stopCleanup:
 				cleanupTicker.Stop()
 				slog.Info("Stopped cache cleanup goroutine")
@@ -199,6 +341,14 @@ func main() {
 	// Signal the cleanup goroutine to stop
 	close(stopCleanup)
 
+	// Drain the broker consumer's in-flight jobs before the worker pools it depends on
+	// are stopped by the deferred Stop() calls above.
+	if brokerConsumer != nil {
+		if err := brokerConsumer.Stop(); err != nil {
+			slog.Warn("Error stopping queue broker consumer", "backend", appConfig.QueueBackend, "error", err)
+		}
+	}
+
 	// Shutdown server gracefully
 	if err := server.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
@@ -208,6 +358,91 @@ func main() {
 	slog.Info("Server exited gracefully")
 }
 
+// runReplayWARC implements the "replay-warc" subcommand: it replays a previously
+// archived WARC file (see ExtractRequestPayload.Archive) back into the configured
+// cache, so an operator can rerun against an archived crawl offline instead of
+// refetching it. Every replayed record is cached as generic webpage content under the
+// "html" cache group, since cache.ReplayWARC only reconstructs generic HTML content
+// (WebpageExtractor.ExtractFromContent) rather than every source-specific extractor's
+// own parsing, so e.g. a WARC captured from a Reddit extraction replays as an "html"
+// entry keyed by URL rather than a "reddit" one.
+func runReplayWARC(args []string) {
+	fs := flag.NewFlagSet("replay-warc", flag.ExitOnError)
+	file := fs.String("file", "", "path to the .warc.gz file to replay")
+	ttl := fs.Duration("ttl", time.Hour, "how long the replayed entries stay cached")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if *file == "" {
+		slog.Error("replay-warc: -file is required")
+		os.Exit(1)
+	}
+
+	appConfig, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	cacheManager, err := cache.NewCacheManager(appConfig.Caches, cache.RedisConfig{
+		Addr:                appConfig.RedisURL,
+		Addrs:               appConfig.RedisAddrs,
+		MasterName:          appConfig.RedisMasterName,
+		ClusterMode:         appConfig.RedisClusterMode,
+		Username:            appConfig.RedisUsername,
+		Password:            appConfig.RedisPassword,
+		DB:                  appConfig.RedisDB,
+		TLS:                 appConfig.RedisTLS,
+		Codec:               appConfig.RedisCodec,
+		Compression:         appConfig.RedisCompression,
+		CompressionMinBytes: appConfig.RedisCompressionMinBytes,
+		Namespace:           appConfig.RedisNamespace,
+	})
+	if err != nil {
+		slog.Error("Failed to initialize cache manager", "error", err)
+		os.Exit(1)
+	}
+
+	count, err := cache.ReplayWARC(context.Background(), *file, cacheManager, func(string) string { return "html" }, *ttl)
+	if err != nil {
+		slog.Error("replay-warc: failed", "file", *file, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("replay-warc: done", "file", *file, "cached", count)
+}
+
+// runMintToken implements the "mint-token" subcommand: it signs a development JWT
+// against the configured AUTH_HMAC_SECRET, for testing AUTH_ENABLED locally without
+// standing up a separate identity provider.
+func runMintToken(args []string) {
+	fs := flag.NewFlagSet("mint-token", flag.ExitOnError)
+	sub := fs.String("sub", "", "subject (caller identity) to embed in the token")
+	scope := fs.String("scope", "*", "comma-separated scope list, e.g. /search,/extract")
+	ttl := fs.Duration("ttl", 24*time.Hour, "token lifetime")
+	rateLimit := fs.Int("rate-limit", 0, "requests-per-minute override (0 uses AUTH_DEFAULT_RATE_LIMIT)")
+	maxConcurrency := fs.Int("max-concurrency", 0, "in-flight request cap override (0 uses AUTH_DEFAULT_MAX_CONCURRENCY)")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+	if *sub == "" {
+		slog.Error("mint-token: -sub is required")
+		os.Exit(1)
+	}
+
+	appConfig, err := config.LoadConfig()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	token, err := auth.MintToken(appConfig, *sub, strings.Split(*scope, ","), *ttl, *rateLimit, *maxConcurrency)
+	if err != nil {
+		slog.Error("mint-token: failed", "error", err)
+		os.Exit(1)
+	}
+	fmt.Println(token)
+}
+
 // Custom RoundTripper to select a transport from the pool
 type roundRobinTransport struct {
 	transports []*http.Transport
@@ -218,6 +453,7 @@ func (r *roundRobinTransport) RoundTrip(req *http.Request) (*http.Response, erro
 	count := atomic.AddUint32(r.counter, 1)
 	transportIndex := int(count) % len(r.transports)
 
+	metrics.TransportRequests.WithLabelValues(strconv.Itoa(transportIndex)).Inc()
 	return r.transports[transportIndex].RoundTrip(req)
 }
 
@@ -256,6 +492,132 @@ func createTransportPool(size int, dnsCache *goCache.Cache) []*http.Transport {
 	return transports
 }
 
+// accessLogMiddleware attaches a fresh *api.RequestMetrics to the request (see
+// api.NewRequestMetricsContext) for processRequest to populate, wraps the ResponseWriter
+// to capture the response status and on-the-wire byte count, and on return emits a
+// single slog record summarizing the request: method, path, remote IP (trusted-proxy
+// X-Forwarded-For aware), user-agent, status, duration, byte counts, and the content
+// cache hit/miss and per-URL success/failure counts processRequest recorded. Must sit
+// inside requestIDMiddleware (so the request ID is already in context) and outside
+// gzipMiddleware (so the byte count it captures is the real on-the-wire size).
+func accessLogMiddleware(cfg *config.AppConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx, metrics := api.NewRequestMetricsContext(r.Context())
+			r = r.WithContext(ctx)
+
+			alw := &accessLogResponseWriter{ResponseWriter: w, cfg: cfg, metrics: metrics, status: http.StatusOK}
+			next.ServeHTTP(alw, r)
+
+			if cfg.AccessLogSample < 1 && rand.Float64() >= cfg.AccessLogSample {
+				return
+			}
+
+			requestID, _ := r.Context().Value(requestIDKey).(string)
+			slog.Info("access",
+				"request_id", requestID,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote_ip", remoteIP(r, cfg.TrustedProxies),
+				"user_agent", r.UserAgent(),
+				"status", alw.status,
+				"duration_ms", float64(time.Since(start).Microseconds())/1000,
+				"bytes_out", alw.bytesOut,
+				"bytes_out_raw", metrics.BytesOutRaw,
+				"cache_hits", metrics.CacheHits,
+				"cache_misses", metrics.CacheMisses,
+				"urls_ok", metrics.URLsOK,
+				"urls_failed", metrics.URLsFailed,
+			)
+		})
+	}
+}
+
+// accessLogResponseWriter wraps http.ResponseWriter to capture the status code and
+// on-the-wire byte count for accessLogMiddleware, and, when cfg.AccessLogServerTiming is
+// set, adds a Server-Timing header summarizing metrics' phase durations just before the
+// first byte is written (metrics is fully populated by processRequest before it calls
+// WriteHeader, so there's no race with the handler still filling it in).
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	cfg         *config.AppConfig
+	metrics     *api.RequestMetrics
+	status      int
+	bytesOut    int64
+	wroteHeader bool
+}
+
+func (w *accessLogResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+		if w.cfg != nil && w.cfg.AccessLogServerTiming {
+			if timing := api.ServerTimingHeader(w.metrics); timing != "" {
+				w.Header().Set("Server-Timing", timing)
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytesOut += int64(n)
+	return n, err
+}
+
+// CloseNotify implements the http.CloseNotifier interface, passed through for the
+// SSE/streaming endpoints gzipMiddleware already relies on it for.
+func (w *accessLogResponseWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// Flush implements http.Flusher, passed through so gzipMiddleware's own Flush (used by
+// the SSE/NDJSON streaming endpoints) still reaches the underlying connection.
+func (w *accessLogResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// remoteIP returns r's client IP, trusting X-Forwarded-For only when r.RemoteAddr
+// matches an entry in trustedProxies (an IP or CIDR); otherwise an untrusted client could
+// spoof its logged address by sending its own X-Forwarded-For header.
+func remoteIP(r *http.Request, trustedProxies []string) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, trustedProxies) {
+		return host
+	}
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		return strings.TrimSpace(strings.Split(xff, ",")[0])
+	}
+	return host
+}
+
+func isTrustedProxy(ip string, trustedProxies []string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+	for _, entry := range trustedProxies {
+		if strings.Contains(entry, "/") {
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(parsedIP) {
+				return true
+			}
+		} else if entry == ip {
+			return true
+		}
+	}
+	return false
+}
+
 func requestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestID := uuid.New().String()
@@ -281,25 +643,48 @@ func gzipMiddleware(next http.Handler) http.Handler {
 		slog.Debug("gzipping response")
 		gw := gzipWriterPool.Get().(*gzip.Writer)
 		gw.Reset(w)
+		grw := &gzipResponseWriter{ResponseWriter: w, writer: gw, metrics: api.RequestMetricsFromContext(r.Context())}
 		defer func() {
 			if err := gw.Close(); err != nil {
 				slog.Warn("Error closing gzip writer", "error", err)
 			}
 			gzipWriterPool.Put(gw)
 			slog.Debug("gzip writer returned to pool")
+			observeGzipRatio(w, grw.metrics)
 		}()
-		grw := &gzipResponseWriter{ResponseWriter: w, writer: gw}
 		next.ServeHTTP(grw, r)
 	})
 }
 
+// observeGzipRatio records compressed-to-uncompressed bytes for the response just
+// finished as a metrics.GzipCompressionRatio sample. It skips responses metrics
+// couldn't track (no RequestMetrics in context, or w isn't the accessLogResponseWriter
+// that counts on-the-wire bytes) rather than observing a meaningless zero.
+func observeGzipRatio(w http.ResponseWriter, m *api.RequestMetrics) {
+	if m == nil || m.BytesOutRaw == 0 {
+		return
+	}
+	alw, ok := w.(*accessLogResponseWriter)
+	if !ok || alw.bytesOut == 0 {
+		return
+	}
+	metrics.GzipCompressionRatio.Observe(float64(alw.bytesOut) / float64(m.BytesOutRaw))
+}
+
 // gzipResponseWriter wraps http.ResponseWriter to compress responses
 type gzipResponseWriter struct {
 	http.ResponseWriter
 	writer *gzip.Writer
+	// metrics, when non-nil (accessLogMiddleware attached one to the request), has its
+	// BytesOutRaw counted with the pre-compression size of every Write, so the access log
+	// can report both the uncompressed and on-the-wire response size.
+	metrics *api.RequestMetrics
 }
 
 func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.metrics != nil {
+		w.metrics.AddBytesOutRaw(int64(len(b)))
+	}
 	return w.writer.Write(b)
 }
 
@@ -308,6 +693,17 @@ func (w *gzipResponseWriter) CloseNotify() <-chan bool {
 	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
 }
 
+// Flush implements http.Flusher so gzip-compressed NDJSON/SSE streams still deliver each
+// frame as soon as it's written, instead of waiting for the gzip writer's internal buffer.
+func (w *gzipResponseWriter) Flush() {
+	if err := w.writer.Flush(); err != nil {
+		slog.Warn("Error flushing gzip writer", "error", err)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (w *gzipResponseWriter) Header() http.Header {
 	return w.ResponseWriter.Header()
 }